@@ -0,0 +1,18 @@
+#ifndef MEM_H
+#define MEM_H
+
+#include <stdint.h>
+
+typedef struct {
+    uint64_t total;
+    uint64_t used;
+    uint64_t available;
+    uint64_t cached;
+    uint64_t swap_total;
+    uint64_t swap_used;
+} MemSample;
+
+// Sample physical memory and swap usage (bytes) from the Mach kernel
+int mem_sample(MemSample *out);
+
+#endif // MEM_H
@@ -0,0 +1,32 @@
+#ifndef NET_H
+#define NET_H
+
+#include <stdint.h>
+
+typedef struct {
+    uint64_t rx_bytes;
+    uint64_t tx_bytes;
+    uint64_t rx_packets;
+    uint64_t tx_packets;
+    uint64_t rx_errors;
+    uint64_t tx_errors;
+} NetSample;
+
+typedef struct {
+    double rx_bytes_per_sec;
+    double tx_bytes_per_sec;
+    double rx_packets_per_sec;
+    double tx_packets_per_sec;
+    uint64_t rx_errors;
+    uint64_t tx_errors;
+} NetRate;
+
+// Sample traffic counters summed across all non-loopback interfaces
+int net_sample(NetSample *out);
+
+// Calculate traffic rates between two samples taken seconds apart.
+// Error counts are carried over from cur as cumulative totals.
+void net_delta(const NetSample *prev, const NetSample *cur, double seconds,
+               NetRate *out);
+
+#endif // NET_H
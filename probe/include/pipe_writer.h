@@ -1,12 +1,21 @@
 #ifndef PIPE_WRITER_H
 #define PIPE_WRITER_H
 
+#include "mem.h"
+#include "net.h"
+
 // Open or create a named pipe for writing
 int pipe_open(const char *path);
 
 // Write CPU percentage as JSON to the pipe
 int pipe_write_cpu(int fd, double cpu_percent);
 
+// Write CPU percentage, memory usage, load averages and network totals
+// as JSON to the pipe. mem, load (1/5/15 minute averages) and net may be
+// NULL to omit them.
+int pipe_write_reading(int fd, double cpu_percent, const MemSample *mem,
+                       const double *load, const NetRate *net);
+
 // Close pipe and clean up
 void pipe_close(int fd, const char *path);
 
@@ -1,4 +1,6 @@
 #include "../include/cpu.h"
+#include "../include/mem.h"
+#include "../include/net.h"
 #include "../include/pipe_writer.h"
 #include <signal.h>
 #include <stdio.h>
@@ -33,6 +35,10 @@ int main(void) {
     return 1;
   }
 
+  // Network rates need a previous sample too; skip them if it fails
+  NetSample net_prev, net_cur;
+  int have_net = (net_sample(&net_prev) == 0);
+
   while (running) {
     sleep(SAMPLE_INTERVAL);
 
@@ -43,7 +49,24 @@ int main(void) {
 
     double cpu_percent = cpu_delta(&prev, &cur);
 
-    if (pipe_write_cpu(pipe_fd, cpu_percent) != 0) {
+    // Memory and load are optional; omit them if sampling fails
+    MemSample mem;
+    const MemSample *mem_ptr = (mem_sample(&mem) == 0) ? &mem : NULL;
+    double load[3];
+    const double *load_ptr = (getloadavg(load, 3) == 3) ? load : NULL;
+    NetRate net;
+    const NetRate *net_ptr = NULL;
+    if (net_sample(&net_cur) == 0) {
+      if (have_net) {
+        net_delta(&net_prev, &net_cur, SAMPLE_INTERVAL, &net);
+        net_ptr = &net;
+      }
+      net_prev = net_cur;
+      have_net = 1;
+    }
+
+    if (pipe_write_reading(pipe_fd, cpu_percent, mem_ptr, load_ptr,
+                           net_ptr) != 0) {
       fprintf(stderr, "Failed to write to pipe\n");
     }
 
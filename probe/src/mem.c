@@ -0,0 +1,51 @@
+#include "../include/mem.h"
+#include <mach/mach.h>
+#include <mach/mach_host.h>
+#include <sys/sysctl.h>
+#include <sys/types.h>
+
+int mem_sample(MemSample *out) {
+  uint64_t total = 0;
+  size_t len = sizeof(total);
+  if (sysctlbyname("hw.memsize", &total, &len, NULL, 0) != 0) {
+    return -1;
+  }
+
+  vm_size_t page_size;
+  if (host_page_size(mach_host_self(), &page_size) != KERN_SUCCESS) {
+    return -1;
+  }
+
+  vm_statistics64_data_t vm;
+  mach_msg_type_number_t count = HOST_VM_INFO64_COUNT;
+  kern_return_t kr = host_statistics64(mach_host_self(), HOST_VM_INFO64,
+                                       (host_info64_t)&vm, &count);
+  if (kr != KERN_SUCCESS) {
+    return -1;
+  }
+
+  // Same accounting as vm_stat in sensei: free + inactive is available,
+  // file-backed pages are cache
+  uint64_t available =
+      ((uint64_t)vm.free_count + (uint64_t)vm.inactive_count) * page_size;
+  if (available > total) {
+    available = total;
+  }
+
+  out->total = total;
+  out->available = available;
+  out->used = total - available;
+  out->cached = (uint64_t)vm.external_page_count * page_size;
+
+  struct xsw_usage swap;
+  len = sizeof(swap);
+  if (sysctlbyname("vm.swapusage", &swap, &len, NULL, 0) == 0) {
+    out->swap_total = swap.xsu_total;
+    out->swap_used = swap.xsu_used;
+  } else {
+    out->swap_total = 0;
+    out->swap_used = 0;
+  }
+
+  return 0;
+}
@@ -0,0 +1,71 @@
+#include "../include/net.h"
+#include <net/if.h>
+#include <net/route.h>
+#include <stdlib.h>
+#include <sys/socket.h>
+#include <sys/sysctl.h>
+#include <sys/types.h>
+
+int net_sample(NetSample *out) {
+  // NET_RT_IFLIST2 reports 64-bit counters; the if_data from getifaddrs
+  // wraps at 4 GB
+  int mib[] = {CTL_NET, PF_ROUTE, 0, 0, NET_RT_IFLIST2, 0};
+  size_t len = 0;
+  if (sysctl(mib, 6, NULL, &len, NULL, 0) != 0) {
+    return -1;
+  }
+
+  char *buf = malloc(len);
+  if (buf == NULL) {
+    return -1;
+  }
+  if (sysctl(mib, 6, buf, &len, NULL, 0) != 0) {
+    free(buf);
+    return -1;
+  }
+
+  NetSample sample = {0};
+  for (char *next = buf; next < buf + len;) {
+    struct if_msghdr *ifm = (struct if_msghdr *)next;
+    next += ifm->ifm_msglen;
+    if (ifm->ifm_type != RTM_IFINFO2) {
+      continue;
+    }
+
+    struct if_msghdr2 *ifm2 = (struct if_msghdr2 *)ifm;
+    if (ifm2->ifm_flags & IFF_LOOPBACK) {
+      continue;
+    }
+    sample.rx_bytes += ifm2->ifm_data.ifi_ibytes;
+    sample.tx_bytes += ifm2->ifm_data.ifi_obytes;
+    sample.rx_packets += ifm2->ifm_data.ifi_ipackets;
+    sample.tx_packets += ifm2->ifm_data.ifi_opackets;
+    sample.rx_errors += ifm2->ifm_data.ifi_ierrors;
+    sample.tx_errors += ifm2->ifm_data.ifi_oerrors;
+  }
+  free(buf);
+
+  *out = sample;
+  return 0;
+}
+
+// Counter difference, treating a counter that went backwards (an
+// interface disappeared or was reset) as no traffic
+static double counter_delta(uint64_t prev, uint64_t cur) {
+  return (cur >= prev) ? (double)(cur - prev) : 0.0;
+}
+
+void net_delta(const NetSample *prev, const NetSample *cur, double seconds,
+               NetRate *out) {
+  if (seconds <= 0) {
+    seconds = 1;
+  }
+  out->rx_bytes_per_sec = counter_delta(prev->rx_bytes, cur->rx_bytes) / seconds;
+  out->tx_bytes_per_sec = counter_delta(prev->tx_bytes, cur->tx_bytes) / seconds;
+  out->rx_packets_per_sec =
+      counter_delta(prev->rx_packets, cur->rx_packets) / seconds;
+  out->tx_packets_per_sec =
+      counter_delta(prev->tx_packets, cur->tx_packets) / seconds;
+  out->rx_errors = cur->rx_errors;
+  out->tx_errors = cur->tx_errors;
+}
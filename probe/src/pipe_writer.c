@@ -1,5 +1,6 @@
 #include "../include/pipe_writer.h"
 #include <fcntl.h>
+#include <stdarg.h>
 #include <stdio.h>
 #include <string.h>
 #include <sys/stat.h>
@@ -21,18 +22,72 @@ int pipe_open(const char *path) {
 }
 
 int pipe_write_cpu(int fd, double cpu_percent) {
-  char buffer[128];
+  return pipe_write_reading(fd, cpu_percent, NULL, NULL, NULL);
+}
+
+// Append formatted text to buf at *len, failing if it doesn't fit
+static int append(char *buf, size_t size, size_t *len, const char *fmt, ...) {
+  va_list args;
+  va_start(args, fmt);
+  int n = vsnprintf(buf + *len, size - *len, fmt, args);
+  va_end(args);
+
+  if (n < 0 || (size_t)n >= size - *len) {
+    return -1;
+  }
+  *len += (size_t)n;
+  return 0;
+}
+
+int pipe_write_reading(int fd, double cpu_percent, const MemSample *mem,
+                       const double *load, const NetRate *net) {
+  char buffer[1024];
+  size_t len = 0;
   time_t timestamp = time(NULL);
 
-  int len = snprintf(buffer, sizeof(buffer),
-                     "{\"cpu_percent\":%.1f,\"timestamp\":%ld}\n", cpu_percent,
-                     timestamp);
+  if (append(buffer, sizeof(buffer), &len,
+             "{\"cpu_percent\":%.1f,\"timestamp\":%ld", cpu_percent,
+             timestamp) != 0) {
+    return -1;
+  }
+
+  if (mem != NULL &&
+      append(buffer, sizeof(buffer), &len,
+             ",\"memory\":{\"total\":%llu,\"used\":%llu,\"available\":%llu,"
+             "\"cached\":%llu,\"swap_total\":%llu,\"swap_used\":%llu}",
+             (unsigned long long)mem->total, (unsigned long long)mem->used,
+             (unsigned long long)mem->available,
+             (unsigned long long)mem->cached,
+             (unsigned long long)mem->swap_total,
+             (unsigned long long)mem->swap_used) != 0) {
+    return -1;
+  }
+
+  if (load != NULL &&
+      append(buffer, sizeof(buffer), &len,
+             ",\"load\":{\"load1\":%.2f,\"load5\":%.2f,\"load15\":%.2f}",
+             load[0], load[1], load[2]) != 0) {
+    return -1;
+  }
+
+  if (net != NULL &&
+      append(buffer, sizeof(buffer), &len,
+             ",\"network\":{\"rx_bytes_per_sec\":%.0f,"
+             "\"tx_bytes_per_sec\":%.0f,\"rx_packets_per_sec\":%.0f,"
+             "\"tx_packets_per_sec\":%.0f,\"rx_errors\":%llu,"
+             "\"tx_errors\":%llu}",
+             net->rx_bytes_per_sec, net->tx_bytes_per_sec,
+             net->rx_packets_per_sec, net->tx_packets_per_sec,
+             (unsigned long long)net->rx_errors,
+             (unsigned long long)net->tx_errors) != 0) {
+    return -1;
+  }
 
-  if (len < 0 || len >= (int)sizeof(buffer)) {
+  if (append(buffer, sizeof(buffer), &len, "}\n") != 0) {
     return -1;
   }
 
-  ssize_t written = write(fd, buffer, strlen(buffer));
+  ssize_t written = write(fd, buffer, len);
   return (written > 0) ? 0 : -1;
 }
 
@@ -60,6 +60,37 @@ fi
 
 echo "✓ Valid JSON with cpu_percent=$CPU%"
 
+# Memory metrics ride along with every reading
+for KEY in memory total used available cached swap_total swap_used; do
+    if ! echo "$LINE" | grep -q "\"$KEY\""; then
+        echo "❌ Missing '$KEY' key"
+        kill "$PROBE_PID" 2>/dev/null || true
+        exit 1
+    fi
+done
+
+echo "✓ Memory metrics present"
+
+for KEY in load load1 load5 load15; do
+    if ! echo "$LINE" | grep -q "\"$KEY\""; then
+        echo "❌ Missing '$KEY' key"
+        kill "$PROBE_PID" 2>/dev/null || true
+        exit 1
+    fi
+done
+
+echo "✓ Load averages present"
+
+for KEY in network rx_bytes_per_sec tx_bytes_per_sec rx_errors tx_errors; do
+    if ! echo "$LINE" | grep -q "\"$KEY\""; then
+        echo "❌ Missing '$KEY' key"
+        kill "$PROBE_PID" 2>/dev/null || true
+        exit 1
+    fi
+done
+
+echo "✓ Network totals present"
+
 # Clean up
 kill "$PROBE_PID" 2>/dev/null || true
 rm -f "$PIPE"
@@ -1,20 +1,104 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"system-shinobi/sensei/internal/audit"
+	"system-shinobi/sensei/internal/config"
+	"system-shinobi/sensei/internal/control"
 	"system-shinobi/sensei/internal/dojo"
+	"system-shinobi/sensei/internal/icon"
+	"system-shinobi/sensei/internal/logging"
+	"system-shinobi/sensei/internal/protect"
+	"system-shinobi/sensei/internal/record"
+	"system-shinobi/sensei/internal/systemd"
 )
 
 func main() {
-	model := dojo.NewModel()
+	configPath := flag.String("config", config.DefaultPath(), "path to the JSON config file")
+	replayPath := flag.String("replay", "", "show a recording made with `sensei record` instead of live data")
+	speed := flag.Float64("speed", 1, "how many times faster than real time to replay")
+	flag.Parse()
 
-	p := tea.NewProgram(model, tea.WithAltScreen())
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	classifier, err := icon.NewClassifier(cfg.Classify)
+	if err != nil {
+		log.Fatalf("Invalid classify config: %v", err)
+	}
+
+	policy, err := protect.New(cfg.Protect)
+	if err != nil {
+		log.Fatalf("Invalid protect config: %v", err)
+	}
+
+	// Every kill, freeze and thaw is recorded in the shared audit log
+	auditLog, err := audit.Open(audit.DefaultPath(config.StateDir()))
+	if err != nil {
+		log.Printf("Warning: signals will not be audited: %v", err)
+	}
+	defer auditLog.Close()
+
+	// systemd is optional: without it the !clan scroll explains why
+	units, unitsErr := systemd.Connect(cfg.Systemd.User)
+	defer units.Close()
+
+	model := dojo.NewModel(dojo.Options{
+		Classifier:  classifier,
+		AuditLog:    auditLog,
+		Policy:      policy,
+		Disk:        cfg.Disk,
+		Units:       units,
+		UnitsErr:    unitsErr,
+		LogPath:     logging.DefaultPath(config.StateDir()),
+		ControlPath: control.SocketPath(config.StateDir()),
+	})
+
+	if *replayPath != "" {
+		frames, err := record.ReadFile(*replayPath)
+		if err != nil {
+			log.Fatalf("Failed to load recording: %v", err)
+		}
+		player, err := record.NewPlayer(frames, *speed, time.Now())
+		if err != nil {
+			log.Fatalf("Invalid replay: %v", err)
+		}
+		model = model.WithReplay(player)
+	}
+
+	// Signals are handled below rather than by bubbletea
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithoutSignalHandler())
+
+	// Closing the terminal window sends SIGHUP, and kill or a closing
+	// tmux pane SIGTERM; shut down cleanly so frozen processes still get
+	// thawed
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		p.Kill()
+	}()
+
+	_, err = p.Run()
+
+	// The freezer is shared by every copy of the model
+	if thawErr := model.ThawAll(); thawErr != nil {
+		log.Printf("Failed to thaw frozen processes: %v", thawErr)
+	}
 
-	if _, err := p.Run(); err != nil {
+	if err != nil && !errors.Is(err, tea.ErrProgramKilled) {
 		log.Printf("Error running dojo: %v", err)
+		auditLog.Close()
 		os.Exit(1)
 	}
 }
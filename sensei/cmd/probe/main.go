@@ -0,0 +1,150 @@
+// Command probe samples CPU, memory, load, network traffic and power from
+// /proc and /sys and streams them, with its own overhead, to sensei over
+// the named pipe. It speaks the same JSON lines protocol as the C probe,
+// which remains the probe for macOS.
+package main
+
+import (
+	"errors"
+	"log"
+	"math"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"system-shinobi/sensei/internal/metrics"
+	"system-shinobi/sensei/internal/pipe"
+	"system-shinobi/sensei/internal/selfstat"
+)
+
+const (
+	pipePath       = "/tmp/shinobi.pipe"
+	sampleInterval = time.Second
+)
+
+func main() {
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	prev, err := metrics.ReadCPUTimes()
+	if err != nil {
+		log.Fatalf("Failed to get initial CPU sample: %v", err)
+	}
+	s := &sampler{prev: prev}
+	// Network rates are optional, like memory and load
+	s.netPrev, s.netErr = metrics.ReadNetCounters()
+	s.netPrevAt = time.Now()
+
+	// Opening the FIFO blocks until sensei connects, so wait for it
+	// alongside the stop signal
+	opened := make(chan *pipe.PipeWriter)
+	go func() {
+		writer, err := pipe.NewPipeWriter(pipePath)
+		if err != nil {
+			log.Fatalf("Failed to open pipe at %s: %v", pipePath, err)
+		}
+		opened <- writer
+	}()
+
+	var writer *pipe.PipeWriter
+	select {
+	case writer = <-opened:
+	case <-stop:
+		os.Remove(pipePath)
+		return
+	}
+	defer writer.Close()
+
+	ticker := time.NewTicker(sampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			reading, ok := s.sample(now)
+			if !ok {
+				continue
+			}
+
+			// Report what the probe itself costs, including the last write
+			self := selfstat.Snapshot("probe")
+			reading.Self = &self
+
+			done := selfstat.Time("write")
+			err := writer.Write(reading)
+			done()
+			if err != nil {
+				if errors.Is(err, syscall.EPIPE) {
+					log.Println("Sensei disconnected")
+					return
+				}
+				log.Printf("Failed to write to pipe: %v", err)
+			}
+		}
+	}
+}
+
+// sampler holds what the probe needs from the previous sample to turn
+// counters into rates
+type sampler struct {
+	prev       metrics.CPUTimes
+	cpuPercent float64
+	netPrev    []metrics.NetCounters
+	netPrevAt  time.Time
+	netErr     error
+}
+
+// sample takes one reading, timing each collection. It returns false if
+// the CPU can't be sampled.
+func (s *sampler) sample(now time.Time) (pipe.CpuReading, bool) {
+	done := selfstat.Time("cpu")
+	cur, err := metrics.ReadCPUTimes()
+	done()
+	if err != nil {
+		log.Printf("Failed to sample CPU: %v", err)
+		return pipe.CpuReading{}, false
+	}
+	if pct, ok := metrics.CPUPercent(s.prev, cur); ok {
+		// One decimal place, like the C probe
+		s.cpuPercent = math.Round(pct*10) / 10
+	}
+	s.prev = cur
+
+	reading := pipe.CpuReading{
+		CpuPercent: s.cpuPercent,
+		Timestamp:  now.Unix(),
+	}
+
+	done = selfstat.Time("memory")
+	if mem, err := metrics.ReadMemory(); err == nil {
+		reading.Memory = &mem
+	}
+	done()
+
+	done = selfstat.Time("load")
+	if load, err := metrics.ReadLoad(); err == nil {
+		reading.Load = &load
+	}
+	done()
+
+	done = selfstat.Time("power")
+	if power, err := metrics.ReadPower(); err == nil {
+		reading.Power = &power
+	}
+	done()
+
+	done = selfstat.Time("network")
+	if counters, err := metrics.ReadNetCounters(); err == nil {
+		if s.netErr == nil {
+			totals := metrics.SumNetRates(metrics.NetRates(s.netPrev, counters, now.Sub(s.netPrevAt)))
+			reading.Network = &totals
+		}
+		s.netPrev, s.netPrevAt, s.netErr = counters, now, nil
+	}
+	done()
+
+	return reading, true
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"system-shinobi/sensei/internal/config"
+	"system-shinobi/sensei/internal/control"
+	"system-shinobi/sensei/internal/icon"
+	"system-shinobi/sensei/internal/pipe"
+	"system-shinobi/sensei/internal/record"
+	"system-shinobi/sensei/internal/selfstat"
+	"system-shinobi/sensei/internal/sink"
+	"system-shinobi/sensei/internal/supervisor"
+)
+
+// watchBuffer is how many frames a CmdReadings watcher may fall behind
+// before it is dropped
+const watchBuffer = 64
+
+// errFellBehind ends a CmdReadings stream that couldn't keep up
+var errFellBehind = errors.New("fell behind the probe, readings were dropped")
+
+// instance tracks what a running sensei reports to --status
+type instance struct {
+	mu     sync.Mutex
+	status control.Status
+	probe  *supervisor.Supervisor // nil unless sensei manages the probe
+
+	reading  *pipe.CpuReading // the latest reading, nil while disconnected
+	watchers map[chan record.Frame]bool
+}
+
+func newInstance(probe *supervisor.Supervisor) *instance {
+	return &instance{
+		status:   control.Status{PID: os.Getpid(), Started: time.Now()},
+		probe:    probe,
+		watchers: make(map[chan record.Frame]bool),
+	}
+}
+
+// Update records the latest classified reading
+func (in *instance) Update(s sink.Sample) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.status.Connected = true
+	in.status.LastReading = s.Time
+	in.status.CPUPercent = s.Reading.CpuPercent
+	in.status.Level = s.Class.Name
+	reading := s.Reading
+	in.reading = &reading
+	in.publish(record.Frame{At: s.Time, Reading: &reading})
+}
+
+// Disconnected records that the probe went away
+func (in *instance) Disconnected(now time.Time, idle icon.Class) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.status.Connected = false
+	in.reading = nil
+	in.publish(record.Frame{At: now})
+}
+
+// watch returns a channel of every frame from now on, with a frame
+// without a reading for each disconnect, and a function ending it. A
+// watcher more than watchBuffer frames behind is dropped, closing its
+// channel, rather than holding up the pipeline.
+func (in *instance) watch() (<-chan record.Frame, func()) {
+	frames := make(chan record.Frame, watchBuffer)
+	in.mu.Lock()
+	in.watchers[frames] = true
+	in.mu.Unlock()
+	return frames, func() {
+		in.mu.Lock()
+		defer in.mu.Unlock()
+		if in.watchers[frames] {
+			delete(in.watchers, frames)
+			close(frames)
+		}
+	}
+}
+
+// publish passes f to the watchers; in.mu must be held
+func (in *instance) publish(f record.Frame) {
+	for w := range in.watchers {
+		select {
+		case w <- f:
+		default:
+			delete(in.watchers, w)
+			close(w)
+		}
+	}
+}
+
+func (in *instance) snapshot() control.Status {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	status := in.status
+	if in.probe != nil {
+		health := in.probe.Health()
+		status.Probe = &health
+	}
+	return status
+}
+
+// stats reports sensei's overhead and the probe's, when it reports it
+func (in *instance) stats() []selfstat.Report {
+	reports := []selfstat.Report{selfstat.Snapshot("sensei")}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.reading != nil && in.reading.Self != nil {
+		reports = append(reports, *in.reading.Self)
+	}
+	return reports
+}
+
+// lockInstance takes the single-instance lock, exiting if another sensei
+// holds it. Failing to create the lock only warns.
+func lockInstance() *control.Lock {
+	path := control.LockPath(config.StateDir())
+	lock, err := control.Acquire(path)
+	if errors.Is(err, control.ErrLocked) {
+		pid, _ := control.Owner(path)
+		slog.Error("sensei is already running; use --status, --open-dojo or --quit", "pid", pid)
+		os.Exit(1)
+	}
+	if err != nil {
+		slog.Warn("Single-instance lock unavailable", "err", err)
+	}
+	return lock
+}
+
+// serveControl starts the control socket. It returns nil, after logging,
+// if the socket can't be created.
+func serveControl(in *instance, openDojo func() error, quit func()) *control.Server {
+	path := control.SocketPath(config.StateDir())
+	server, err := control.Listen(path)
+	if err != nil {
+		slog.Warn("Control socket unavailable", "path", path, "err", err)
+		return nil
+	}
+
+	server.Handle(control.CmdStatus, func() (any, error) {
+		return in.snapshot(), nil
+	})
+	server.Handle(control.CmdStats, func() (any, error) {
+		return in.stats(), nil
+	})
+	server.HandleStream(control.CmdReadings, func(send func(any) error, done <-chan struct{}) error {
+		frames, stop := in.watch()
+		defer stop()
+		for {
+			select {
+			case f, ok := <-frames:
+				if !ok {
+					return errFellBehind
+				}
+				if err := send(f); err != nil {
+					return err
+				}
+			case <-done:
+				return nil
+			}
+		}
+	})
+	server.Handle(control.CmdOpenDojo, func() (any, error) {
+		return nil, openDojo()
+	})
+	server.Handle(control.CmdQuit, func() (any, error) {
+		slog.Info("Quit requested over the control socket")
+		go quit() // reply before shutdown closes the socket
+		return nil, nil
+	})
+	go server.Serve()
+	return server
+}
+
+// runControl sends command to the running sensei and prints the outcome,
+// returning the process exit code
+func runControl(command string) int {
+	path := control.SocketPath(config.StateDir())
+
+	var status control.Status
+	var result any
+	if command == control.CmdStatus {
+		result = &status
+	}
+	if err := control.Call(path, command, result); err != nil {
+		fmt.Fprintf(os.Stderr, "sensei: %v\n", err)
+		return 1
+	}
+
+	switch command {
+	case control.CmdStatus:
+		fmt.Print(status.Format(time.Now()))
+	case control.CmdOpenDojo:
+		fmt.Println("Dojo opened")
+	case control.CmdQuit:
+		fmt.Println("sensei is quitting")
+	}
+	return 0
+}
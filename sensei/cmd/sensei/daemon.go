@@ -0,0 +1,238 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"system-shinobi/sensei/internal/alert"
+	"system-shinobi/sensei/internal/audit"
+	"system-shinobi/sensei/internal/control"
+	"system-shinobi/sensei/internal/exporter"
+	"system-shinobi/sensei/internal/icon"
+	"system-shinobi/sensei/internal/launch"
+	"system-shinobi/sensei/internal/pipe"
+	"system-shinobi/sensei/internal/record"
+	"system-shinobi/sensei/internal/rules"
+	"system-shinobi/sensei/internal/sink"
+	"system-shinobi/sensei/internal/supervisor"
+)
+
+// daemon is the part of sensei that runs with or without the tray: the
+// probe connection, the sink pipeline, rules, the control socket and the
+// exporter
+type daemon struct {
+	configPath  string
+	probe       *supervisor.Supervisor // nil unless sensei manages the probe
+	reconnect   bool                   // keep reopening the FIFO of a probe sensei doesn't manage
+	self        *instance
+	pipeline    *sink.Pipeline
+	launcher    *launch.Launcher
+	launcherErr error
+	engine      *rules.Engine
+	auditLog    *audit.Log
+	lock        *control.Lock
+	exporter    *exporter.Server
+
+	// replay, when set, holds the recording's segments still to be read
+	// in place of the probe, one per connection, at speed times real time
+	replay [][]record.Frame
+	speed  float64
+
+	server  *control.Server
+	current connection
+}
+
+// start serves the control socket, starts the managed probe and reads
+// from the probe in the background. openDojo and quit back the control
+// socket's commands. A replay has no control socket.
+func (d *daemon) start(openDojo func() error, quit func()) {
+	if d.replay == nil {
+		d.server = serveControl(d.self, openDojo, quit)
+	}
+	if d.probe != nil {
+		go d.probe.Run()
+	}
+	go d.readProbe()
+}
+
+// readProbe connects to the probe's FIFO, or plays the recording, and
+// feeds the pipeline. A managed probe is reconnected to each time it
+// restarts, and with reconnect an unmanaged one is retried with backoff.
+func (d *daemon) readProbe() {
+	var delay time.Duration // backoff before reopening an unmanaged probe's FIFO
+	for {
+		if d.probe != nil {
+			<-d.probe.Ready()
+		}
+
+		// Open the pipe reader
+		reader, err := d.openReader()
+		if err != nil {
+			slog.Warn("Failed to open pipe", "path", pipePath, "err", err)
+			if d.probe != nil {
+				continue
+			}
+			if !d.reconnect {
+				slog.Warn("Make sure the probe is running. Sensei will show the disconnected state.")
+				return
+			}
+			delay = nextReconnect(delay, 0)
+			slog.Info("Retrying pipe", "in", delay)
+			time.Sleep(delay)
+			continue
+		}
+		if !d.current.set(reader) {
+			reader.Stop()
+			return
+		}
+
+		// Start reading from the pipe
+		opened := time.Now()
+		reader.Start()
+		d.pipeline.Read(reader.Readings())
+
+		// If we get here, the pipe was closed (probe disconnected)
+		switch {
+		case d.replay != nil && len(d.replay) == 0:
+			slog.Info("Replay finished")
+		case d.replay != nil:
+			slog.Info("Probe disconnected in recording")
+		default:
+			slog.Warn("Pipe closed - probe disconnected")
+		}
+		d.current.clear()
+		d.pipeline.Disconnected(time.Now())
+		if d.replay != nil {
+			if len(d.replay) == 0 {
+				return
+			}
+			continue
+		}
+		if d.probe == nil {
+			if !d.reconnect {
+				return
+			}
+			delay = nextReconnect(delay, time.Since(opened))
+			time.Sleep(delay)
+		}
+	}
+}
+
+// nextReconnect returns the delay before reopening an unmanaged probe's
+// FIFO: reconnectMin after the first failure or a connection of at least
+// reconnectMax, otherwise double the previous delay up to reconnectMax
+func nextReconnect(prev, ran time.Duration) time.Duration {
+	if prev == 0 || ran >= reconnectMax {
+		return reconnectMin
+	}
+	return min(2*prev, reconnectMax)
+}
+
+// openReader opens the probe's FIFO, or a stream of the recording's next
+// segment when replaying
+func (d *daemon) openReader() (*pipe.PipeReader, error) {
+	if d.replay != nil {
+		segment := d.replay[0]
+		d.replay = d.replay[1:]
+		return pipe.NewPipeReaderFromReadCloser(record.Stream(segment, d.speed)), nil
+	}
+	return pipe.NewPipeReader(pipePath)
+}
+
+// openDojo opens the dojo in a terminal
+func (d *daemon) openDojo() error {
+	if d.launcherErr != nil {
+		return d.launcherErr
+	}
+	return launchDojo(d.launcher, d.configPath)
+}
+
+// stop shuts everything down and releases the single-instance lock
+func (d *daemon) stop() {
+	d.probe.Stop()
+	d.current.stop()
+	if d.engine != nil {
+		d.engine.Stop()
+	}
+	d.auditLog.Close()
+	d.exporter.Close()
+	d.server.Close()
+	d.lock.Release()
+}
+
+// runHeadless runs sensei in the foreground without a tray until SIGINT,
+// SIGTERM or --quit
+func runHeadless(d *daemon) {
+	quit := make(chan struct{})
+	var once sync.Once
+	d.start(d.openDojo, func() { once.Do(func() { close(quit) }) })
+	slog.Info("Sensei running headless")
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	select {
+	case sig := <-signals:
+		slog.Info("Received signal", "signal", sig)
+	case <-quit:
+	}
+
+	d.stop()
+	slog.Info("Sensei exiting")
+}
+
+// alertSink raises notifications as alert rules start and stop firing
+type alertSink struct {
+	engine *alert.Engine
+}
+
+func (a alertSink) Update(s sink.Sample) {
+	for _, ev := range a.engine.Evaluate(s.Time, alert.FromReading(s.Reading)) {
+		raiseAlert(ev)
+	}
+}
+
+func (a alertSink) Disconnected(now time.Time, idle icon.Class) {}
+
+// connection holds the pipe reader being read from, so that shutdown can
+// stop it
+type connection struct {
+	mu      sync.Mutex
+	reader  *pipe.PipeReader
+	stopped bool
+}
+
+// set makes reader current. It returns false once sensei is exiting.
+func (c *connection) set(reader *pipe.PipeReader) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopped {
+		return false
+	}
+	c.reader = reader
+	return true
+}
+
+// clear closes the current reader after its pipe closed
+func (c *connection) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reader != nil {
+		c.reader.Stop()
+		c.reader = nil
+	}
+}
+
+// stop closes the current reader and refuses new ones
+func (c *connection) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopped = true
+	if c.reader != nil {
+		c.reader.Stop()
+		c.reader = nil
+	}
+}
@@ -1,90 +1,387 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 
 	"fyne.io/systray"
+	"system-shinobi/sensei/internal/alert"
+	"system-shinobi/sensei/internal/audit"
+	"system-shinobi/sensei/internal/config"
+	"system-shinobi/sensei/internal/control"
+	"system-shinobi/sensei/internal/exporter"
 	"system-shinobi/sensei/internal/icon"
+	"system-shinobi/sensei/internal/launch"
+	"system-shinobi/sensei/internal/logging"
+	"system-shinobi/sensei/internal/notify"
 	"system-shinobi/sensei/internal/pipe"
+	"system-shinobi/sensei/internal/protect"
+	"system-shinobi/sensei/internal/record"
+	"system-shinobi/sensei/internal/rules"
+	"system-shinobi/sensei/internal/sink"
+	"system-shinobi/sensei/internal/supervisor"
 	"system-shinobi/sensei/internal/tray"
 )
 
 const pipePath = "/tmp/shinobi.pipe"
 
+// topInterval is how often the tray's Top processes submenu refreshes
+const topInterval = 5 * time.Second
+
+// probeInterval is how often the tray's Probe submenu refreshes
+const probeInterval = 5 * time.Second
+
+// reconnectMin and reconnectMax bound the backoff before a headless sensei
+// reopens the FIFO of a probe it doesn't manage
+const (
+	reconnectMin = time.Second
+	reconnectMax = 30 * time.Second
+)
+
 func main() {
-	// Pre-generate all icons (both colored and template versions)
-	icons := icon.GenerateAll()
-	templates := make(map[icon.IconState][]byte)
-	templates[icon.StateIdle] = icon.GenerateTemplate(icon.StateIdle)
-	templates[icon.StateLow] = icon.GenerateTemplate(icon.StateLow)
-	templates[icon.StateMedium] = icon.GenerateTemplate(icon.StateMedium)
-	templates[icon.StateHigh] = icon.GenerateTemplate(icon.StateHigh)
+	// `sensei record` saves the probe stream instead of showing it
+	if len(os.Args) > 1 && os.Args[1] == "record" {
+		os.Exit(runRecord(os.Args[2:]))
+	}
 
-	var reader *pipe.PipeReader
+	configPath := flag.String("config", config.DefaultPath(), "path to the JSON config file")
+	statusFlag := flag.Bool("status", false, "print the running sensei's status and exit")
+	openDojoFlag := flag.Bool("open-dojo", false, "ask the running sensei to open the dojo")
+	quitFlag := flag.Bool("quit", false, "ask the running sensei to quit")
+	headlessFlag := flag.Bool("headless", false, "run in the foreground without a system tray")
+	logLevel := flag.String("log-level", "", "log level: debug, info, warn or error (overrides the config)")
+	replayPath := flag.String("replay", "", "show a recording made with `sensei record` instead of the live probe")
+	speed := flag.Float64("speed", 1, "how many times faster than real time to replay")
+	flag.Parse()
 
-	onReady := func() {
-		// Setup the system tray
-		cpuLabel, dojoItem, quitItem := tray.Setup(icons, templates)
+	// Control flags talk to the running instance instead of starting one
+	switch {
+	case *statusFlag:
+		os.Exit(runControl(control.CmdStatus))
+	case *openDojoFlag:
+		os.Exit(runControl(control.CmdOpenDojo))
+	case *quitFlag:
+		os.Exit(runControl(control.CmdQuit))
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	classifyBy, err := icon.ParseInput(cfg.Tray.ClassifyBy)
+	if err != nil {
+		log.Fatalf("Invalid tray config: %v", err)
+	}
+
+	classifier, err := icon.NewClassifier(cfg.Classify)
+	if err != nil {
+		log.Fatalf("Invalid classify config: %v", err)
+	}
 
-		// Open the pipe reader
-		var err error
-		reader, err = pipe.NewPipeReader(pipePath)
+	var painter *tray.Painter
+	if !*headlessFlag {
+		painter, err = newPainter(cfg.Tray, filepath.Join(filepath.Dir(*configPath), "themes"), classifier.Tinted())
 		if err != nil {
-			log.Printf("Warning: Failed to open pipe at %s: %v", pipePath, err)
-			log.Printf("Make sure the probe is running. Menu will show disconnected state.")
-			return
+			log.Fatalf("Invalid tray config: %v", err)
 		}
+	}
 
-		// Start reading from the pipe
-		reader.Start()
+	smoother, err := icon.NewSmoother(classifier, cfg.Tray.Smoothing)
+	if err != nil {
+		log.Fatalf("Invalid tray config: %v", err)
+	}
 
-		// Launch goroutine to process CPU readings
-		go func() {
-			for reading := range reader.Readings() {
-				state := icon.Classify(reading.CpuPercent)
-				tray.UpdateIcon(state)
-				tray.UpdateLabel(cpuLabel, reading.CpuPercent, state)
-			}
+	alerts, err := alert.NewEngine(cfg.Alerts)
+	if err != nil {
+		log.Fatalf("Invalid alerts config: %v", err)
+	}
 
-			// If we get here, the pipe was closed (probe disconnected)
-			log.Println("Pipe closed - probe disconnected")
-			tray.UpdateLabel(cpuLabel, -1, icon.StateIdle)
-			tray.UpdateIcon(icon.StateIdle)
-		}()
+	policy, err := protect.New(cfg.Protect)
+	if err != nil {
+		log.Fatalf("Invalid protect config: %v", err)
+	}
+	for _, spec := range cfg.Rules.Rules {
+		if _, err := rules.Compile(spec); err != nil {
+			log.Fatalf("Invalid rules config: %v", err)
+		}
+	}
+
+	// A replay stands in for the probe, so none is started
+	var replay []record.Frame
+	var probe *supervisor.Supervisor
+	if *replayPath != "" {
+		if *speed <= 0 {
+			log.Fatalf("Invalid --speed: must be positive, got %v", *speed)
+		}
+		replay, err = record.ReadFile(*replayPath)
+		if err != nil {
+			log.Fatalf("Failed to load recording: %v", err)
+		}
+	} else {
+		probe, err = newSupervisor(cfg.Probe)
+		if err != nil {
+			log.Fatalf("Invalid probe config: %v", err)
+		}
+	}
+
+	// Log to stderr and a rotating file next to the audit log. Config
+	// errors above go to stderr only; after this the standard log
+	// package writes through slog too.
+	logPath := logging.DefaultPath(config.StateDir())
+	logFile, err := logging.Setup(cfg.Log, *logLevel, logPath)
+	switch {
+	case errors.Is(err, logging.ErrFileUnavailable):
+		slog.Warn("Logging to stderr only", "path", logPath, "err", err)
+	case err != nil:
+		log.Fatalf("Invalid log config: %v", err)
+	default:
+		defer logFile.Close()
+	}
+
+	// Rule actions and tray kills are recorded in the shared audit log
+	auditPath := audit.DefaultPath(config.StateDir())
+	auditLog, err := audit.Open(auditPath)
+	if err != nil {
+		slog.Warn("Failed to open audit log", "path", auditPath, "err", err)
+	}
+
+	// A missing terminal is reported when Open Dojo is clicked
+	launcher, launcherErr := launch.Detect(cfg.Dojo.Terminal)
+
+	// Readings go to the status socket, alerts and, if configured, the
+	// exporter; the tray adds itself when there is one
+	self := newInstance(probe)
+	percent := func(reading pipe.CpuReading) float64 { return classifyPercent(reading, classifyBy) }
+	pipeline := sink.NewPipeline(smoother, classifier, percent, self)
+
+	// A replay only shows the recording. It leaves the lock, control
+	// socket and exporter to a live sensei, and doesn't act on or alert
+	// about readings from the past.
+	var lock *control.Lock
+	var exportServer *exporter.Server
+	var engine *rules.Engine
+	if replay == nil {
+		// Two senseis would fight over the FIFO
+		lock = lockInstance()
+		pipeline.Add(alertSink{alerts})
+		exportServer = startExporter(cfg.Exporter, probe, pipeline)
+
+		// Start the auto-shuriken rule engine if any rules are configured
+		engine = startRules(cfg, policy, auditLog)
+	}
+
+	d := &daemon{
+		configPath:  *configPath,
+		probe:       probe,
+		reconnect:   *headlessFlag,
+		self:        self,
+		pipeline:    pipeline,
+		launcher:    launcher,
+		launcherErr: launcherErr,
+		engine:      engine,
+		auditLog:    auditLog,
+		lock:        lock,
+		exporter:    exportServer,
+		replay:      record.Segments(replay),
+		speed:       *speed,
+	}
+	if replay != nil {
+		slog.Info("Replaying recording", "path", *replayPath, "frames", len(replay), "speed", *speed)
+	}
+
+	if *headlessFlag {
+		runHeadless(d)
+		return
+	}
+	runTray(d, painter, classifier, tray.NewTerminator(policy, auditLog, notify.Send))
+}
+
+// runTray runs sensei in the system tray until Quit
+func runTray(d *daemon, painter *tray.Painter, classifier *icon.Classifier, terminator *tray.Terminator) {
+	onReady := func() {
+		// Setup the system tray
+		menu := tray.Setup(painter, classifier, terminator)
+		d.pipeline.Add(tray.NewSink(menu, painter))
+		go menu.Top.Run(topInterval)
+
+		// Opening the dojo from the menu or the control socket shows
+		// failures on the menu item and as a notification
+		openDojo := func() error {
+			err := d.openDojo()
+			tray.UpdateDojo(menu.Dojo, err)
+			if err != nil {
+				slog.Error("Failed to launch dojo", "err", err)
+				if nerr := notify.Send("Dojo failed to open", err.Error()); nerr != nil {
+					slog.Warn("Failed to notify", "err", nerr)
+				}
+			}
+			return err
+		}
 
 		// Handle dojo button clicks
 		go func() {
-			for range dojoItem.ClickedCh {
-				if err := launchDojo(); err != nil {
-					log.Printf("Failed to launch dojo: %v", err)
-				}
+			for range menu.Dojo.ClickedCh {
+				openDojo()
 			}
 		}()
 
 		// Handle quit button clicks
 		go func() {
-			<-quitItem.ClickedCh
-			log.Println("Quit requested")
+			<-menu.Quit.ClickedCh
+			slog.Info("Quit requested")
 			systray.Quit()
 		}()
+
+		// Show the managed probe's health, refreshing its uptime
+		if d.probe != nil {
+			go func() {
+				ticker := time.NewTicker(probeInterval)
+				defer ticker.Stop()
+				for {
+					tray.UpdateProbe(menu.Probe, d.probe.Health(), time.Now())
+					<-ticker.C
+				}
+			}()
+		}
+
+		d.start(openDojo, systray.Quit)
 	}
 
 	onExit := func() {
-		if reader != nil {
-			reader.Stop()
-		}
-		log.Println("Sensei exiting...")
+		painter.Stop()
+		d.stop()
+		slog.Info("Sensei exiting")
 	}
 
 	systray.Run(onReady, onExit)
 }
 
-// launchDojo opens a new Terminal window running the dojo binary
-func launchDojo() error {
+// startExporter serves the Prometheus exporter when an address is
+// configured, adding it to the pipeline. It returns nil, after logging,
+// if the address can't be listened on.
+func startExporter(cfg config.Exporter, probe *supervisor.Supervisor, pipeline *sink.Pipeline) *exporter.Server {
+	if cfg.Listen == "" {
+		return nil
+	}
+	var health func() supervisor.Health
+	if probe != nil {
+		health = probe.Health
+	}
+	exp := exporter.New(health)
+	server, err := exporter.Listen(cfg.Listen, exp)
+	if err != nil {
+		slog.Warn("Exporter disabled", "err", err)
+		return nil
+	}
+	pipeline.Add(exp)
+	slog.Info("Exporting metrics", "url", "http://"+server.Addr()+exporter.MetricsPath)
+	return server
+}
+
+// newSupervisor returns the supervisor for a managed probe, or nil when
+// the user starts the probe themselves
+func newSupervisor(cfg config.Probe) (*supervisor.Supervisor, error) {
+	if !cfg.Manage {
+		return nil, nil
+	}
+	path := cfg.Path
+	if path == "" {
+		exePath, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("could not find executable path: %w", err)
+		}
+		path = filepath.Join(filepath.Dir(exePath), "probe")
+	}
+	return supervisor.New(cfg, path, pipePath)
+}
+
+// newPainter builds the tray icon painter from the tray config. Bare theme
+// names are looked up in themesDir.
+func newPainter(cfg config.Tray, themesDir string, tint bool) (*tray.Painter, error) {
+	overlay, err := icon.ParseOverlay(cfg.Overlay)
+	if err != nil {
+		return nil, err
+	}
+	opts := icon.Options{Size: cfg.IconSize, Scale: cfg.IconScale, Overlay: overlay}
+
+	switch name := cfg.Theme; {
+	case name == "" || name == icon.Ninja.Name():
+	case strings.ContainsRune(name, filepath.Separator):
+		opts.Theme, err = icon.LoadTheme(name)
+	default:
+		opts.Theme, err = icon.LoadTheme(filepath.Join(themesDir, name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tray.NewPainter(opts, cfg.Animate, tint)
+}
+
+// classifyPercent picks the percentage to classify from the configured
+// input, falling back to CPU% when the probe doesn't report that metric
+func classifyPercent(reading pipe.CpuReading, by icon.Input) float64 {
+	switch {
+	case by == icon.InputLoad && reading.Load != nil:
+		return icon.LoadPercent(reading.Load.Load1, runtime.NumCPU())
+	case by == icon.InputPressure && reading.Load != nil && reading.Load.CPUPressure != nil:
+		return icon.PressurePercent(reading.Load.CPUPressure.SomeAvg10)
+	}
+	return reading.CpuPercent
+}
+
+// raiseAlert notifies the user when an alert starts firing and logs
+// when it clears
+func raiseAlert(ev alert.Event) {
+	cond := ev.Rule.Condition
+	if !ev.Firing {
+		slog.Info("Alert cleared", "alert", ev.Rule.Name, "metric", cond.Metric, "value", cond.FormatValue(ev.Value))
+		return
+	}
+	slog.WarnContext(logging.Event(context.Background()), "Alert firing", "alert", ev.Rule.Name, "metric", cond.Metric, "value", cond.FormatValue(ev.Value))
+	msg := fmt.Sprintf("%s is %s (%s)", cond.Metric, cond.FormatValue(ev.Value), cond)
+	if err := notify.Send(ev.Rule.Name, msg); err != nil {
+		slog.Warn("Failed to show notification", "err", err)
+	}
+}
+
+// startRules starts the rule engine. It returns nil when no rules are
+// configured or there is no audit log to record their actions.
+func startRules(cfg config.Config, policy *protect.Policy, auditLog *audit.Log) *rules.Engine {
+	if len(cfg.Rules.Rules) == 0 {
+		return nil
+	}
+	if auditLog == nil {
+		slog.Warn("Rules disabled, they need the audit log")
+		return nil
+	}
+
+	engine, err := rules.NewEngine(cfg.Rules, policy, auditLog)
+	if err != nil {
+		log.Fatalf("Invalid rules config: %v", err)
+	}
+
+	mode := "enforcing"
+	if cfg.Rules.DryRun {
+		mode = "dry-run"
+	}
+	slog.Info("Rules loaded", "rules", len(cfg.Rules.Rules), "mode", mode, "audit", auditLog.Path())
+	engine.Start()
+	return engine
+}
+
+// launchDojo opens a new terminal window running the dojo binary with
+// the same config file
+func launchDojo(launcher *launch.Launcher, configPath string) error {
 	exePath, err := os.Executable()
 	if err != nil {
 		return fmt.Errorf("could not find executable path: %w", err)
@@ -96,12 +393,5 @@ func launchDojo() error {
 		return fmt.Errorf("dojo binary not found at %s: %w", dojoPath, err)
 	}
 
-	// Use AppleScript to open a new Terminal window with dojo
-	cmd := exec.Command("osascript",
-		"-e", `tell application "Terminal"`,
-		"-e", fmt.Sprintf(`do script "%s"`, dojoPath),
-		"-e", `activate`,
-		"-e", `end tell`,
-	)
-	return cmd.Start()
+	return launcher.Open(dojoPath, "-config", configPath)
 }
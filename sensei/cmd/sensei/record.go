@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"system-shinobi/sensei/internal/config"
+	"system-shinobi/sensei/internal/control"
+	"system-shinobi/sensei/internal/pipe"
+	"system-shinobi/sensei/internal/process"
+	"system-shinobi/sensei/internal/record"
+)
+
+// runRecord implements `sensei record`: it saves probe readings, probe
+// disconnects and the top processes to a file until interrupted. The
+// frames are streamed from the running sensei when there is one, which
+// holds the FIFO, and read from the FIFO otherwise.
+func runRecord(args []string) int {
+	flags := flag.NewFlagSet("sensei record", flag.ExitOnError)
+	out := flags.String("o", "", "file to write (default shinobi-<time>.jsonl)")
+	duration := flags.Duration("duration", 0, "stop after this long (default until interrupted)")
+	procs := flags.Int("processes", 30, "top processes to save with each reading, 0 for none")
+	flags.Parse(args)
+
+	if *procs < 0 {
+		fmt.Fprintf(os.Stderr, "sensei record: -processes must not be negative\n")
+		return 2
+	}
+	path := *out
+	if path == "" {
+		path = "shinobi-" + time.Now().Format("20060102-150405") + ".jsonl"
+	}
+
+	frames, stop, err := recordSource()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sensei record: %v\n", err)
+		return 1
+	}
+	defer stop()
+
+	file, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sensei record: %v\n", err)
+		return 1
+	}
+	defer file.Close()
+	w := record.NewWriter(file)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	var deadline <-chan time.Time
+	if *duration > 0 {
+		deadline = time.After(*duration)
+	}
+
+	fmt.Fprintf(os.Stderr, "Recording to %s, press Ctrl-C to stop\n", path)
+	count := 0
+	for {
+		select {
+		case f, ok := <-frames:
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Recorded %d frame(s) to %s\n", count, path)
+				return 0
+			}
+			switch {
+			case f.Reading == nil:
+				fmt.Fprintf(os.Stderr, "Probe disconnected at %s\n", f.At.Format(time.TimeOnly))
+			case *procs > 0:
+				// A failed listing still leaves the reading worth keeping
+				f.Processes, _ = process.ListTop(*procs)
+			}
+			if err := w.Write(f); err != nil {
+				fmt.Fprintf(os.Stderr, "sensei record: %v\n", err)
+				return 1
+			}
+			count++
+		case <-signals:
+			fmt.Fprintf(os.Stderr, "Recorded %d frame(s) to %s\n", count, path)
+			return 0
+		case <-deadline:
+			fmt.Fprintf(os.Stderr, "Recorded %d frame(s) to %s\n", count, path)
+			return 0
+		}
+	}
+}
+
+// recordSource returns the frames to record and a function that stops
+// them, streaming them from the running sensei if there is one and
+// reading the FIFO if not. The channel closes when the source ends.
+func recordSource() (<-chan record.Frame, func(), error) {
+	sub, err := control.Subscribe(control.SocketPath(config.StateDir()), control.CmdReadings)
+	if err == nil {
+		return watchSensei(sub), func() { sub.Close() }, nil
+	}
+	if !errors.Is(err, control.ErrNotRunning) {
+		return nil, nil, err
+	}
+
+	reader, err := pipe.NewPipeReader(pipePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sensei is not running and the probe's pipe can't be opened: %w", err)
+	}
+	reader.Start()
+	frames := make(chan record.Frame)
+	go func() {
+		defer close(frames)
+		for reading := range reader.Readings() {
+			frames <- record.Frame{At: time.Now(), Reading: &reading}
+		}
+		// Without sensei there is nothing to reconnect, so the
+		// recording ends at the disconnect
+		frames <- record.Frame{At: time.Now()}
+	}()
+	return frames, reader.Stop, nil
+}
+
+// watchSensei passes on every frame sensei streams, readings and
+// disconnects alike, until sensei ends the stream or sub is closed
+func watchSensei(sub *control.Subscription) <-chan record.Frame {
+	frames := make(chan record.Frame)
+	go func() {
+		defer close(frames)
+		for {
+			var f record.Frame
+			err := sub.Next(&f)
+			if errors.Is(err, io.EOF) {
+				fmt.Fprintf(os.Stderr, "sensei stopped\n")
+				return
+			}
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					fmt.Fprintf(os.Stderr, "sensei record: %v\n", err)
+				}
+				return
+			}
+			frames <- f
+		}
+	}()
+	return frames
+}
@@ -0,0 +1,213 @@
+// Package alert evaluates threshold conditions such as "temp > 90C"
+// against the probe's readings and reports when they start and stop
+// holding.
+package alert
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"system-shinobi/sensei/internal/config"
+	"system-shinobi/sensei/internal/pipe"
+)
+
+// metricUnits lists the metrics a condition can test, with the unit
+// suffix a threshold may carry
+var metricUnits = map[string]string{
+	"cpu":          "%",   // total CPU usage
+	"mem":          "%",   // memory in use
+	"swap":         "%",   // swap in use
+	"load1":        "",    // 1 minute load average
+	"cpu_pressure": "%",   // PSI some avg10
+	"mem_pressure": "%",   // PSI some avg10
+	"io_pressure":  "%",   // PSI some avg10
+	"temp":         "C",   // CPU temperature
+	"battery":      "%",   // battery charge
+	"power":        "W",   // battery charge or discharge rate
+	"freq":         "MHz", // average CPU frequency
+}
+
+// Condition is a parsed threshold test
+type Condition struct {
+	Metric    string
+	Op        string // >, >=, < or <=
+	Threshold float64
+}
+
+var conditionPattern = regexp.MustCompile(`^([a-z0-9_]+)\s*(>=|<=|>|<)\s*([0-9]+(?:\.[0-9]+)?)\s*(%|°?C|W|MHz)?$`)
+
+// ParseCondition parses an expression such as "temp > 90C",
+// "battery < 15%" or "load1 >= 8"
+func ParseCondition(expr string) (Condition, error) {
+	m := conditionPattern.FindStringSubmatch(strings.TrimSpace(expr))
+	if m == nil {
+		return Condition{}, fmt.Errorf("invalid condition %q, expected e.g. \"temp > 90C\"", expr)
+	}
+	unit, ok := metricUnits[m[1]]
+	if !ok {
+		return Condition{}, fmt.Errorf("unknown metric %q in %q", m[1], expr)
+	}
+	if suffix := strings.TrimPrefix(m[4], "°"); suffix != "" && suffix != unit {
+		return Condition{}, fmt.Errorf("%s is measured in %q, not %q", m[1], unit, m[4])
+	}
+	threshold, err := strconv.ParseFloat(m[3], 64)
+	if err != nil {
+		return Condition{}, err
+	}
+	return Condition{Metric: m[1], Op: m[2], Threshold: threshold}, nil
+}
+
+// Holds reports whether value satisfies the condition
+func (c Condition) Holds(value float64) bool {
+	switch c.Op {
+	case ">":
+		return value > c.Threshold
+	case ">=":
+		return value >= c.Threshold
+	case "<":
+		return value < c.Threshold
+	case "<=":
+		return value <= c.Threshold
+	}
+	return false
+}
+
+// String formats the condition with its unit, e.g. "temp > 90C"
+func (c Condition) String() string {
+	return fmt.Sprintf("%s %s %s%s", c.Metric, c.Op, strconv.FormatFloat(c.Threshold, 'f', -1, 64), metricUnits[c.Metric])
+}
+
+// FormatValue formats a value of the condition's metric with its unit
+func (c Condition) FormatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', 1, 64) + metricUnits[c.Metric]
+}
+
+// Sample is the metric values of one reading, keyed by metric name.
+// Metrics the probe didn't report are absent.
+type Sample map[string]float64
+
+// FromReading extracts every known metric from a probe reading
+func FromReading(r pipe.CpuReading) Sample {
+	s := Sample{"cpu": r.CpuPercent}
+	if mem := r.Memory; mem != nil {
+		s["mem"] = mem.UsedPercent()
+		s["swap"] = mem.SwapPercent()
+		if mem.Pressure != nil {
+			s["mem_pressure"] = mem.Pressure.SomeAvg10
+		}
+	}
+	if load := r.Load; load != nil {
+		s["load1"] = load.Load1
+		if load.CPUPressure != nil {
+			s["cpu_pressure"] = load.CPUPressure.SomeAvg10
+		}
+		if load.IOPressure != nil {
+			s["io_pressure"] = load.IOPressure.SomeAvg10
+		}
+	}
+	if power := r.Power; power != nil {
+		if power.CPUTempC > 0 {
+			s["temp"] = power.CPUTempC
+		}
+		if power.CPUFreqMHz > 0 {
+			s["freq"] = power.CPUFreqMHz
+		}
+		if power.Battery != nil {
+			s["battery"] = power.Battery.Percent
+			if power.Battery.Watts > 0 {
+				s["power"] = power.Battery.Watts
+			}
+		}
+	}
+	return s
+}
+
+// Rule is a named condition that must hold for a while before it fires
+type Rule struct {
+	Name      string
+	Condition Condition
+	For       time.Duration
+}
+
+// Event reports that a rule started firing, or stopped
+type Event struct {
+	Rule   Rule
+	Value  float64
+	Time   time.Time
+	Firing bool // false when the condition has cleared
+}
+
+// Engine tracks how long each rule's condition has held. It is not safe
+// for concurrent use.
+type Engine struct {
+	rules  []Rule
+	since  map[string]time.Time // when each rule's condition started holding
+	firing map[string]bool
+}
+
+// NewEngine compiles the configured alerts
+func NewEngine(specs []config.AlertSpec) (*Engine, error) {
+	e := &Engine{
+		since:  make(map[string]time.Time),
+		firing: make(map[string]bool),
+	}
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		cond, err := ParseCondition(spec.When)
+		if err != nil {
+			return nil, err
+		}
+		name := spec.Name
+		if name == "" {
+			name = cond.String()
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate alert name %q", name)
+		}
+		seen[name] = true
+		e.rules = append(e.rules, Rule{Name: name, Condition: cond, For: spec.For.Duration})
+	}
+	return e, nil
+}
+
+// Len returns the number of rules
+func (e *Engine) Len() int {
+	return len(e.rules)
+}
+
+// Evaluate checks every rule against a sample. A rule fires once its
+// condition has held for its For duration, and reports again when the
+// condition clears. Rules whose metric is missing from the sample keep
+// their state.
+func (e *Engine) Evaluate(now time.Time, s Sample) []Event {
+	var events []Event
+	for _, r := range e.rules {
+		v, ok := s[r.Condition.Metric]
+		if !ok {
+			continue
+		}
+
+		if !r.Condition.Holds(v) {
+			delete(e.since, r.Name)
+			if e.firing[r.Name] {
+				delete(e.firing, r.Name)
+				events = append(events, Event{Rule: r, Value: v, Time: now})
+			}
+			continue
+		}
+
+		since, ok := e.since[r.Name]
+		if !ok {
+			since = now
+			e.since[r.Name] = now
+		}
+		if !e.firing[r.Name] && now.Sub(since) >= r.For {
+			e.firing[r.Name] = true
+			events = append(events, Event{Rule: r, Value: v, Time: now, Firing: true})
+		}
+	}
+	return events
+}
@@ -0,0 +1,124 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"system-shinobi/sensei/internal/config"
+	"system-shinobi/sensei/internal/metrics"
+	"system-shinobi/sensei/internal/pipe"
+)
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		expr string
+		want Condition
+	}{
+		{"temp > 90C", Condition{"temp", ">", 90}},
+		{"temp>90°C", Condition{"temp", ">", 90}},
+		{"battery < 15%", Condition{"battery", "<", 15}},
+		{"load1 >= 8", Condition{"load1", ">=", 8}},
+		{" cpu <= 12.5 ", Condition{"cpu", "<=", 12.5}},
+	}
+	for _, tt := range tests {
+		got, err := ParseCondition(tt.expr)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseCondition(%q) = %+v, %v; expected %+v", tt.expr, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "temp", "temp = 90", "gpu > 50", "temp > 90%", "battery < lots"} {
+		if _, err := ParseCondition(bad); err == nil {
+			t.Errorf("ParseCondition(%q) should fail", bad)
+		}
+	}
+}
+
+func TestConditionString(t *testing.T) {
+	c, _ := ParseCondition("temp>90C")
+	if c.String() != "temp > 90C" || c.FormatValue(93.25) != "93.2C" {
+		t.Errorf("Unexpected formatting: %q, %q", c.String(), c.FormatValue(93.25))
+	}
+}
+
+func TestFromReading(t *testing.T) {
+	r := pipe.CpuReading{
+		CpuPercent: 40,
+		Memory:     &metrics.Memory{Total: 100, Used: 75},
+		Power: &metrics.Power{
+			CPUTempC: 91,
+			Battery:  &metrics.Battery{Percent: 12, Watts: 9.5},
+		},
+	}
+	s := FromReading(r)
+	if s["cpu"] != 40 || s["mem"] != 75 || s["temp"] != 91 || s["battery"] != 12 || s["power"] != 9.5 {
+		t.Errorf("Unexpected sample: %v", s)
+	}
+	if _, ok := s["load1"]; ok {
+		t.Error("Load wasn't reported and should be absent")
+	}
+	if _, ok := s["freq"]; ok {
+		t.Error("An unreported frequency should be absent")
+	}
+}
+
+func TestFromReadingSkipsUnknownPower(t *testing.T) {
+	r := pipe.CpuReading{
+		Power: &metrics.Power{Battery: &metrics.Battery{Percent: 80}},
+	}
+	s := FromReading(r)
+	if s["battery"] != 80 {
+		t.Errorf("Unexpected sample: %v", s)
+	}
+	if _, ok := s["power"]; ok {
+		t.Error("A battery without a rate should not report power")
+	}
+}
+
+func TestEngineFiresAfterDurationAndClears(t *testing.T) {
+	e, err := NewEngine([]config.AlertSpec{
+		{Name: "hot", When: "temp > 90C", For: config.Duration{Duration: 30 * time.Second}},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	start := time.Unix(1707860342, 0)
+
+	if ev := e.Evaluate(start, Sample{"temp": 95}); len(ev) != 0 {
+		t.Fatalf("Should not fire before 30s, got %+v", ev)
+	}
+	ev := e.Evaluate(start.Add(30*time.Second), Sample{"temp": 94})
+	if len(ev) != 1 || !ev[0].Firing || ev[0].Value != 94 || ev[0].Rule.Name != "hot" {
+		t.Fatalf("Expected the rule to fire, got %+v", ev)
+	}
+	if ev := e.Evaluate(start.Add(40*time.Second), Sample{"temp": 96}); len(ev) != 0 {
+		t.Errorf("A firing rule should not fire again, got %+v", ev)
+	}
+	if ev := e.Evaluate(start.Add(45*time.Second), Sample{"cpu": 10}); len(ev) != 0 {
+		t.Errorf("A missing metric should not clear the rule, got %+v", ev)
+	}
+	ev = e.Evaluate(start.Add(50*time.Second), Sample{"temp": 80})
+	if len(ev) != 1 || ev[0].Firing {
+		t.Fatalf("Expected the rule to clear, got %+v", ev)
+	}
+}
+
+func TestEngineResetsWhenConditionBreaks(t *testing.T) {
+	e, _ := NewEngine([]config.AlertSpec{{When: "battery < 15%", For: config.Duration{Duration: time.Minute}}})
+	start := time.Unix(1707860342, 0)
+
+	e.Evaluate(start, Sample{"battery": 14})
+	e.Evaluate(start.Add(50*time.Second), Sample{"battery": 16})
+	if ev := e.Evaluate(start.Add(70*time.Second), Sample{"battery": 14}); len(ev) != 0 {
+		t.Errorf("The timer should restart after the condition breaks, got %+v", ev)
+	}
+}
+
+func TestNewEngineRejectsBadSpecs(t *testing.T) {
+	if _, err := NewEngine([]config.AlertSpec{{When: "temp >"}}); err == nil {
+		t.Error("Expected an invalid condition to be rejected")
+	}
+	if _, err := NewEngine([]config.AlertSpec{{When: "temp > 90C"}, {When: "temp>90C"}}); err == nil {
+		t.Error("Expected duplicate default names to be rejected")
+	}
+}
@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Outcomes recorded for signals that were not delivered with an error
+const (
+	OutcomeOK     = "ok"
+	OutcomeDryRun = "dry-run"
+)
+
+// InitiatorInteractive marks signals sent by a person from the dojo
+const InitiatorInteractive = "interactive"
+
+// InitiatorTray marks signals sent by a person from the tray menu
+const InitiatorTray = "tray"
+
+// RuleInitiator returns the initiator recorded for signals sent by a rule
+func RuleInitiator(rule string) string {
+	return "rule:" + rule
+}
+
+// Entry is one signal or systemd unit action, written as a single JSON
+// line. Signal entries set PID and Signal; unit entries set Unit and
+// Action instead.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	PID       int       `json:"pid,omitempty"`
+	Name      string    `json:"name"`
+	Command   string    `json:"command,omitempty"` // full command line, if it could be read
+	Signal    string    `json:"signal,omitempty"`
+	Unit      string    `json:"unit,omitempty"`   // the systemd unit acted on
+	Action    string    `json:"action,omitempty"` // "stop", "restart" or "mask"
+	Outcome   string    `json:"outcome"`          // OutcomeOK, OutcomeDryRun or the error text
+	Initiator string    `json:"initiator"`        // InitiatorInteractive, InitiatorTray or RuleInitiator(name)
+}
+
+// IsUnitAction reports whether the entry records a systemd unit action
+// rather than a signal
+func (e Entry) IsUnitAction() bool {
+	return e.Unit != ""
+}
+
+// Outcome returns the outcome string recorded for a signal that returned err
+func Outcome(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return OutcomeOK
+}
+
+// Log is an append-only audit log backed by a JSON lines file.
+// It is safe for concurrent use, and a nil *Log discards entries.
+type Log struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// Open opens (or creates) the audit log at path, creating parent directories
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{path: path, file: file}, nil
+}
+
+// Path returns the file the log appends to
+func (l *Log) Path() string {
+	if l == nil {
+		return ""
+	}
+	return l.path
+}
+
+// Record appends e to the log
+func (l *Log) Record(e Entry) error {
+	if l == nil {
+		return nil
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.file.Write(data)
+	return err
+}
+
+// Close closes the underlying file
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// ReadTail returns up to n of the most recent entries in the log at
+// path, newest first. Lines that fail to parse are skipped.
+func ReadTail(path string, n int) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+		if len(entries) > n {
+			entries = entries[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Newest first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// DefaultPath returns the audit log location inside stateDir
+func DefaultPath(stateDir string) string {
+	return filepath.Join(stateDir, "audit.jsonl")
+}
@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordAndReadTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "audit.jsonl")
+	log, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	base := time.Unix(1707860342, 0).UTC()
+	for i := 0; i < 5; i++ {
+		err := log.Record(Entry{
+			Time:      base.Add(time.Duration(i) * time.Second),
+			PID:       100 + i,
+			Name:      "node",
+			Command:   "node server.js",
+			Signal:    "SIGTERM",
+			Outcome:   OutcomeOK,
+			Initiator: InitiatorInteractive,
+		})
+		if err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	log.Close()
+
+	entries, err := ReadTail(path, 3)
+	if err != nil {
+		t.Fatalf("ReadTail failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].PID != 104 || entries[2].PID != 102 {
+		t.Errorf("Expected newest first (104..102), got %d..%d", entries[0].PID, entries[2].PID)
+	}
+	if entries[0].Command != "node server.js" || !entries[0].Time.Equal(base.Add(4*time.Second)) {
+		t.Errorf("Entry did not round-trip: %+v", entries[0])
+	}
+}
+
+func TestReopenAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	for i := 0; i < 2; i++ {
+		log, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		log.Record(Entry{PID: i, Signal: "SIGKILL", Outcome: OutcomeOK})
+		log.Close()
+	}
+
+	entries, err := ReadTail(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 entries after reopening, got %d", len(entries))
+	}
+}
+
+func TestReadTailSkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	data := `{"pid":1,"signal":"SIGTERM","outcome":"ok"}` + "\n" +
+		`{"pid":` + "\n" +
+		`{"pid":2,"signal":"SIGKILL","outcome":"ok"}` + "\n"
+	os.WriteFile(path, []byte(data), 0o644)
+
+	entries, err := ReadTail(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].PID != 2 {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+}
+
+func TestNilLogDiscards(t *testing.T) {
+	var log *Log
+	if err := log.Record(Entry{PID: 1}); err != nil {
+		t.Errorf("Nil log should discard entries, got %v", err)
+	}
+	if Outcome(errors.New("operation not permitted")) != "operation not permitted" || Outcome(nil) != OutcomeOK {
+		t.Error("Outcome should map errors to their text and nil to ok")
+	}
+}
+
+func TestUnitActionEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	log.Record(Entry{Time: time.Now(), Name: "nginx.service", Unit: "nginx.service", Action: "restart",
+		Outcome: OutcomeOK, Initiator: InitiatorInteractive})
+	log.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"pid"`) || strings.Contains(string(data), `"signal"`) {
+		t.Errorf("Expected no pid or signal in a unit entry: %s", data)
+	}
+	entries, err := ReadTail(path, 1)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ReadTail failed: %v %+v", err, entries)
+	}
+	if e := entries[0]; !e.IsUnitAction() || e.Action != "restart" || e.Signal != "" {
+		t.Errorf("Unexpected unit entry %+v", e)
+	}
+}
@@ -0,0 +1,240 @@
+// Package cgroup resolves which cgroup v2 group, systemd unit and
+// container a process belongs to, and reads per-group resource usage
+// from the cgroup filesystem.
+package cgroup
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"system-shinobi/sensei/internal/metrics"
+)
+
+// Group identifies the cgroup a process runs in
+type Group struct {
+	Path      string // cgroup v2 path, e.g. /system.slice/nginx.service
+	Unit      string // innermost systemd unit, e.g. nginx.service, if any
+	Container string // full container ID, if the process is containerized
+	Runtime   string // docker, podman, containerd, cri-o or container
+}
+
+// ShortID returns the first 12 characters of the container ID, the
+// form docker and podman print
+func (g Group) ShortID() string {
+	if len(g.Container) > 12 {
+		return g.Container[:12]
+	}
+	return g.Container
+}
+
+// Label returns a short human-readable name for the group: the
+// container, else the systemd unit, else the cgroup path
+func (g Group) Label() string {
+	switch {
+	case g.Container != "":
+		return g.Runtime + " " + g.ShortID()
+	case g.Unit != "":
+		return g.Unit
+	default:
+		return g.Path
+	}
+}
+
+// containerSegment matches a cgroup path segment naming a container,
+// e.g. docker-<id>.scope, libpod-<id>.scope or a bare <id>
+var containerSegment = regexp.MustCompile(`^(?:(docker|libpod|cri-containerd|crio)-)?([0-9a-f]{64})(?:\.scope)?$`)
+
+// runtimes maps container scope prefixes to runtime names
+var runtimes = map[string]string{
+	"docker":         "docker",
+	"libpod":         "podman",
+	"cri-containerd": "containerd",
+	"crio":           "cri-o",
+}
+
+// Resolve works out the systemd unit and container of a cgroup path
+func Resolve(path string) Group {
+	g := Group{Path: path}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, seg := range segments {
+		if m := containerSegment.FindStringSubmatch(seg); m != nil {
+			g.Container = m[2]
+			switch {
+			case m[1] != "":
+				g.Runtime = runtimes[m[1]]
+			case i > 0 && segments[i-1] == "docker":
+				// cgroupfs driver: /docker/<id>
+				g.Runtime = "docker"
+			default:
+				g.Runtime = "container"
+			}
+			continue
+		}
+		if strings.HasSuffix(seg, ".service") || strings.HasSuffix(seg, ".scope") {
+			g.Unit = seg
+		}
+	}
+	return g
+}
+
+// parseProcCgroup returns the cgroup v2 path from /proc/[pid]/cgroup.
+// On pure v2 systems the file has a single line; hybrid systems list
+// v1 controllers too:
+//
+//	12:memory:/user.slice
+//	0::/user.slice/user-1000.slice/session-2.scope
+func parseProcCgroup(data string) (string, error) {
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		if path, ok := strings.CutPrefix(scanner.Text(), "0::"); ok {
+			return path, nil
+		}
+	}
+	return "", errors.New("no cgroup v2 entry")
+}
+
+// Stats is the resource usage of one cgroup
+type Stats struct {
+	CPUUsage       time.Duration // cumulative CPU time, from cpu.stat
+	MemoryCurrent  uint64
+	MemoryMax      uint64 // zero when unlimited
+	CPUPressure    *metrics.Pressure
+	MemoryPressure *metrics.Pressure
+	IOPressure     *metrics.Pressure
+}
+
+// CPUPercent returns the CPU used between two samples taken elapsed
+// apart, as a percentage of one core like ps reports it
+func CPUPercent(prev, cur Stats, elapsed time.Duration) float64 {
+	if elapsed <= 0 || cur.CPUUsage < prev.CPUUsage {
+		return 0
+	}
+	return float64(cur.CPUUsage-prev.CPUUsage) / float64(elapsed) * 100
+}
+
+// readStats reads the cgroup at path under the cgroup filesystem
+// mounted at root. cpu.stat is required; memory and pressure files are
+// optional since controllers can be disabled per subtree.
+func readStats(root, path string) (Stats, error) {
+	dir := filepath.Join(root, path)
+
+	data, err := os.ReadFile(filepath.Join(dir, "cpu.stat"))
+	if err != nil {
+		return Stats{}, err
+	}
+	usage, err := parseCPUStat(string(data))
+	if err != nil {
+		return Stats{}, fmt.Errorf("%s: %w", path, err)
+	}
+	st := Stats{CPUUsage: usage}
+
+	if v, err := readUint(filepath.Join(dir, "memory.current")); err == nil {
+		st.MemoryCurrent = v
+	}
+	// memory.max holds "max" when there is no limit
+	if v, err := readUint(filepath.Join(dir, "memory.max")); err == nil {
+		st.MemoryMax = v
+	}
+	st.CPUPressure = readPressure(filepath.Join(dir, "cpu.pressure"))
+	st.MemoryPressure = readPressure(filepath.Join(dir, "memory.pressure"))
+	st.IOPressure = readPressure(filepath.Join(dir, "io.pressure"))
+	return st, nil
+}
+
+// parseCPUStat returns usage_usec from a cpu.stat file:
+//
+//	usage_usec 1234567
+//	user_usec 1000000
+//	system_usec 234567
+func parseCPUStat(data string) (time.Duration, error) {
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "usage_usec" {
+			v, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("usage_usec: %w", err)
+			}
+			return time.Duration(v) * time.Microsecond, nil
+		}
+	}
+	return 0, errors.New("usage_usec missing from cpu.stat")
+}
+
+// findUnits walks the hierarchy under root for systemd service cgroups
+// and returns their paths keyed by unit name. Services nested inside a
+// user manager (user@1000.service/app.slice/...) are included.
+func findUnits(root string) (map[string]string, error) {
+	units := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable subtrees are skipped, not fatal
+			if path == root {
+				return err
+			}
+			return fs.SkipDir
+		}
+		if !d.IsDir() || !strings.HasSuffix(d.Name(), ".service") {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if _, ok := units[d.Name()]; !ok {
+			units[d.Name()] = "/" + filepath.ToSlash(rel)
+		}
+		return nil
+	})
+	return units, err
+}
+
+// readPIDs returns the processes in the cgroup at path and every cgroup
+// below it, from their cgroup.procs files
+func readPIDs(root, path string) ([]int, error) {
+	var pids []int
+	err := filepath.WalkDir(filepath.Join(root, path), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != "cgroup.procs" {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Fields(string(data)) {
+			if pid, err := strconv.Atoi(line); err == nil {
+				pids = append(pids, pid)
+			}
+		}
+		return nil
+	})
+	return pids, err
+}
+
+func readUint(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+func readPressure(path string) *metrics.Pressure {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	p := metrics.ParsePressure(string(data))
+	return &p
+}
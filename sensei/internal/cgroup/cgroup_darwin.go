@@ -0,0 +1,25 @@
+package cgroup
+
+import "errors"
+
+var errUnsupported = errors.New("cgroups are only available on Linux")
+
+// ForPIDs is not available on macOS, which has no cgroups
+func ForPIDs(pids []int) (map[int]Group, error) {
+	return nil, errUnsupported
+}
+
+// ReadStats is not available on macOS, which has no cgroups
+func ReadStats(path string) (Stats, error) {
+	return Stats{}, errUnsupported
+}
+
+// Units is not available on macOS, which has no cgroups
+func Units() (map[string]string, error) {
+	return nil, errUnsupported
+}
+
+// PIDs is not available on macOS, which has no cgroups
+func PIDs(path string) ([]int, error) {
+	return nil, errUnsupported
+}
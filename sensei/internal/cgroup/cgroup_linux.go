@@ -0,0 +1,45 @@
+package cgroup
+
+import (
+	"os"
+	"strconv"
+)
+
+// root is where the unified cgroup v2 hierarchy is mounted
+const root = "/sys/fs/cgroup"
+
+// ForPIDs resolves the cgroup of each PID from /proc/[pid]/cgroup.
+// Processes that have exited, or that only appear in cgroup v1
+// hierarchies, are left out.
+func ForPIDs(pids []int) (map[int]Group, error) {
+	groups := make(map[int]Group, len(pids))
+	for _, pid := range pids {
+		data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/cgroup")
+		if err != nil {
+			continue
+		}
+		path, err := parseProcCgroup(string(data))
+		if err != nil {
+			continue
+		}
+		groups[pid] = Resolve(path)
+	}
+	return groups, nil
+}
+
+// ReadStats reads the CPU, memory and pressure of the cgroup at path
+func ReadStats(path string) (Stats, error) {
+	return readStats(root, path)
+}
+
+// Units returns the cgroup path of every systemd service, keyed by
+// unit name
+func Units() (map[string]string, error) {
+	return findUnits(root)
+}
+
+// PIDs returns the processes in the cgroup at path, including those in
+// nested cgroups
+func PIDs(path string) ([]int, error) {
+	return readPIDs(root, path)
+}
@@ -0,0 +1,128 @@
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+	id := "3f2a1b9c0d12e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9"
+	tests := []struct {
+		path    string
+		unit    string
+		runtime string
+		label   string
+	}{
+		{"/system.slice/docker-" + id + ".scope", "", "docker", "docker 3f2a1b9c0d12"},
+		{"/docker/" + id, "", "docker", "docker 3f2a1b9c0d12"},
+		{"/user.slice/user-1000.slice/user@1000.service/user.slice/libpod-" + id + ".scope/container", "user@1000.service", "podman", "podman 3f2a1b9c0d12"},
+		{"/kubepods.slice/kubepods-pod1.slice/cri-containerd-" + id + ".scope", "", "containerd", "containerd 3f2a1b9c0d12"},
+		{"/system.slice/nginx.service", "nginx.service", "", "nginx.service"},
+		{"/user.slice/user-1000.slice/session-2.scope", "session-2.scope", "", "session-2.scope"},
+		{"/", "", "", "/"},
+	}
+
+	for _, tt := range tests {
+		g := Resolve(tt.path)
+		if g.Unit != tt.unit || g.Runtime != tt.runtime || g.Label() != tt.label {
+			t.Errorf("Resolve(%q) = %+v (label %q), expected unit %q runtime %q label %q",
+				tt.path, g, g.Label(), tt.unit, tt.runtime, tt.label)
+		}
+		if tt.runtime != "" && g.Container != id {
+			t.Errorf("Resolve(%q) container = %q", tt.path, g.Container)
+		}
+	}
+}
+
+func TestParseProcCgroup(t *testing.T) {
+	path, err := parseProcCgroup("12:memory:/user.slice\n1:name=systemd:/user.slice\n0::/user.slice/session-2.scope\n")
+	if err != nil || path != "/user.slice/session-2.scope" {
+		t.Errorf("Expected the v2 path, got %q (%v)", path, err)
+	}
+
+	if _, err := parseProcCgroup("12:memory:/user.slice\n"); err == nil {
+		t.Error("Expected an error without a v2 entry")
+	}
+}
+
+func TestReadStats(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "system.slice", "nginx.service")
+	os.MkdirAll(dir, 0o755)
+	files := map[string]string{
+		"cpu.stat":        "usage_usec 2500000\nuser_usec 2000000\nsystem_usec 500000\n",
+		"memory.current":  "104857600\n",
+		"memory.max":      "max\n",
+		"memory.pressure": "some avg10=4.50 avg60=1.00 avg300=0.20 total=100\nfull avg10=1.25 avg60=0.00 avg300=0.00 total=10\n",
+	}
+	for name, data := range files {
+		os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644)
+	}
+
+	st, err := readStats(root, "/system.slice/nginx.service")
+	if err != nil {
+		t.Fatalf("readStats failed: %v", err)
+	}
+	if st.CPUUsage != 2500*time.Millisecond || st.MemoryCurrent != 104857600 || st.MemoryMax != 0 {
+		t.Errorf("Unexpected stats: %+v", st)
+	}
+	if st.MemoryPressure == nil || st.MemoryPressure.SomeAvg10 != 4.5 || st.MemoryPressure.FullAvg10 != 1.25 {
+		t.Errorf("Unexpected memory pressure: %+v", st.MemoryPressure)
+	}
+	if st.CPUPressure != nil || st.IOPressure != nil {
+		t.Error("Missing pressure files should leave pressure nil")
+	}
+
+	if _, err := readStats(root, "/missing.service"); err == nil {
+		t.Error("Expected an error for a missing cgroup")
+	}
+}
+
+func TestCPUPercent(t *testing.T) {
+	prev := Stats{CPUUsage: time.Second}
+	cur := Stats{CPUUsage: 4 * time.Second}
+	if got := CPUPercent(prev, cur, 2*time.Second); got != 150 {
+		t.Errorf("Expected 150%% (1.5 cores), got %.1f", got)
+	}
+	if got := CPUPercent(cur, prev, time.Second); got != 0 {
+		t.Errorf("Expected 0 when usage goes backwards, got %.1f", got)
+	}
+}
+
+func TestFindUnitsAndPIDs(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, data string) {
+		full := filepath.Join(root, path)
+		os.MkdirAll(filepath.Dir(full), 0o755)
+		os.WriteFile(full, []byte(data), 0o644)
+	}
+	write("system.slice/nginx.service/cgroup.procs", "100\n101\n")
+	write("system.slice/docker.service/cgroup.procs", "200\n")
+	write("system.slice/docker.service/sub/cgroup.procs", "201\n")
+	write("user.slice/user-1000.slice/user@1000.service/app.slice/pipewire.service/cgroup.procs", "300\n")
+	write("user.slice/user-1000.slice/session-2.scope/cgroup.procs", "400\n")
+
+	units, err := findUnits(root)
+	if err != nil {
+		t.Fatalf("findUnits failed: %v", err)
+	}
+	if len(units) != 4 {
+		t.Errorf("Expected 4 services, got %v", units)
+	}
+	if units["nginx.service"] != "/system.slice/nginx.service" {
+		t.Errorf("Unexpected nginx path: %q", units["nginx.service"])
+	}
+	if units["pipewire.service"] != "/user.slice/user-1000.slice/user@1000.service/app.slice/pipewire.service" {
+		t.Errorf("Unexpected pipewire path: %q", units["pipewire.service"])
+	}
+
+	pids, err := readPIDs(root, units["docker.service"])
+	if err != nil {
+		t.Fatalf("readPIDs failed: %v", err)
+	}
+	if len(pids) != 2 || pids[0] != 200 || pids[1] != 201 {
+		t.Errorf("Expected PIDs 200 and 201 including the nested cgroup, got %v", pids)
+	}
+}
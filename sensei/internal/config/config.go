@@ -0,0 +1,229 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Config is the user configuration shared by sensei and dojo, loaded
+// from a JSON file. Every section is optional.
+type Config struct {
+	Tray     Tray        `json:"tray"`
+	Classify Classify    `json:"classify"`
+	Dojo     Dojo        `json:"dojo"`
+	Probe    Probe       `json:"probe"`
+	Exporter Exporter    `json:"exporter"`
+	Log      Log         `json:"log"`
+	Rules    Rules       `json:"rules"`
+	Protect  Protect     `json:"protect"`
+	Disk     Disk        `json:"disk"`
+	Systemd  Systemd     `json:"systemd"`
+	Alerts   []AlertSpec `json:"alerts"`
+}
+
+// Tray configures the menu bar icon
+type Tray struct {
+	ClassifyBy string `json:"classify_by"` // "cpu" (default), "load" or "pressure"
+	IconSize   int    `json:"icon_size"`   // logical size in points, 16-64; 0 means 22
+	IconScale  int    `json:"icon_scale"`  // 2 renders @2x for HiDPI trays
+	Overlay    string `json:"overlay"`     // "none" (default), "bar" or "number"
+	Animate    bool   `json:"animate"`     // animate the icon in the High state
+	Theme      string `json:"theme"`       // "ninja" (default), a theme under <config dir>/themes, or a theme directory path
+
+	Smoothing Smoothing `json:"smoothing"`
+}
+
+// Smoothing damps the tray's classification so the icon and label don't
+// flap when the reading hovers around a level boundary
+type Smoothing struct {
+	Method     string   `json:"method"`      // "ema" (default), "window" or "none"
+	Alpha      float64  `json:"alpha"`       // EMA weight of the newest reading, 0-1
+	Window     int      `json:"window"`      // readings averaged in window mode
+	Dwell      Duration `json:"dwell"`       // minimum time in a level before it may change
+	UpMargin   float64  `json:"up_margin"`   // points above a level's start needed to enter it
+	DownMargin float64  `json:"down_margin"` // points below a level's start needed to leave it downwards
+}
+
+// Classify configures the load levels shared by the tray icon and the dojo
+type Classify struct {
+	Levels   []LevelSpec `json:"levels"`   // ascending levels; empty means the built-in Idle/Low/Medium/High
+	Gradient bool        `json:"gradient"` // interpolate colors between levels instead of stepping
+}
+
+// LevelSpec declares one level, e.g. {"name": "Hot", "from": 85, "color": "#ff0000"}
+type LevelSpec struct {
+	Name  string  `json:"name"`
+	From  float64 `json:"from"`  // CPU percentage where the level starts; the first level starts at 0
+	Color string  `json:"color"` // #rgb or #rrggbb
+	Icon  string  `json:"icon"`  // icon state to draw: idle, low, medium or high; spread evenly by default
+}
+
+// Dojo configures how the tray opens the dojo
+type Dojo struct {
+	// Terminal to open the dojo in, e.g. "kitty" or "foot --app-id dojo {}"
+	// ({} marks where the command goes); empty uses $TERMINAL or detects one
+	Terminal string `json:"terminal"`
+}
+
+// Probe configures whether sensei starts the probe itself
+type Probe struct {
+	Manage     bool     `json:"manage"`      // spawn the probe and restart it when it exits
+	Path       string   `json:"path"`        // probe binary; empty means "probe" next to sensei
+	Args       []string `json:"args"`        // extra arguments for the probe
+	MaxBackoff Duration `json:"max_backoff"` // longest wait between restarts; 0 means 1m
+}
+
+// Exporter configures the Prometheus metrics endpoint
+type Exporter struct {
+	Listen string `json:"listen"` // address to serve /metrics on, e.g. "127.0.0.1:9464"; empty disables it
+}
+
+// Log configures sensei's log, written to stderr and to sensei.log in
+// the state directory
+type Log struct {
+	Level          string   `json:"level"`           // "debug", "info" (default), "warn" or "error"
+	MaxSize        int      `json:"max_size_mb"`     // size at which the file is rotated; 0 means 10
+	MaxFiles       int      `json:"max_files"`       // rotated files kept; 0 means 3
+	RepeatInterval Duration `json:"repeat_interval"` // how often the same warning may repeat; 0 means 1m
+}
+
+// Disk configures the dojo's disk and filesystem scroll
+type Disk struct {
+	WarnAbove float64 `json:"warn_above"` // warn when a mount's space or inode use passes this percentage
+}
+
+// Systemd configures the dojo's !clan service scroll
+type Systemd struct {
+	User bool `json:"user"` // manage the user's systemd instance instead of the system one
+}
+
+// Protect lists processes that must not be signalled casually, on top
+// of the built-in list (PID 1, the window server, sshd, sensei itself...)
+type Protect struct {
+	Mode  string   `json:"mode"`  // "confirm" requires a typed confirmation, "refuse" blocks outright
+	PIDs  []int    `json:"pids"`  // specific process IDs
+	Names []string `json:"names"` // process names
+	Users []string `json:"users"` // every process owned by these users
+	Root  bool     `json:"root"`  // every process owned by root
+}
+
+// Rules configures the auto-shuriken rule engine
+type Rules struct {
+	DryRun    bool       `json:"dry_run"`   // log and audit actions without sending signals
+	Interval  Duration   `json:"interval"`  // how often processes are sampled
+	Allowlist []string   `json:"allowlist"` // process names rules never touch
+	Rules     []RuleSpec `json:"rules"`
+}
+
+// RuleSpec declares a single rule, e.g. "if a process named jest-worker
+// uses more than 90% CPU for 5 minutes, send SIGTERM then SIGKILL"
+type RuleSpec struct {
+	Name     string   `json:"name"`
+	Match    string   `json:"match"`     // regular expression matched against the process name
+	CPUAbove float64  `json:"cpu_above"` // CPU percentage that must be exceeded
+	For      Duration `json:"for"`       // how long the CPU must stay above the threshold
+	Signals  []string `json:"signals"`   // signals to send in order, e.g. ["SIGTERM", "SIGKILL"]
+	Grace    Duration `json:"grace"`     // wait between escalating signals
+}
+
+// AlertSpec declares a desktop notification raised while a metric
+// crosses a threshold, e.g. "temp > 90C" held for 30s
+type AlertSpec struct {
+	Name string   `json:"name"` // defaults to the condition
+	When string   `json:"when"` // condition such as "battery < 15%" or "load1 > 8"
+	For  Duration `json:"for"`  // how long the condition must hold before alerting
+}
+
+// Duration is a time.Duration that reads and writes as a string like "5m"
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalJSON parses a duration string such as "90s" or "5m"
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"5m\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// Default returns the configuration used when no file exists
+func Default() Config {
+	return Config{
+		Tray: Tray{
+			Smoothing: Smoothing{
+				Method:     "ema",
+				Alpha:      0.3,
+				Window:     5,
+				Dwell:      Duration{3 * time.Second},
+				DownMargin: 5,
+			},
+		},
+		Rules: Rules{
+			Interval: Duration{10 * time.Second},
+		},
+		Protect: Protect{
+			Mode: "confirm",
+		},
+		Disk: Disk{
+			WarnAbove: 90,
+		},
+	}
+}
+
+// Load reads the config file at path on top of the defaults.
+// A missing file is not an error.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// DefaultPath returns the default config file location,
+// e.g. ~/.config/shinobi/config.json
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "shinobi", "config.json")
+}
+
+// StateDir returns the directory for logs and other runtime state,
+// honoring $XDG_STATE_HOME and falling back to ~/.local/state/shinobi
+func StateDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "shinobi")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "shinobi")
+	}
+	return filepath.Join(home, ".local", "state", "shinobi")
+}
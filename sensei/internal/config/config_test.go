@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingFileUsesDefaults(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Rules.Interval.Duration != 10*time.Second {
+		t.Errorf("Expected default interval 10s, got %v", cfg.Rules.Interval)
+	}
+	if cfg.Disk.WarnAbove != 90 {
+		t.Errorf("Expected default disk warning at 90%%, got %v", cfg.Disk.WarnAbove)
+	}
+	if s := cfg.Tray.Smoothing; s.Method != "ema" || s.Dwell.Duration != 3*time.Second {
+		t.Errorf("Expected default EMA smoothing with a 3s dwell, got %+v", s)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+		"rules": {
+			"dry_run": true,
+			"allowlist": ["sshd"],
+			"rules": [
+				{"name": "jest", "match": "^jest", "cpu_above": 90, "for": "5m", "signals": ["SIGTERM", "SIGKILL"], "grace": "15s"}
+			]
+		}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !cfg.Rules.DryRun || len(cfg.Rules.Allowlist) != 1 {
+		t.Errorf("Unexpected rules section: %+v", cfg.Rules)
+	}
+	if cfg.Rules.Interval.Duration != 10*time.Second {
+		t.Errorf("Default interval should survive a partial file, got %v", cfg.Rules.Interval)
+	}
+	if len(cfg.Rules.Rules) != 1 {
+		t.Fatalf("Expected 1 rule, got %d", len(cfg.Rules.Rules))
+	}
+	r := cfg.Rules.Rules[0]
+	if r.For.Duration != 5*time.Minute || r.Grace.Duration != 15*time.Second || r.CPUAbove != 90 {
+		t.Errorf("Unexpected rule: %+v", r)
+	}
+}
+
+func TestLoadRejectsBadDuration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"rules": {"interval": 10}}`), 0o644)
+
+	if _, err := Load(path); err == nil {
+		t.Error("Expected an error for a numeric duration")
+	}
+}
@@ -0,0 +1,196 @@
+package control
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"system-shinobi/sensei/internal/supervisor"
+)
+
+func TestLockIsExclusive(t *testing.T) {
+	path := LockPath(t.TempDir())
+	first, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	if _, err := Acquire(path); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Expected ErrLocked for a second Acquire, got %v", err)
+	}
+	if pid, err := Owner(path); err != nil || pid != os.Getpid() {
+		t.Errorf("Owner = %d, %v; expected %d", pid, err, os.Getpid())
+	}
+
+	first.Release()
+	second, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Expected the lock to be free after Release, got %v", err)
+	}
+	second.Release()
+}
+
+func newTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	path := SocketPath(t.TempDir())
+	// A stale socket file from a crashed instance must not block Listen
+	os.WriteFile(path, nil, 0o600)
+	s, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	go s.Serve()
+	t.Cleanup(func() { s.Close() })
+	return s, path
+}
+
+func TestCallRoundTrip(t *testing.T) {
+	s, path := newTestServer(t)
+	started := time.Date(2024, 2, 13, 14, 0, 0, 0, time.UTC)
+	s.Handle(CmdStatus, func() (any, error) {
+		return Status{PID: 42, Started: started, Connected: true, CPUPercent: 37.5, Level: "Low"}, nil
+	})
+	s.Handle(CmdOpenDojo, func() (any, error) {
+		return nil, errors.New("no terminal emulator found")
+	})
+
+	var st Status
+	if err := Call(path, CmdStatus, &st); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if st.PID != 42 || !st.Started.Equal(started) || st.Level != "Low" {
+		t.Errorf("Unexpected status: %+v", st)
+	}
+
+	if err := Call(path, CmdOpenDojo, nil); err == nil || !strings.Contains(err.Error(), "no terminal") {
+		t.Errorf("Expected the handler's error, got %v", err)
+	}
+	if err := Call(path, "dance", nil); err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("Expected an unknown command error, got %v", err)
+	}
+}
+
+func TestCallNotRunning(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sensei.sock")
+	if err := Call(path, CmdStatus, nil); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning, got %v", err)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	s, path := newTestServer(t)
+	s.HandleStream(CmdReadings, func(send func(any) error, done <-chan struct{}) error {
+		for i := 1; i <= 3; i++ {
+			if err := send(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	s.HandleStream("broken", func(send func(any) error, done <-chan struct{}) error {
+		send(1)
+		return errors.New("fell behind")
+	})
+
+	sub, err := Subscribe(path, CmdReadings)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	defer sub.Close()
+	var got []int
+	for {
+		var n int
+		if err := sub.Next(&n); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		got = append(got, n)
+	}
+	if len(got) != 3 || got[0] != 1 || got[2] != 3 {
+		t.Errorf("Expected results 1, 2, 3, got %v", got)
+	}
+
+	broken, err := Subscribe(path, "broken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broken.Close()
+	var n int
+	if err := broken.Next(&n); err != nil || n != 1 {
+		t.Fatalf("Expected the first result, got %d, %v", n, err)
+	}
+	if err := broken.Next(&n); err == nil || !strings.Contains(err.Error(), "fell behind") {
+		t.Errorf("Expected the streamer's error, got %v", err)
+	}
+}
+
+func TestCloseEndsStreams(t *testing.T) {
+	s, path := newTestServer(t)
+	s.HandleStream(CmdReadings, func(send func(any) error, done <-chan struct{}) error {
+		<-done
+		return nil
+	})
+	sub, err := Subscribe(path, CmdReadings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	time.Sleep(50 * time.Millisecond) // let the stream start
+
+	closed := make(chan struct{})
+	go func() {
+		s.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close blocked on an open stream")
+	}
+	if err := sub.Next(nil); err != io.EOF {
+		t.Errorf("Expected io.EOF once the server closed, got %v", err)
+	}
+}
+
+func TestCloseRemovesSocket(t *testing.T) {
+	path := SocketPath(t.TempDir())
+	s, err := Listen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve()
+	s.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the socket file to be removed, got %v", err)
+	}
+}
+
+func TestStatusFormat(t *testing.T) {
+	started := time.Date(2024, 2, 13, 14, 0, 0, 0, time.UTC)
+	st := Status{PID: 42, Started: started, Connected: true, LastReading: started.Add(time.Hour), CPUPercent: 37.5, Level: "Low"}
+	out := st.Format(started.Add(time.Hour + 2*time.Second))
+	for _, want := range []string{"PID 42", "1h 0m", "2s ago", "37.5% [Low]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Status output missing %q:\n%s", want, out)
+		}
+	}
+	if out := (Status{PID: 42, Started: started}).Format(started); !strings.Contains(out, "disconnected") {
+		t.Errorf("Expected a disconnected probe, got:\n%s", out)
+	}
+}
+
+func TestStatusFormatManagedProbe(t *testing.T) {
+	started := time.Date(2024, 2, 13, 14, 0, 0, 0, time.UTC)
+	st := Status{PID: 42, Started: started, Probe: &supervisor.Health{Restarts: 3, LastError: "exit status 1"}}
+	out := st.Format(started.Add(time.Minute))
+	for _, want := range []string{"managed, restarting", "3 restart(s)", "last error: exit status 1", "disconnected"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Status output missing %q:\n%s", want, out)
+		}
+	}
+}
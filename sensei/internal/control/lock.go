@@ -0,0 +1,69 @@
+// Package control keeps sensei to a single instance and lets later
+// invocations talk to the running one over a local socket
+package control
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ErrLocked is returned by Acquire when another process holds the lock
+var ErrLocked = errors.New("already locked by another process")
+
+// LockPath returns the lock file sensei takes in dir
+func LockPath(dir string) string {
+	return filepath.Join(dir, "sensei.lock")
+}
+
+// Lock is an exclusive advisory lock on a file, released when the
+// process exits even if it crashes
+type Lock struct {
+	file *os.File
+}
+
+// Acquire takes the lock at path without blocking and records this
+// process's PID in it. It returns ErrLocked if another process holds it.
+func Acquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, ErrLocked
+		}
+		return nil, fmt.Errorf("locking %s: %w", path, err)
+	}
+
+	if err := file.Truncate(0); err == nil {
+		file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &Lock{file: file}, nil
+}
+
+// Release unlocks and closes the lock file. A nil *Lock is a no-op.
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	return l.file.Close()
+}
+
+// Owner returns the PID recorded in the lock file at path
+func Owner(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
@@ -0,0 +1,280 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Commands understood by a running sensei
+const (
+	CmdStatus   = "status"
+	CmdOpenDojo = "open-dojo"
+	CmdQuit     = "quit"
+	CmdStats    = "stats"    // []selfstat.Report for sensei and the probe
+	CmdReadings = "readings" // stream of record.Frame, one per reading or disconnect
+)
+
+// callTimeout bounds a whole request/response exchange
+const callTimeout = 5 * time.Second
+
+// SocketPath returns the control socket sensei listens on in dir
+func SocketPath(dir string) string {
+	return filepath.Join(dir, "sensei.sock")
+}
+
+// Request is one command, sent as a JSON line
+type Request struct {
+	Command string `json:"command"`
+}
+
+// Response answers a Request, as a JSON line
+type Response struct {
+	OK     bool            `json:"ok"`
+	Error  string          `json:"error,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+// Handler runs a command and returns a JSON-encodable result
+type Handler func() (any, error)
+
+// Streamer runs a streaming command, passing each JSON-encodable result
+// to send until send fails, the stream is over or done is closed when the
+// server closes. An error it returns is sent as the last response.
+type Streamer func(send func(any) error, done <-chan struct{}) error
+
+// Server answers commands on a unix socket
+type Server struct {
+	listener  net.Listener
+	path      string
+	conns     sync.WaitGroup
+	done      chan struct{} // closed by Close to end streams
+	closeOnce sync.Once
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	streams  map[string]Streamer
+}
+
+// Listen creates the control socket at path. The caller must hold the
+// instance Lock, so any socket file already there is stale and removed.
+func Listen(path string) (*Server, error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	os.Chmod(path, 0o600) // only the owner may control sensei
+	return &Server{
+		listener: listener,
+		path:     path,
+		done:     make(chan struct{}),
+		handlers: make(map[string]Handler),
+		streams:  make(map[string]Streamer),
+	}, nil
+}
+
+// Handle registers the handler for a command
+func (s *Server) Handle(command string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[command] = h
+}
+
+// HandleStream registers the streamer for a command. Its reply is a
+// Response line per result rather than a single one.
+func (s *Server) HandleStream(command string, h Streamer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streams[command] = h
+}
+
+// Serve accepts connections until Close is called
+func (s *Server) Serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				slog.Warn("Control socket", "err", err)
+			}
+			return
+		}
+		s.conns.Add(1)
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops listening, ends streams, waits for replies in flight (such
+// as the one to CmdQuit) and removes the socket file. A nil *Server is a
+// no-op.
+func (s *Server) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.closeOnce.Do(func() { close(s.done) })
+	err := s.listener.Close()
+	s.conns.Wait()
+	os.Remove(s.path)
+	return err
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer s.conns.Done()
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(callTimeout))
+
+	var req Request
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err == nil {
+		err = json.Unmarshal(line, &req)
+	}
+	if err == nil {
+		s.mu.Lock()
+		h, ok := s.streams[req.Command]
+		s.mu.Unlock()
+		if ok {
+			s.stream(conn, h)
+			return
+		}
+	}
+	writeResponse(conn, s.dispatch(req, err))
+}
+
+// stream answers a streaming command for as long as it runs. Each
+// response must be written within callTimeout, so a stuck client can't
+// hold the stream open.
+func (s *Server) stream(conn net.Conn, h Streamer) {
+	conn.SetReadDeadline(time.Time{})
+	send := func(result any) error {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		conn.SetWriteDeadline(time.Now().Add(callTimeout))
+		return writeResponse(conn, Response{OK: true, Result: data})
+	}
+	if err := h(send, s.done); err != nil {
+		conn.SetWriteDeadline(time.Now().Add(callTimeout))
+		writeResponse(conn, Response{Error: err.Error()})
+	}
+}
+
+func writeResponse(conn net.Conn, resp Response) error {
+	data, _ := json.Marshal(resp)
+	_, err := conn.Write(append(data, '\n'))
+	return err
+}
+
+func (s *Server) dispatch(req Request, readErr error) Response {
+	if readErr != nil {
+		return Response{Error: fmt.Sprintf("bad request: %v", readErr)}
+	}
+	s.mu.Lock()
+	h, ok := s.handlers[req.Command]
+	s.mu.Unlock()
+	if !ok {
+		return Response{Error: fmt.Sprintf("unknown command %q", req.Command)}
+	}
+
+	result, err := h()
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	resp := Response{OK: true}
+	if result != nil {
+		if resp.Result, err = json.Marshal(result); err != nil {
+			return Response{Error: err.Error()}
+		}
+	}
+	return resp
+}
+
+// ErrNotRunning is returned by Call when nothing listens on the socket
+var ErrNotRunning = errors.New("sensei is not running")
+
+// Call sends a command to the sensei listening at path and decodes its
+// result into result, which may be nil
+func Call(path, command string, result any) error {
+	conn, err := net.DialTimeout("unix", path, callTimeout)
+	if err != nil {
+		return ErrNotRunning
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(callTimeout))
+
+	data, _ := json.Marshal(Request{Command: command})
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("reading reply: %w", err)
+	}
+	return decodeReply(line, result)
+}
+
+// decodeReply decodes a Response line's result into result, which may be
+// nil, or returns its error
+func decodeReply(line []byte, result any) error {
+	var resp Response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return fmt.Errorf("bad reply: %w", err)
+	}
+	if !resp.OK {
+		return errors.New(resp.Error)
+	}
+	if result != nil && resp.Result != nil {
+		return json.Unmarshal(resp.Result, result)
+	}
+	return nil
+}
+
+// Subscription reads the results of a streaming command, see Subscribe
+type Subscription struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// Subscribe sends a streaming command to the sensei listening at path.
+// Its results are read with Next until Close.
+func Subscribe(path, command string) (*Subscription, error) {
+	conn, err := net.DialTimeout("unix", path, callTimeout)
+	if err != nil {
+		return nil, ErrNotRunning
+	}
+	conn.SetWriteDeadline(time.Now().Add(callTimeout))
+	data, _ := json.Marshal(Request{Command: command})
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Subscription{conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+// Next waits for the next result and decodes it into result. It returns
+// io.EOF once sensei ends the stream, and the stream's error if it failed.
+func (s *Subscription) Next(result any) error {
+	line, err := s.reader.ReadBytes('\n')
+	if errors.Is(err, io.EOF) && len(line) == 0 {
+		return io.EOF
+	}
+	if err != nil {
+		return fmt.Errorf("reading reply: %w", err)
+	}
+	return decodeReply(line, result)
+}
+
+// Close ends the subscription, making a blocked Next return
+func (s *Subscription) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,46 @@
+package control
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"system-shinobi/sensei/internal/supervisor"
+	"system-shinobi/sensei/internal/sysinfo"
+)
+
+// Status describes a running sensei, the result of CmdStatus
+type Status struct {
+	PID         int       `json:"pid"`
+	Started     time.Time `json:"started"`
+	Connected   bool      `json:"connected"` // the probe is sending readings
+	LastReading time.Time `json:"last_reading,omitempty"`
+	CPUPercent  float64   `json:"cpu_percent"`
+	Level       string    `json:"level"`
+
+	Probe *supervisor.Health `json:"probe,omitempty"` // set when sensei manages the probe
+}
+
+// Format renders the status for the terminal
+func (s Status) Format(now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "sensei running (PID %d) for %s\n", s.PID, sysinfo.FormatUptime(now.Sub(s.Started)))
+	if p := s.Probe; p != nil {
+		state := "restarting"
+		if p.Running {
+			state = fmt.Sprintf("up %s (PID %d)", sysinfo.FormatUptime(now.Sub(p.Started)), p.PID)
+		}
+		fmt.Fprintf(&b, "probe:  managed, %s, %d restart(s)", state, p.Restarts)
+		if p.LastError != "" {
+			fmt.Fprintf(&b, ", last error: %s", p.LastError)
+		}
+		b.WriteString("\n")
+	}
+	if !s.Connected {
+		b.WriteString("probe:  disconnected\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "probe:  connected, last reading %s ago\n", now.Sub(s.LastReading).Round(time.Second))
+	fmt.Fprintf(&b, "cpu:    %.1f%% [%s]\n", s.CPUPercent, s.Level)
+	return b.String()
+}
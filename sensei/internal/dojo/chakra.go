@@ -0,0 +1,88 @@
+package dojo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"system-shinobi/sensei/internal/icon"
+	"system-shinobi/sensei/internal/metrics"
+	"system-shinobi/sensei/internal/sysinfo"
+)
+
+// infoRow is a label/value line styled like the !clone scroll
+type infoRow struct {
+	label string
+	value string
+	style lipgloss.Style
+}
+
+// renderChakra renders the !chakra memory and swap scroll
+func (m Model) renderChakra() string {
+	var b strings.Builder
+
+	b.WriteString(scrollTitleStyle.Render("!CHAKRA - Memory Flow"))
+	b.WriteString("\n\n")
+
+	if m.memory == nil {
+		b.WriteString("  Reading memory...")
+		return b.String()
+	}
+	mem := m.memory
+
+	used := mem.UsedPercent()
+	rows := []infoRow{
+		{"Used", fmt.Sprintf("%s / %s (%.0f%%)", sysinfo.FormatMemory(mem.Used), sysinfo.FormatMemory(mem.Total), used), infoValueStyle},
+		{"", meter(used, 30), m.cpuColor(used)},
+		{"Available", sysinfo.FormatMemory(mem.Available), infoValueStyle},
+		{"Cached", sysinfo.FormatMemory(mem.Cached), infoValueStyle},
+		{"Swap", formatSwap(mem), infoValueStyle},
+	}
+	if mem.SwapTotal > 0 {
+		rows = append(rows, infoRow{"", meter(mem.SwapPercent(), 30), m.cpuColor(mem.SwapPercent())})
+	}
+	rows = append(rows, infoRow{"History", sparkline(m.memHistory, 100), m.cpuColor(used)})
+
+	for _, r := range rows {
+		b.WriteString(fmt.Sprintf("  %s %s\n", infoLabelStyle.Render(r.label), r.style.Render(r.value)))
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.renderPressure("Memory pressure", mem.Pressure))
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  Auto-refreshes every 2s  [r] Force refresh"))
+
+	return b.String()
+}
+
+// renderPressure renders a PSI table, or a note when PSI is unavailable
+func (m Model) renderPressure(title string, p *metrics.Pressure) string {
+	if p == nil {
+		return helpStyle.Render(fmt.Sprintf("  %s: pressure stall info needs Linux with PSI enabled", title)) + "\n"
+	}
+
+	var b strings.Builder
+	header := fmt.Sprintf("  %-16s %-8s %-8s %s", title, "avg10", "avg60", "avg300")
+	b.WriteString(tableHeaderStyle.Render(header))
+	b.WriteString("\n")
+	for _, row := range []struct {
+		name               string
+		avg10, avg60, a300 float64
+	}{
+		{"some", p.SomeAvg10, p.SomeAvg60, p.SomeAvg300},
+		{"full", p.FullAvg10, p.FullAvg60, p.FullAvg300},
+	} {
+		line := fmt.Sprintf("  %-16s %-8.2f %-8.2f %.2f", row.name, row.avg10, row.avg60, row.a300)
+		b.WriteString(m.cpuColor(icon.PressurePercent(row.avg10)).Render(line))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func formatSwap(mem *metrics.Memory) string {
+	if mem.SwapTotal == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%s / %s (%.0f%%)", sysinfo.FormatMemory(mem.SwapUsed), sysinfo.FormatMemory(mem.SwapTotal), mem.SwapPercent())
+}
@@ -0,0 +1,313 @@
+package dojo
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"system-shinobi/sensei/internal/audit"
+	"system-shinobi/sensei/internal/cgroup"
+	"system-shinobi/sensei/internal/process"
+	"system-shinobi/sensei/internal/protect"
+	"system-shinobi/sensei/internal/selfstat"
+	"system-shinobi/sensei/internal/sysinfo"
+	"system-shinobi/sensei/internal/systemd"
+)
+
+// unitAction is a systemd unit operation waiting on confirmation
+type unitAction string
+
+const (
+	unitNone    unitAction = ""
+	unitStop    unitAction = "stop"
+	unitRestart unitAction = "restart"
+	unitMask    unitAction = "mask"
+)
+
+// unitRow is a service with the processes and usage of its cgroup
+type unitRow struct {
+	systemd.Unit
+	cgroupPath string
+	procs      []process.Process
+	stats      *cgroup.Stats
+}
+
+// unitsMsg carries a fresh unit listing
+type unitsMsg struct {
+	units []unitRow
+	at    time.Time
+}
+
+// unitResultMsg reports the outcome of a unit action
+type unitResultMsg struct {
+	name   string
+	action unitAction
+	err    error
+}
+
+// fetchUnits lists services over D-Bus and joins them with their
+// cgroups for processes and resource usage
+func fetchUnits(mgr *systemd.Manager) tea.Cmd {
+	return func() tea.Msg {
+		if mgr == nil {
+			// renderClan already explains why
+			return nil
+		}
+		defer selfstat.Time("units")()
+
+		units, err := mgr.ListServices()
+		if err != nil {
+			return errMsg(err.Error())
+		}
+		// Without cgroups the units are still listed, just without usage
+		paths, _ := cgroup.Units()
+
+		procs, _ := process.ListAll()
+		byPID := make(map[int]process.Process, len(procs))
+		for _, p := range procs {
+			byPID[p.PID] = p
+		}
+
+		rows := make([]unitRow, 0, len(units))
+		for _, u := range units {
+			row := unitRow{Unit: u, cgroupPath: paths[u.Name]}
+			if row.cgroupPath != "" {
+				if st, err := cgroup.ReadStats(row.cgroupPath); err == nil {
+					row.stats = &st
+				}
+				pids, _ := cgroup.PIDs(row.cgroupPath)
+				for _, pid := range pids {
+					p, ok := byPID[pid]
+					if !ok {
+						p = process.Process{PID: pid, Name: "?"}
+					}
+					row.procs = append(row.procs, p)
+				}
+			}
+			rows = append(rows, row)
+		}
+		return unitsMsg{units: rows, at: time.Now()}
+	}
+}
+
+// runUnitAction stops, restarts or masks a unit and audits it
+func runUnitAction(mgr *systemd.Manager, name string, action unitAction, auditLog *audit.Log) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		switch action {
+		case unitStop:
+			err = mgr.Stop(name)
+		case unitRestart:
+			err = mgr.Restart(name)
+		case unitMask:
+			err = mgr.Mask(name)
+		}
+		entry := audit.Entry{
+			Time:      time.Now(),
+			Name:      name,
+			Unit:      name,
+			Action:    string(action),
+			Outcome:   audit.Outcome(err),
+			Initiator: audit.InitiatorInteractive,
+		}
+		if err := auditLog.Record(entry); err != nil {
+			log.Printf("Failed to write audit entry: %v", err)
+		}
+		return unitResultMsg{name: name, action: action, err: err}
+	}
+}
+
+// renderClan renders the !clan systemd service scroll
+func (m Model) renderClan() string {
+	var b strings.Builder
+
+	b.WriteString(scrollTitleStyle.Render("!CLAN - systemd Services"))
+	b.WriteString("\n\n")
+
+	if m.unitResult != "" {
+		b.WriteString(m.unitResult)
+		b.WriteString("\n\n")
+	}
+
+	if m.unitMgr == nil {
+		b.WriteString(helpStyle.Render("  systemd is not available: " + m.unitMgrErr))
+		return b.String()
+	}
+	if m.units == nil {
+		b.WriteString("  Gathering the clan...")
+		return b.String()
+	}
+
+	header := fmt.Sprintf("  %-32s %-9s %-9s %-6s %-7s %s", "Unit", "Active", "Sub", "Procs", "CPU%", "Memory")
+	b.WriteString(tableHeaderStyle.Render(header))
+	b.WriteString("\n")
+
+	// Keep the selection in view, leaving room for its process list
+	visible := m.height - 22
+	if visible < 5 {
+		visible = 5
+	}
+	start := 0
+	if m.unitIdx >= visible {
+		start = m.unitIdx - visible + 1
+	}
+
+	for i := start; i < len(m.units) && i < start+visible; i++ {
+		u := m.units[i]
+		cpu, mem := "-", "-"
+		if v, ok := m.unitCPU[u.cgroupPath]; ok {
+			cpu = fmt.Sprintf("%.1f", v)
+		}
+		if u.stats != nil {
+			mem = sysinfo.FormatMemory(u.stats.MemoryCurrent)
+		}
+		row := fmt.Sprintf("  %-32s %-9s %-9s %-6d %-7s %s",
+			truncate(u.Name, 32), u.ActiveState, truncate(u.SubState, 9), len(u.procs), cpu, mem)
+
+		switch {
+		case u.Name == m.confirmName && m.typing:
+			row = confirmStyle.Render(fmt.Sprintf(" PROTECTED: type %q to %s it: %s_ ", u.Name, m.confirmUnit, m.typed))
+			row += "\n" + protectedStyle.Render("    Runs a protected process: "+m.unitProtection(u).Reason)
+		case u.Name == m.confirmName && m.confirmUnit != unitNone:
+			row = confirmStyle.Render(fmt.Sprintf(" %s %s? [Enter] Yes  [Esc] No ",
+				strings.ToUpper(string(m.confirmUnit)), u.Name))
+		case i == m.unitIdx:
+			row = selectedRowStyle.Render(row)
+		case u.ActiveState == "failed":
+			row = errorStyle.Render(row)
+		case u.ActiveState != "active":
+			row = helpStyle.Render(row)
+		default:
+			row = infoValueStyle.Render(row)
+		}
+		b.WriteString(row)
+		b.WriteString("\n")
+	}
+
+	if m.unitIdx < len(m.units) {
+		b.WriteString("\n")
+		b.WriteString(m.renderUnitDetail(m.units[m.unitIdx]))
+	}
+
+	b.WriteString("\n")
+	if m.typing {
+		b.WriteString(helpStyle.Render("  Type the unit name exactly  [Enter] Confirm  [Esc] Cancel"))
+	} else {
+		b.WriteString(helpStyle.Render("  [up/down] Navigate  [s] Stop  [R] Restart  [m] Mask  [r] Refresh"))
+	}
+
+	return b.String()
+}
+
+// renderUnitDetail shows the selected unit's description, pressure and processes
+func (m Model) renderUnitDetail(u unitRow) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("  %s %s\n", infoLabelStyle.Render("Description"), infoValueStyle.Render(u.Description)))
+	if u.stats != nil {
+		b.WriteString(fmt.Sprintf("  %s %s\n", infoLabelStyle.Render("Pressure"), infoValueStyle.Render(fmt.Sprintf("cpu %s  mem %s  io %s",
+			pressureAvg10(u.stats.CPUPressure), pressureAvg10(u.stats.MemoryPressure), pressureAvg10(u.stats.IOPressure)))))
+	}
+	for i, p := range u.procs {
+		if i == 5 {
+			b.WriteString(helpStyle.Render(fmt.Sprintf("    ... and %d more", len(u.procs)-5)))
+			b.WriteString("\n")
+			break
+		}
+		b.WriteString(m.cpuColor(p.CPU).Render(fmt.Sprintf("    %-7d %-7.1f %s", p.PID, p.CPU, truncate(p.Name, 30))))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// unitProtection returns the strictest protect verdict among the
+// unit's processes, since stopping the unit signals all of them
+func (m Model) unitProtection(u unitRow) protect.Decision {
+	strictest := protect.Decision{Verdict: protect.Allow}
+	for _, p := range u.procs {
+		if d := m.policy.Check(p); d.Verdict > strictest.Verdict {
+			strictest = d
+		}
+	}
+	return strictest
+}
+
+func (m Model) handleClanKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirmUnit != unitNone {
+		switch msg.String() {
+		case "enter":
+			return m.finishUnit(m.confirmName)
+		case "esc":
+			m.confirmUnit, m.confirmName = unitNone, ""
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.unitIdx > 0 {
+			m.unitIdx--
+			m.unitResult = ""
+		}
+	case "down", "j":
+		if m.unitIdx < len(m.units)-1 {
+			m.unitIdx++
+			m.unitResult = ""
+		}
+	case "s", "R", "m":
+		if m.unitMgr == nil || m.unitIdx >= len(m.units) {
+			break
+		}
+		if m.replay != nil {
+			m.unitResult = errorStyle.Render("  Replaying a recording, unit actions are disabled.")
+			break
+		}
+		u := m.units[m.unitIdx]
+		d := m.unitProtection(u)
+		if d.Verdict == protect.Refuse {
+			m.unitResult = errorStyle.Render(fmt.Sprintf("  Refused: %s runs a protected process, %s.", u.Name, d.Reason))
+			break
+		}
+		m.confirmUnit = map[string]unitAction{"s": unitStop, "R": unitRestart, "m": unitMask}[msg.String()]
+		m.confirmName = u.Name
+		if d.Verdict == protect.Confirm {
+			m = m.startTyping(actionUnit)
+		}
+	}
+	return m, nil
+}
+
+// finishTypedUnit runs the pending unit action once the typed
+// confirmation is in, if it matches the unit name exactly
+func (m Model) finishTypedUnit() (tea.Model, tea.Cmd) {
+	if m.typed != m.confirmName {
+		m.confirmUnit, m.confirmName = unitNone, ""
+		m.unitResult = errorStyle.Render("  Confirmation did not match, nothing was done.")
+		return m, nil
+	}
+	return m.finishUnit(m.typed)
+}
+
+// finishUnit runs the confirmed action on the named unit. The listing
+// refreshes while the prompt is open, so the unit is looked up by name
+// and nothing is done if it has gone.
+func (m Model) finishUnit(name string) (tea.Model, tea.Cmd) {
+	action := m.confirmUnit
+	m.confirmUnit, m.confirmName = unitNone, ""
+	if m.unitIndex(name) < 0 {
+		m.unitResult = errorStyle.Render(fmt.Sprintf("  %s is no longer listed, nothing was done.", name))
+		return m, nil
+	}
+	return m, runUnitAction(m.unitMgr, name, action, m.auditLog)
+}
+
+// unitIndex returns the position of the named unit in the listing, or -1
+func (m Model) unitIndex(name string) int {
+	for i, u := range m.units {
+		if u.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"system-shinobi/sensei/internal/metrics"
 	"system-shinobi/sensei/internal/sysinfo"
 )
 
@@ -26,6 +27,25 @@ func (m Model) renderClone() string {
 			sysinfo.FormatMemory(info.MemUsed),
 			sysinfo.FormatMemory(info.MemTotal))},
 		{"CPU Usage", formatCPUStatus(m.cpuPercent)},
+		{"Load Avg", fmt.Sprintf("%.2f %.2f %.2f (1/5/15 min)", info.Load.Load1, info.Load.Load5, info.Load.Load15)},
+	}
+	if p := info.Load.CPUPressure; p != nil {
+		rows = append(rows, struct{ label, value string }{"CPU Pressure", formatPressure(p)})
+	}
+	if p := info.Load.IOPressure; p != nil {
+		rows = append(rows, struct{ label, value string }{"IO Pressure", formatPressure(p)})
+	}
+	if b := info.Power.Battery; b != nil {
+		rows = append(rows, struct{ label, value string }{"Battery", formatBattery(b)})
+	}
+	if info.Power.Battery != nil || info.Power.OnAC {
+		rows = append(rows, struct{ label, value string }{"Power Source", formatPowerSource(info.Power.OnAC)})
+	}
+	if t := info.Power.CPUTempC; t > 0 {
+		rows = append(rows, struct{ label, value string }{"CPU Temp", fmt.Sprintf("%.0f°C", t)})
+	}
+	if info.Power.CPUFreqMHz > 0 {
+		rows = append(rows, struct{ label, value string }{"CPU Freq", formatFreq(info.Power)})
 	}
 
 	for _, r := range rows {
@@ -46,3 +66,36 @@ func formatCPUStatus(cpu float64) string {
 	}
 	return fmt.Sprintf("%.1f%%", cpu)
 }
+
+// formatPressure summarizes PSI as "some 1.2% / full 0.3% (avg10)"
+func formatPressure(p *metrics.Pressure) string {
+	return fmt.Sprintf("some %.1f%% / full %.1f%% (avg10)", p.SomeAvg10, p.FullAvg10)
+}
+
+// formatBattery summarizes the battery as "83% charging (18.0 W)"
+func formatBattery(b *metrics.Battery) string {
+	s := fmt.Sprintf("%.0f%% %s", b.Percent, b.State)
+	if b.Watts > 0 {
+		s += fmt.Sprintf(" (%.1f W)", b.Watts)
+	}
+	return s
+}
+
+func formatPowerSource(onAC bool) string {
+	if onAC {
+		return "AC adapter"
+	}
+	return "battery"
+}
+
+// formatFreq shows the current and maximum CPU frequency, flagging throttling
+func formatFreq(p metrics.Power) string {
+	s := fmt.Sprintf("%.2f GHz", p.CPUFreqMHz/1000)
+	if p.MaxFreqMHz > 0 {
+		s += fmt.Sprintf(" / %.2f GHz max", p.MaxFreqMHz/1000)
+	}
+	if p.Throttled() {
+		s += " (throttled)"
+	}
+	return s
+}
@@ -0,0 +1,60 @@
+package dojo
+
+import (
+	"fmt"
+	"strings"
+
+	"system-shinobi/sensei/internal/metrics"
+	"system-shinobi/sensei/internal/sysinfo"
+)
+
+// kunaiSparkWidth is how many samples of history each interface row shows
+const kunaiSparkWidth = 20
+
+// renderKunai renders the !kunai per-interface network traffic scroll
+func (m Model) renderKunai() string {
+	var b strings.Builder
+
+	b.WriteString(scrollTitleStyle.Render("!KUNAI - Network Traffic"))
+	b.WriteString("\n\n")
+
+	if m.netRates == nil {
+		b.WriteString("  Measuring traffic...")
+		return b.String()
+	}
+
+	header := fmt.Sprintf("  %-12s %-11s %-11s %-9s %-9s %-9s %s", "Interface", "RX", "TX", "RX pkt/s", "TX pkt/s", "Errors", "Traffic")
+	b.WriteString(tableHeaderStyle.Render(header))
+	b.WriteString("\n")
+
+	for _, r := range m.netRates {
+		history := m.netHistory[r.Name]
+		if len(history) > kunaiSparkWidth {
+			history = history[len(history)-kunaiSparkWidth:]
+		}
+		row := fmt.Sprintf("  %-12s %-11s %-11s %-9.0f %-9.0f %-9s %s",
+			truncate(r.Name, 12), sysinfo.FormatRate(r.RxBytesPerSec), sysinfo.FormatRate(r.TxBytesPerSec),
+			r.RxPacketsPerSec, r.TxPacketsPerSec, fmt.Sprintf("%d/%d", r.RxErrors, r.TxErrors), sparkline(history, 0))
+
+		switch {
+		case r.RxErrors+r.TxErrors > 0:
+			row = errorStyle.Render(row)
+		case metrics.IsLoopback(r.Name):
+			row = helpStyle.Render(row)
+		default:
+			row = infoValueStyle.Render(row)
+		}
+		b.WriteString(row)
+		b.WriteString("\n")
+	}
+
+	totals := metrics.SumNetRates(m.netRates)
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("  %s %s\n", infoLabelStyle.Render("Total RX"), infoValueStyle.Render(sysinfo.FormatRate(totals.RxBytesPerSec))))
+	b.WriteString(fmt.Sprintf("  %s %s\n", infoLabelStyle.Render("Total TX"), infoValueStyle.Render(sysinfo.FormatRate(totals.TxBytesPerSec))))
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  Errors are cumulative (rx/tx), totals exclude loopback  [r] Force refresh"))
+
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package dojo
+
+import (
+	"fmt"
+	"strings"
+
+	"system-shinobi/sensei/internal/metrics"
+	"system-shinobi/sensei/internal/sysinfo"
+)
+
+// renderKura renders the !kura disk I/O and filesystem scroll
+func (m Model) renderKura() string {
+	var b strings.Builder
+
+	b.WriteString(scrollTitleStyle.Render("!KURA - Disks & Filesystems"))
+	b.WriteString("\n\n")
+
+	// Mounts past the fill threshold go first so they can't be missed
+	for _, fs := range m.filesystems {
+		if m.overFilled(fs) {
+			b.WriteString(confirmStyle.Render(fmt.Sprintf("⚠ %s is %.0f%% full (%.0f%% of inodes), over the %.0f%% limit",
+				fs.Mount, fs.UsedPercent(), fs.InodePercent(), m.diskWarn)))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(m.renderDiskIO())
+	b.WriteString("\n")
+	b.WriteString(m.renderFilesystems())
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  Auto-refreshes every 2s  [r] Force refresh"))
+
+	return b.String()
+}
+
+func (m Model) renderDiskIO() string {
+	switch {
+	case m.diskErr != "":
+		return helpStyle.Render("  Disk I/O: "+m.diskErr) + "\n"
+	case m.diskRates == nil:
+		return "  Measuring disk I/O...\n"
+	}
+
+	var b strings.Builder
+	header := fmt.Sprintf("  %-12s %-11s %-11s %-9s %s", "Device", "Read", "Write", "Read/s", "Write/s")
+	b.WriteString(tableHeaderStyle.Render(header))
+	b.WriteString("\n")
+	for _, r := range m.diskRates {
+		row := fmt.Sprintf("  %-12s %-11s %-11s %-9.0f %.0f",
+			truncate(r.Name, 12), sysinfo.FormatRate(r.ReadBytesPerSec), sysinfo.FormatRate(r.WriteBytesPerSec),
+			r.ReadIOPS, r.WriteIOPS)
+		b.WriteString(infoValueStyle.Render(row))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (m Model) renderFilesystems() string {
+	if m.filesystems == nil {
+		return "  Reading filesystems...\n"
+	}
+
+	var b strings.Builder
+	header := fmt.Sprintf("  %-20s %-8s %-9s %-9s %-22s %s", "Mount", "Type", "Size", "Free", "Used", "Inodes")
+	b.WriteString(tableHeaderStyle.Render(header))
+	b.WriteString("\n")
+	for _, fs := range m.filesystems {
+		used := fs.UsedPercent()
+		row := fmt.Sprintf("  %-20s %-8s %-9s %-9s %s %3.0f%% %5.0f%%",
+			truncate(fs.Mount, 20), truncate(fs.Type, 8), sysinfo.FormatMemory(fs.Total),
+			sysinfo.FormatMemory(fs.Available), meter(used, 15), used, fs.InodePercent())
+		if m.overFilled(fs) {
+			row = errorStyle.Render(row)
+		} else {
+			row = m.cpuColor(used).Render(row)
+		}
+		b.WriteString(row)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// overFilled reports whether fs has crossed the configured fill
+// threshold, by space or by inodes
+func (m Model) overFilled(fs metrics.Filesystem) bool {
+	return m.diskWarn > 0 && (fs.UsedPercent() >= m.diskWarn || fs.InodePercent() >= m.diskWarn)
+}
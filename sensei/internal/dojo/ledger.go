@@ -0,0 +1,113 @@
+package dojo
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"system-shinobi/sensei/internal/audit"
+	"system-shinobi/sensei/internal/process"
+)
+
+// recordSignal writes an interactive signal action to the audit log
+func recordSignal(auditLog *audit.Log, p process.Process, cmdline string, sig syscall.Signal, err error) {
+	entry := audit.Entry{
+		Time:      time.Now(),
+		PID:       p.PID,
+		Name:      p.Name,
+		Command:   cmdline,
+		Signal:    process.SignalName(sig),
+		Outcome:   audit.Outcome(err),
+		Initiator: audit.InitiatorInteractive,
+	}
+	if err := auditLog.Record(entry); err != nil {
+		log.Printf("Failed to write audit entry: %v", err)
+	}
+}
+
+// renderLedger renders the !ledger audit log browser
+func (m Model) renderLedger() string {
+	var b strings.Builder
+
+	b.WriteString(scrollTitleStyle.Render("!LEDGER - Action Audit Log"))
+	b.WriteString("\n\n")
+
+	if len(m.ledger) == 0 {
+		b.WriteString("  No actions recorded yet.")
+		return b.String()
+	}
+
+	// Table header
+	header := fmt.Sprintf("  %-19s %-7s %-16s %-8s %-12s %s", "Time", "PID", "Name", "Action", "Initiator", "Outcome")
+	b.WriteString(tableHeaderStyle.Render(header))
+	b.WriteString("\n")
+
+	// Keep the selection in view, leaving room for the detail lines
+	visible := m.height - 14
+	if visible < 5 {
+		visible = 5
+	}
+	start := 0
+	if m.ledgerIdx >= visible {
+		start = m.ledgerIdx - visible + 1
+	}
+
+	for i := start; i < len(m.ledger) && i < start+visible; i++ {
+		e := m.ledger[i]
+		pid, action := fmt.Sprint(e.PID), e.Signal
+		if e.IsUnitAction() {
+			pid, action = "-", strings.ToUpper(e.Action)
+		}
+		row := fmt.Sprintf("  %-19s %-7s %-16s %-8s %-12s %s",
+			e.Time.Local().Format("2006-01-02 15:04:05"), pid, truncate(e.Name, 16),
+			action, truncate(e.Initiator, 12), truncate(e.Outcome, 24))
+
+		switch {
+		case i == m.ledgerIdx:
+			row = selectedRowStyle.Render(row)
+		case e.Outcome != audit.OutcomeOK && e.Outcome != audit.OutcomeDryRun:
+			row = errorStyle.Render(row)
+		default:
+			row = infoValueStyle.Render(row)
+		}
+		b.WriteString(row)
+		b.WriteString("\n")
+	}
+
+	// Details of the selected entry
+	sel := m.ledger[m.ledgerIdx]
+	b.WriteString("\n")
+	if sel.IsUnitAction() {
+		b.WriteString(fmt.Sprintf("  %s %s\n", infoLabelStyle.Render("Unit"), infoValueStyle.Render(sel.Unit)))
+	} else {
+		cmdline := sel.Command
+		if cmdline == "" {
+			cmdline = "(unknown)"
+		}
+		b.WriteString(fmt.Sprintf("  %s %s\n", infoLabelStyle.Render("Command"), infoValueStyle.Render(truncate(cmdline, 100))))
+	}
+	b.WriteString(fmt.Sprintf("  %s %s\n", infoLabelStyle.Render("Initiator"), infoValueStyle.Render(sel.Initiator)))
+	b.WriteString(fmt.Sprintf("  %s %s\n", infoLabelStyle.Render("Outcome"), infoValueStyle.Render(sel.Outcome)))
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(fmt.Sprintf("  [up/down] Browse  [r] Reload  (%s)", m.auditLog.Path())))
+
+	return b.String()
+}
+
+func (m Model) handleLedgerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.ledgerIdx > 0 {
+			m.ledgerIdx--
+		}
+	case "down", "j":
+		if m.ledgerIdx < len(m.ledger)-1 {
+			m.ledgerIdx++
+		}
+	}
+	return m, nil
+}
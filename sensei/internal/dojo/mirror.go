@@ -0,0 +1,100 @@
+package dojo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"system-shinobi/sensei/internal/control"
+	"system-shinobi/sensei/internal/selfstat"
+	"system-shinobi/sensei/internal/sysinfo"
+)
+
+// mirrorMsg carries the dojo's own report and those sensei sent back
+type mirrorMsg struct {
+	reports []selfstat.Report
+	err     error // why sensei's reports are missing, if they are
+}
+
+// fetchMirror snapshots the dojo and asks sensei over its control socket
+// for its own report and the probe's
+func fetchMirror(controlPath string) tea.Cmd {
+	return func() tea.Msg {
+		msg := mirrorMsg{reports: []selfstat.Report{selfstat.Snapshot("dojo")}}
+		var remote []selfstat.Report
+		if err := control.Call(controlPath, control.CmdStats, &remote); err != nil {
+			msg.err = err
+			return msg
+		}
+		msg.reports = append(msg.reports, remote...)
+		return msg
+	}
+}
+
+// renderMirror renders the !mirror scroll: what shinobi itself costs
+func (m Model) renderMirror() string {
+	var b strings.Builder
+
+	b.WriteString(scrollTitleStyle.Render("!MIRROR - Shinobi Overhead"))
+	b.WriteString("\n\n")
+
+	if len(m.mirror) == 0 {
+		b.WriteString("  Looking in the mirror...")
+		return b.String()
+	}
+
+	header := fmt.Sprintf("  %-8s %-8s %-7s %-10s %-10s %s", "Process", "PID", "CPU%", "CPU time", "RSS", "Goroutines")
+	b.WriteString(tableHeaderStyle.Render(header))
+	b.WriteString("\n")
+	for _, r := range m.mirror {
+		var prev *selfstat.Report
+		if p, ok := m.mirrorPrev[r.Process]; ok {
+			prev = &p
+		}
+		cpu := r.CPUPercent(prev)
+		row := fmt.Sprintf("  %-8s %-8d %-7.1f %-10s %-10s %d",
+			r.Process, r.PID, cpu, r.CPUTime.Round(10*time.Millisecond), sysinfo.FormatMemory(r.RSS), r.Goroutines)
+		b.WriteString(m.cpuColor(cpu).Render(row))
+		b.WriteString("\n")
+	}
+	switch {
+	case errors.Is(m.mirrorErr, control.ErrNotRunning):
+		b.WriteString(helpStyle.Render("  sensei not running, showing the dojo only"))
+		b.WriteString("\n")
+	case m.mirrorErr != nil:
+		b.WriteString(errorStyle.Render(fmt.Sprintf("  Asking sensei failed: %v", m.mirrorErr)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	header = fmt.Sprintf("  %-8s %-14s %-8s %-10s %-10s %s", "Process", "Collection", "Count", "Last", "Mean", "Max")
+	b.WriteString(tableHeaderStyle.Render(header))
+	b.WriteString("\n")
+	for _, r := range m.mirror {
+		for _, c := range r.Collections {
+			row := fmt.Sprintf("  %-8s %-14s %-8d %-10s %-10s %s",
+				r.Process, truncate(c.Name, 14), c.Count, formatTiming(c.Last), formatTiming(c.Mean()), formatTiming(c.Max))
+			b.WriteString(infoValueStyle.Render(row))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  Auto-refreshes every 2s  [r] Force refresh  CPU% is of one core"))
+
+	return b.String()
+}
+
+// formatTiming rounds a collection duration to a readable precision
+func formatTiming(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
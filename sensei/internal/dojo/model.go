@@ -1,11 +1,24 @@
 package dojo
 
 import (
+	"log/slog"
+	"os"
+	"syscall"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"system-shinobi/sensei/internal/audit"
+	"system-shinobi/sensei/internal/cgroup"
+	"system-shinobi/sensei/internal/config"
+	"system-shinobi/sensei/internal/icon"
+	"system-shinobi/sensei/internal/logging"
+	"system-shinobi/sensei/internal/metrics"
 	"system-shinobi/sensei/internal/process"
+	"system-shinobi/sensei/internal/protect"
+	"system-shinobi/sensei/internal/record"
+	"system-shinobi/sensei/internal/selfstat"
 	"system-shinobi/sensei/internal/sysinfo"
+	"system-shinobi/sensei/internal/systemd"
 )
 
 // ScrollType identifies which scroll (tab) is active
@@ -15,12 +28,51 @@ const (
 	ScrollShuriken ScrollType = iota // !shuriken - process killer
 	ScrollShadow                     // !shadow  - process monitor
 	ScrollClone                      // !clone   - system info
+	ScrollLedger                     // !ledger  - signal audit log
+	ScrollChakra                     // !chakra  - memory and swap
+	ScrollKunai                      // !kunai   - network traffic
+	ScrollKura                       // !kura    - disks and filesystems
+	ScrollClan                       // !clan    - systemd services
+	ScrollScribe                     // !scribe  - sensei log
+	ScrollMirror                     // !mirror  - shinobi's own overhead
+
+	scrollCount = 10
 )
 
+// scrollKeys maps the number keys to the scroll each jumps to
+var scrollKeys = map[string]ScrollType{
+	"1": ScrollShuriken,
+	"2": ScrollShadow,
+	"3": ScrollClone,
+	"4": ScrollLedger,
+	"5": ScrollChakra,
+	"6": ScrollKunai,
+	"7": ScrollKura,
+	"8": ScrollClan,
+	"9": ScrollScribe,
+	"0": ScrollMirror,
+}
+
+// pendingAction is a signal action waiting on a typed confirmation
+type pendingAction int
+
+const (
+	actionKill pendingAction = iota
+	actionFreeze
+	actionUnit // confirmUnit on the selected !clan unit
+)
+
+// ledgerSize is how many audit entries the !ledger scroll loads
+const ledgerSize = 200
+
+// scribeSize is how many log entries the !scribe scroll loads
+const scribeSize = 500
+
 // Model is the top-level BubbleTea model for the Dojo TUI
 type Model struct {
 	currentScroll ScrollType
 	width, height int
+	classifier    *icon.Classifier // load levels shared with the tray icon
 
 	// !shuriken state
 	processes   []process.Process
@@ -28,12 +80,86 @@ type Model struct {
 	confirmKill bool
 	killResult  string
 
+	// freeze state (shared across model copies)
+	freezer       *process.Freezer
+	confirmFreeze bool
+	freezeChoice  int // index into freezeDurations
+
+	// protected-process state
+	policy  *protect.Policy
+	typing  bool          // waiting for a typed confirmation
+	typed   string        // what has been typed so far
+	pending pendingAction // what the typed confirmation unlocks
+
 	// !shadow state
-	shadowProcs []process.Process
+	shadowProcs   []process.Process // every process, unsorted
+	shadowSort    shadowSortKey
+	shadowIO      bool // show the I/O and socket columns
+	shadowIOPrev  map[int]process.IOCounters
+	shadowIOAt    time.Time
+	shadowRates   map[int]process.IORate
+	shadowSockets map[int]int
+	shadowIOErr   string // why I/O columns are unavailable, if they are
+	shadowGroup   bool   // group processes by container or systemd unit
+	shadowGroups  map[int]cgroup.Group
+	shadowCgPrev  map[string]cgroup.Stats // keyed by cgroup path
+	shadowCgAt    time.Time
+	shadowCgCPU   map[string]float64 // CPU% per cgroup path since the last sample
+	shadowCgErr   string
 
 	// !clone state
 	sysInfo sysinfo.Info
 
+	// !chakra state
+	memory     *metrics.Memory
+	memHistory []float64 // used memory percentage, oldest first
+
+	// !kunai state
+	netPrev    []metrics.NetCounters
+	netPrevAt  time.Time
+	netRates   []metrics.NetRate
+	netHistory map[string][]float64 // rx+tx bytes/s per interface, oldest first
+
+	// !kura state
+	diskWarn    float64 // fill percentage that triggers a warning
+	diskPrev    []metrics.DiskCounters
+	diskPrevAt  time.Time
+	diskRates   []metrics.DiskRate
+	diskErr     string // why disk I/O is unavailable, if it is
+	filesystems []metrics.Filesystem
+
+	// !clan state
+	unitMgr     *systemd.Manager // nil when systemd can't be reached
+	unitMgrErr  string
+	units       []unitRow
+	unitIdx     int
+	unitCgPrev  map[string]cgroup.Stats // keyed by cgroup path
+	unitCgAt    time.Time
+	unitCPU     map[string]float64
+	confirmUnit unitAction
+	confirmName string // unit confirmUnit targets, fixed when the prompt opens
+	unitResult  string
+
+	// !ledger state
+	auditLog  *audit.Log
+	ledger    []audit.Entry
+	ledgerIdx int
+
+	// !scribe state
+	logPath string
+	logs    []logging.Entry
+	logIdx  int
+	logMin  slog.Level // lowest level shown
+
+	// !mirror state
+	controlPath string
+	mirror      []selfstat.Report
+	mirrorPrev  map[string]selfstat.Report // the previous report per process
+	mirrorErr   error
+
+	// replay is the recording shown instead of live data, nil when live
+	replay *record.Player
+
 	// shared
 	cpuPercent float64
 	err        string
@@ -42,34 +168,107 @@ type Model struct {
 // BubbleTea messages
 type (
 	processListMsg   []process.Process
-	shadowRefreshMsg []process.Process
 	cpuUpdateMsg     float64
 	sysInfoMsg       sysinfo.Info
-	killResultMsg    struct{ err error }
-	tickMsg          time.Time
-	errMsg           string
+	ledgerMsg        []audit.Entry
+	logsMsg          []logging.Entry
+	filesystemsMsg   []metrics.Filesystem
+	memoryMsg        metrics.Memory
+	shadowRefreshMsg struct {
+		procs    []process.Process
+		io       map[int]process.IOCounters
+		sockets  map[int]int
+		groups   map[int]cgroup.Group
+		cgStats  map[string]cgroup.Stats
+		at       time.Time
+		ioErr    error
+		groupErr error
+	}
+	networkMsg struct {
+		counters []metrics.NetCounters
+		at       time.Time
+	}
+	diskMsg struct {
+		counters []metrics.DiskCounters
+		at       time.Time
+		err      error
+	}
+	killResultMsg   struct{ err error }
+	freezeResultMsg struct {
+		pid int
+		err error
+	}
+	thawResultMsg struct {
+		thawed []process.Frozen
+		err    error
+	}
+	tickMsg time.Time
+	errMsg  string
 )
 
+// Options configures a dojo Model
+type Options struct {
+	Classifier  *icon.Classifier // load levels behind the dojo's colors
+	AuditLog    *audit.Log       // records every signal the dojo sends; may be nil
+	Policy      *protect.Policy  // which processes need a typed confirmation or can't be signalled
+	Disk        config.Disk      // when !kura warns about a filling mount
+	Units       *systemd.Manager // systemd connection behind !clan, nil when unavailable
+	UnitsErr    error            // why Units is nil
+	LogPath     string           // sensei's log file, shown in !scribe
+	ControlPath string           // sensei's control socket, asked for its overhead in !mirror
+}
+
 // NewModel creates a new Dojo model
-func NewModel() Model {
-	return Model{
+func NewModel(opts Options) Model {
+	freezer := process.NewFreezer()
+	freezer.OnSignal = func(fr process.Frozen, sig syscall.Signal, err error) {
+		cmdline, _ := process.Command(fr.PID)
+		recordSignal(opts.AuditLog, process.Process{PID: fr.PID, Name: fr.Name}, cmdline, sig, err)
+	}
+
+	m := Model{
 		currentScroll: ScrollShuriken,
+		classifier:    opts.Classifier,
 		cpuPercent:    -1,
+		freezer:       freezer,
+		policy:        opts.Policy,
+		auditLog:      opts.AuditLog,
+		diskWarn:      opts.Disk.WarnAbove,
+		unitMgr:       opts.Units,
+		logPath:       opts.LogPath,
+		logMin:        slog.LevelInfo,
+		controlPath:   opts.ControlPath,
+	}
+	if opts.UnitsErr != nil {
+		m.unitMgrErr = opts.UnitsErr.Error()
 	}
+	return m
+}
+
+// ThawAll resumes every process frozen from this dojo. It must be called
+// when the dojo exits so nothing is left suspended by accident.
+func (m Model) ThawAll() error {
+	_, err := m.freezer.ThawAll()
+	return err
 }
 
 // Init returns the initial commands to run
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
-		fetchProcesses,
+		m.processesCmd(),
 		fetchSysInfo,
-		fetchCPU,
+		m.cpuCmd(),
+		m.memoryCmd(),
+		fetchNetwork,
+		fetchDisk,
 		tickEvery(2*time.Second),
 	)
 }
 
 // Commands that fetch data asynchronously
 func fetchProcesses() tea.Msg {
+	defer selfstat.Time("processes")()
+
 	procs, err := process.ListTop(30)
 	if err != nil {
 		return errMsg(err.Error())
@@ -77,19 +276,58 @@ func fetchProcesses() tea.Msg {
 	return processListMsg(procs)
 }
 
-func fetchShadow() tea.Msg {
-	procs, err := process.ListTop(20)
-	if err != nil {
-		return errMsg(err.Error())
+// fetchShadow lists every process, with I/O counters and socket counts
+// when the !shadow I/O columns are on, and cgroups with their usage
+// when processes are grouped
+func fetchShadow(withIO, withGroups bool) tea.Cmd {
+	return func() tea.Msg {
+		defer selfstat.Time("shadow")()
+
+		procs, err := process.ListAll()
+		if err != nil {
+			return errMsg(err.Error())
+		}
+		msg := shadowRefreshMsg{procs: procs, at: time.Now()}
+
+		pids := make([]int, len(procs))
+		for i, p := range procs {
+			pids[i] = p.PID
+		}
+
+		if withIO {
+			msg.io, msg.ioErr = process.ReadIO(pids)
+			if sockets, err := process.SocketCounts(pids); err == nil {
+				msg.sockets = sockets
+			} else if msg.ioErr == nil {
+				msg.ioErr = err
+			}
+		}
+
+		if withGroups {
+			msg.groups, msg.groupErr = cgroup.ForPIDs(pids)
+			msg.cgStats = make(map[string]cgroup.Stats)
+			for _, g := range msg.groups {
+				if _, ok := msg.cgStats[g.Path]; ok {
+					continue
+				}
+				if st, err := cgroup.ReadStats(g.Path); err == nil {
+					msg.cgStats[g.Path] = st
+				}
+			}
+		}
+		return msg
 	}
-	return shadowRefreshMsg(procs)
 }
 
 func fetchSysInfo() tea.Msg {
+	defer selfstat.Time("sysinfo")()
+
 	return sysInfoMsg(sysinfo.Collect())
 }
 
 func fetchCPU() tea.Msg {
+	defer selfstat.Time("cpu")()
+
 	cpu, err := process.GetCPUPercent()
 	if err != nil {
 		return cpuUpdateMsg(-1)
@@ -97,13 +335,103 @@ func fetchCPU() tea.Msg {
 	return cpuUpdateMsg(cpu)
 }
 
-func killProcess(pid int) tea.Cmd {
+func fetchMemory() tea.Msg {
+	defer selfstat.Time("memory")()
+
+	mem, err := metrics.ReadMemory()
+	if err != nil {
+		return errMsg(err.Error())
+	}
+	return memoryMsg(mem)
+}
+
+func fetchNetwork() tea.Msg {
+	defer selfstat.Time("network")()
+
+	counters, err := metrics.ReadNetCounters()
+	if err != nil {
+		return errMsg(err.Error())
+	}
+	return networkMsg{counters: counters, at: time.Now()}
+}
+
+func fetchDisk() tea.Msg {
+	defer selfstat.Time("disk")()
+
+	counters, err := metrics.ReadDiskCounters()
+	return diskMsg{counters: counters, at: time.Now(), err: err}
+}
+
+func fetchFilesystems() tea.Msg {
+	defer selfstat.Time("filesystems")()
+
+	filesystems, err := metrics.ReadFilesystems()
+	if err != nil {
+		return errMsg(err.Error())
+	}
+	return filesystemsMsg(filesystems)
+}
+
+func fetchLedger(path string) tea.Cmd {
 	return func() tea.Msg {
-		err := process.Kill(pid)
+		if path == "" {
+			return errMsg("audit log unavailable")
+		}
+		entries, err := audit.ReadTail(path, ledgerSize)
+		if err != nil && !os.IsNotExist(err) {
+			return errMsg(err.Error())
+		}
+		return ledgerMsg(entries)
+	}
+}
+
+func fetchLogs(path string) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := logging.ReadTail(path, scribeSize)
+		if err != nil && !os.IsNotExist(err) {
+			return errMsg(err.Error())
+		}
+		return logsMsg(entries)
+	}
+}
+
+func killProcess(p process.Process, freezer *process.Freezer, auditLog *audit.Log) tea.Cmd {
+	return func() tea.Msg {
+		// Read the command line first, it's gone once the process exits
+		cmdline, _ := process.Command(p.PID)
+		err := process.Kill(p.PID)
+		recordSignal(auditLog, p, cmdline, syscall.SIGTERM, err)
+		if err == nil && freezer.IsFrozen(p.PID) {
+			// A stopped process can't act on SIGTERM until it is resumed
+			err = freezer.Thaw(p.PID)
+		}
 		return killResultMsg{err: err}
 	}
 }
 
+func freezeProcess(p process.Process, timeout time.Duration, freezer *process.Freezer) tea.Cmd {
+	return func() tea.Msg {
+		err := freezer.Freeze(p, timeout, time.Now())
+		return freezeResultMsg{pid: p.PID, err: err}
+	}
+}
+
+func thawProcess(fr process.Frozen, freezer *process.Freezer) tea.Cmd {
+	return func() tea.Msg {
+		if err := freezer.Thaw(fr.PID); err != nil {
+			return thawResultMsg{err: err}
+		}
+		return thawResultMsg{thawed: []process.Frozen{fr}}
+	}
+}
+
+func thawExpired(freezer *process.Freezer) tea.Cmd {
+	return func() tea.Msg {
+		thawed, err := freezer.ThawExpired(time.Now())
+		return thawResultMsg{thawed: thawed, err: err}
+	}
+}
+
 func tickEvery(d time.Duration) tea.Cmd {
 	return tea.Tick(d, func(t time.Time) tea.Msg {
 		return tickMsg(t)
@@ -0,0 +1,95 @@
+package dojo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"system-shinobi/sensei/internal/record"
+)
+
+// errNotRecorded explains the !shadow columns a recording can't fill
+var errNotRecorded = errors.New("not available when replaying a recording")
+
+// WithReplay returns the model showing a recording made with `sensei
+// record` in !shuriken, !shadow, !chakra and the status bar instead of
+// live data. Signals are refused while replaying: the recorded PIDs may
+// belong to other processes by now.
+func (m Model) WithReplay(p *record.Player) Model {
+	m.replay = p
+	return m
+}
+
+// processesCmd fetches the top processes, live or from the recording
+func (m Model) processesCmd() tea.Cmd {
+	if m.replay == nil {
+		return fetchProcesses
+	}
+	p := m.replay
+	return func() tea.Msg {
+		return processListMsg(p.At(time.Now()).Processes)
+	}
+}
+
+// shadowCmd fetches the !shadow process list, live or from the recording
+func (m Model) shadowCmd() tea.Cmd {
+	if m.replay == nil {
+		return fetchShadow(m.shadowIO, m.shadowGroup)
+	}
+	p, withIO, withGroups := m.replay, m.shadowIO, m.shadowGroup
+	return func() tea.Msg {
+		now := time.Now()
+		msg := shadowRefreshMsg{procs: p.At(now).Processes, at: now}
+		if withIO {
+			msg.ioErr = errNotRecorded
+		}
+		if withGroups {
+			msg.groupErr = errNotRecorded
+		}
+		return msg
+	}
+}
+
+// cpuCmd fetches the CPU percentage, live or from the recording
+func (m Model) cpuCmd() tea.Cmd {
+	if m.replay == nil {
+		return fetchCPU
+	}
+	p := m.replay
+	return func() tea.Msg {
+		f := p.At(time.Now())
+		if f.Reading == nil {
+			return cpuUpdateMsg(-1)
+		}
+		return cpuUpdateMsg(f.Reading.CpuPercent)
+	}
+}
+
+// memoryCmd fetches memory usage, live or from the recording
+func (m Model) memoryCmd() tea.Cmd {
+	if m.replay == nil {
+		return fetchMemory
+	}
+	p := m.replay
+	return func() tea.Msg {
+		f := p.At(time.Now())
+		if f.Reading == nil || f.Reading.Memory == nil {
+			return nil
+		}
+		return memoryMsg(*f.Reading.Memory)
+	}
+}
+
+// replayStatus describes playback for the status bar, e.g. "REPLAY
+// 1:05/4:00 x4"
+func (m Model) replayStatus(now time.Time) string {
+	p := m.replay
+	return fmt.Sprintf("REPLAY %s/%s x%g", formatClock(p.Position(now)), formatClock(p.Length()), p.Speed())
+}
+
+// formatClock formats a duration as minutes and seconds
+func formatClock(d time.Duration) string {
+	secs := int(d.Seconds())
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
@@ -0,0 +1,115 @@
+package dojo
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"system-shinobi/sensei/internal/logging"
+)
+
+// scribeLevels are the minimum levels [l] cycles through
+var scribeLevels = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+// scribeEntries returns the loaded log entries at or above the chosen level
+func (m Model) scribeEntries() []logging.Entry {
+	var out []logging.Entry
+	for _, e := range m.logs {
+		var lvl slog.Level
+		if err := lvl.UnmarshalText([]byte(e.Level)); err != nil || lvl >= m.logMin {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// renderScribe renders the !scribe sensei log browser
+func (m Model) renderScribe() string {
+	var b strings.Builder
+
+	b.WriteString(scrollTitleStyle.Render("!SCRIBE - Sensei Log"))
+	b.WriteString("\n\n")
+
+	entries := m.scribeEntries()
+	if len(entries) == 0 {
+		b.WriteString(fmt.Sprintf("  No %s or higher entries in %s.", m.logMin, m.logPath))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("  [l] Level  [r] Reload"))
+		return b.String()
+	}
+
+	// Table header
+	header := fmt.Sprintf("  %-8s %-5s %s", "Time", "Level", "Message")
+	b.WriteString(tableHeaderStyle.Render(header))
+	b.WriteString("\n")
+
+	// Keep the selection in view, leaving room for the detail lines
+	visible := m.height - 14
+	if visible < 5 {
+		visible = 5
+	}
+	idx := min(m.logIdx, len(entries)-1)
+	start := 0
+	if idx >= visible {
+		start = idx - visible + 1
+	}
+
+	width := max(m.width-20, 40)
+	for i := start; i < len(entries) && i < start+visible; i++ {
+		e := entries[i]
+		row := fmt.Sprintf("  %-8s %-5s %s",
+			e.Time.Local().Format("15:04:05"), e.Level, truncate(strings.TrimSpace(e.Msg+"  "+e.Attrs), width))
+
+		switch {
+		case i == idx:
+			row = selectedRowStyle.Render(row)
+		case e.Level == slog.LevelError.String():
+			row = errorStyle.Render(row)
+		case e.Level == slog.LevelWarn.String():
+			row = warnStyle.Render(row)
+		default:
+			row = infoValueStyle.Render(row)
+		}
+		b.WriteString(row)
+		b.WriteString("\n")
+	}
+
+	// Details of the selected entry
+	sel := entries[idx]
+	attrs := sel.Attrs
+	if attrs == "" {
+		attrs = "(none)"
+	}
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("  %s %s\n", infoLabelStyle.Render("Time"), infoValueStyle.Render(sel.Time.Local().Format("2006-01-02 15:04:05.000"))))
+	b.WriteString(fmt.Sprintf("  %s %s\n", infoLabelStyle.Render("Message"), infoValueStyle.Render(truncate(sel.Msg, 100))))
+	b.WriteString(fmt.Sprintf("  %s %s\n", infoLabelStyle.Render("Attributes"), infoValueStyle.Render(truncate(attrs, 100))))
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(fmt.Sprintf("  [up/down] Browse  [l] Level: %s+  [r] Reload  (%s)", m.logMin, m.logPath)))
+
+	return b.String()
+}
+
+func (m Model) handleScribeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.logIdx > 0 {
+			m.logIdx--
+		}
+	case "down", "j":
+		if m.logIdx < len(m.scribeEntries())-1 {
+			m.logIdx++
+		}
+	case "l":
+		for i, lvl := range scribeLevels {
+			if lvl == m.logMin {
+				m.logMin = scribeLevels[(i+1)%len(scribeLevels)]
+				break
+			}
+		}
+		m.logIdx = 0
+	}
+	return m, nil
+}
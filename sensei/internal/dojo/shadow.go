@@ -2,9 +2,35 @@ package dojo
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"system-shinobi/sensei/internal/cgroup"
+	"system-shinobi/sensei/internal/metrics"
+	"system-shinobi/sensei/internal/process"
+	"system-shinobi/sensei/internal/sysinfo"
 )
 
+// shadowSortKey is the column !shadow is sorted by
+type shadowSortKey int
+
+const (
+	sortCPU shadowSortKey = iota
+	sortMemory
+	sortRead
+	sortWrite
+	sortSockets
+
+	// sort keys that don't need the I/O columns
+	basicSortCount = 2
+	sortKeyCount   = 5
+)
+
+// shadowRows is how many processes !shadow lists
+const shadowRows = 20
+
 // renderShadow renders the !shadow process monitor scroll
 func (m Model) renderShadow() string {
 	var b strings.Builder
@@ -17,27 +43,217 @@ func (m Model) renderShadow() string {
 		return b.String()
 	}
 
-	// Table header
-	header := fmt.Sprintf("  %-7s %-7s %-7s %s", "PID", "CPU%", "MEM%", "Name")
+	// Table header, with the sort column marked
+	mark := func(key shadowSortKey, title string) string {
+		if key == m.shadowSort {
+			return title + "▼"
+		}
+		return title
+	}
+	header := fmt.Sprintf("  %-7s %-7s %-7s", "PID", mark(sortCPU, "CPU%"), mark(sortMemory, "MEM%"))
+	if m.shadowIO {
+		header += fmt.Sprintf(" %-11s %-11s %-6s", mark(sortRead, "Read"), mark(sortWrite, "Write"), mark(sortSockets, "Socks"))
+	}
+	header += " Name"
 	b.WriteString(tableHeaderStyle.Render(header))
 	b.WriteString("\n")
 
 	// Process rows (read-only, no selection)
-	visible := m.height - 10
+	visible := min(m.height-10, shadowRows)
 	if visible < 5 {
 		visible = 5
 	}
-	for i, p := range m.shadowProcs {
-		if i >= visible {
-			break
+	if m.shadowGroup {
+		b.WriteString(m.renderShadowGroups(visible))
+	} else {
+		for i, p := range m.sortedShadow() {
+			if i >= visible {
+				break
+			}
+			b.WriteString(m.cpuColor(p.CPU).Render(m.shadowRow(p, "  ")))
+			b.WriteString("\n")
+		}
+	}
+
+	for _, note := range []struct {
+		on         bool
+		title, err string
+	}{
+		{m.shadowIO, "I/O", m.shadowIOErr},
+		{m.shadowGroup, "Groups", m.shadowCgErr},
+	} {
+		if note.on && note.err != "" {
+			b.WriteString("\n")
+			b.WriteString(helpStyle.Render(fmt.Sprintf("  %s: %s", note.title, note.err)))
+			b.WriteString("\n")
 		}
-		row := fmt.Sprintf("  %-7d %-7.1f %-7.1f %s", p.PID, p.CPU, p.Memory, truncate(p.Name, 30))
-		b.WriteString(cpuColor(p.CPU).Render(row))
-		b.WriteString("\n")
 	}
 
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("  Auto-refreshes every 2s  [r] Force refresh"))
+	b.WriteString(helpStyle.Render("  Auto-refreshes every 2s  [i] I/O columns  [g] Group by container/unit  [s] Sort  [r] Force refresh"))
+
+	return b.String()
+}
+
+// shadowRow formats one process line, with the I/O columns if enabled
+func (m Model) shadowRow(p process.Process, indent string) string {
+	row := fmt.Sprintf("%s%-7d %-7.1f %-7.1f", indent, p.PID, p.CPU, p.Memory)
+	if m.shadowIO {
+		read, write := "-", "-"
+		if r, ok := m.shadowRates[p.PID]; ok {
+			read, write = sysinfo.FormatRate(r.ReadBytesPerSec), sysinfo.FormatRate(r.WriteBytesPerSec)
+		}
+		sockets := "-"
+		if n, ok := m.shadowSockets[p.PID]; ok {
+			sockets = fmt.Sprintf("%d", n)
+		}
+		row += fmt.Sprintf(" %-11s %-11s %-6s", read, write, sockets)
+	}
+	return row + " " + truncate(p.Name, 30)
+}
+
+// procGroup is the processes sharing one cgroup
+type procGroup struct {
+	group cgroup.Group
+	procs []process.Process
+	cpu   float64 // from the cgroup when known, else summed from ps
+}
+
+// groupShadow buckets the sorted processes by cgroup, busiest group first
+func (m Model) groupShadow() []procGroup {
+	index := make(map[string]int)
+	var groups []procGroup
+	for _, p := range m.sortedShadow() {
+		g, ok := m.shadowGroups[p.PID]
+		if !ok {
+			g = cgroup.Group{Path: "(unknown)"}
+		}
+		i, ok := index[g.Path]
+		if !ok {
+			i = len(groups)
+			index[g.Path] = i
+			groups = append(groups, procGroup{group: g})
+		}
+		groups[i].procs = append(groups[i].procs, p)
+		groups[i].cpu += p.CPU
+	}
+
+	for i := range groups {
+		if cpu, ok := m.shadowCgCPU[groups[i].group.Path]; ok {
+			groups[i].cpu = cpu
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].cpu > groups[j].cpu })
+	return groups
+}
+
+// renderShadowGroups renders a header per cgroup with its CPU, memory
+// and pressure, followed by its processes
+func (m Model) renderShadowGroups(visible int) string {
+	var b strings.Builder
+	rows := 0
+	for _, sg := range m.groupShadow() {
+		if rows >= visible {
+			break
+		}
+		line := fmt.Sprintf("  ▸ %-30s %3d procs  CPU %5.1f%%", truncate(sg.group.Label(), 30), len(sg.procs), sg.cpu)
+		if st, ok := m.shadowCgPrev[sg.group.Path]; ok {
+			line += "  Mem " + sysinfo.FormatMemory(st.MemoryCurrent)
+			if st.MemoryMax > 0 {
+				line += " / " + sysinfo.FormatMemory(st.MemoryMax)
+			}
+			line += fmt.Sprintf("  PSI cpu %s mem %s io %s",
+				pressureAvg10(st.CPUPressure), pressureAvg10(st.MemoryPressure), pressureAvg10(st.IOPressure))
+		}
+		b.WriteString(tableHeaderStyle.Render(line))
+		b.WriteString("\n")
+		rows++
 
+		for _, p := range sg.procs {
+			if rows >= visible {
+				break
+			}
+			b.WriteString(m.cpuColor(p.CPU).Render(m.shadowRow(p, "    ")))
+			b.WriteString("\n")
+			rows++
+		}
+	}
 	return b.String()
 }
+
+// sortedShadow returns the processes ordered by the current sort key.
+// Processes whose I/O couldn't be read sort after every known value.
+func (m Model) sortedShadow() []process.Process {
+	procs := make([]process.Process, len(m.shadowProcs))
+	copy(procs, m.shadowProcs)
+
+	switch m.shadowSort {
+	case sortCPU:
+		process.SortByCPU(procs)
+	case sortMemory:
+		process.SortByMemory(procs)
+	default:
+		value := func(pid int) float64 {
+			switch m.shadowSort {
+			case sortRead:
+				if r, ok := m.shadowRates[pid]; ok {
+					return r.ReadBytesPerSec
+				}
+			case sortWrite:
+				if r, ok := m.shadowRates[pid]; ok {
+					return r.WriteBytesPerSec
+				}
+			case sortSockets:
+				if n, ok := m.shadowSockets[pid]; ok {
+					return float64(n)
+				}
+			}
+			return -1
+		}
+		sort.SliceStable(procs, func(i, j int) bool {
+			return value(procs[i].PID) > value(procs[j].PID)
+		})
+	}
+	return procs
+}
+
+// handleShadowKey toggles the I/O columns and grouping, and cycles the
+// sort column
+func (m Model) handleShadowKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "i":
+		m.shadowIO = !m.shadowIO
+		if m.shadowIO {
+			// Rates against a sample from long ago would be meaningless
+			m.shadowIOPrev = nil
+			m.shadowIOAt = time.Time{}
+			m.shadowRates = nil
+		} else if m.shadowSort >= basicSortCount {
+			m.shadowSort = sortCPU
+		}
+		return m, m.shadowCmd()
+	case "g":
+		m.shadowGroup = !m.shadowGroup
+		if m.shadowGroup {
+			m.shadowCgPrev = nil
+			m.shadowCgAt = time.Time{}
+			m.shadowCgCPU = nil
+		}
+		return m, m.shadowCmd()
+	case "s":
+		count := shadowSortKey(basicSortCount)
+		if m.shadowIO {
+			count = sortKeyCount
+		}
+		m.shadowSort = (m.shadowSort + 1) % count
+	}
+	return m, nil
+}
+
+// pressureAvg10 formats the 10s "some" pressure, or "-" when unavailable
+func pressureAvg10(p *metrics.Pressure) string {
+	if p == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", p.SomeAvg10)
+}
@@ -3,8 +3,22 @@ package dojo
 import (
 	"fmt"
 	"strings"
+	"time"
+
+	"system-shinobi/sensei/internal/process"
+	"system-shinobi/sensei/internal/protect"
 )
 
+// freezeDurations are the timeouts offered when freezing a process.
+// Zero keeps the process frozen until it is thawed or the dojo exits.
+var freezeDurations = []time.Duration{
+	30 * time.Second,
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	0,
+}
+
 // renderShuriken renders the !shuriken process killer scroll
 func (m Model) renderShuriken() string {
 	var b strings.Builder
@@ -34,31 +48,88 @@ func (m Model) renderShuriken() string {
 			break
 		}
 
+		frozen := m.freezer.IsFrozen(p.PID)
+		decision := m.policy.Check(p)
 		row := fmt.Sprintf("  %-7d %-7.1f %-7.1f %s", p.PID, p.CPU, p.Memory, truncate(p.Name, 30))
+		if frozen {
+			row += " [frozen]"
+		}
+		if decision.Verdict != protect.Allow {
+			row += " [protected]"
+		}
 
 		if i == m.selectedIdx {
-			if m.confirmKill {
+			if m.typing {
+				verb := "kill"
+				if m.pending == actionFreeze {
+					verb = "freeze"
+				}
+				row = confirmStyle.Render(fmt.Sprintf(" PROTECTED: type %q to %s PID %d: %s_ ", p.Name, verb, p.PID, m.typed))
+				row += "\n" + protectedStyle.Render("    Protected because "+decision.Reason)
+			} else if m.confirmKill {
 				row = confirmStyle.Render(fmt.Sprintf(" KILL PID %d (%s)? [Enter] Yes  [Esc] No ", p.PID, truncate(p.Name, 15)))
+			} else if m.confirmFreeze {
+				row = confirmStyle.Render(fmt.Sprintf(" FREEZE PID %d (%s) for < %s >? [Enter] Yes  [Esc] No ",
+					p.PID, truncate(p.Name, 15), formatFreezeDuration(freezeDurations[m.freezeChoice])))
 			} else {
 				row = selectedRowStyle.Render(row)
 			}
+		} else if frozen {
+			row = frozenStyle.Render(row)
+		} else if decision.Verdict != protect.Allow {
+			row = protectedStyle.Render(row)
 		} else {
-			row = cpuColor(p.CPU).Render(row)
+			row = m.cpuColor(p.CPU).Render(row)
 		}
 
 		b.WriteString(row)
 		b.WriteString("\n")
 	}
 
+	if frozen := m.freezer.List(); len(frozen) > 0 {
+		b.WriteString("\n")
+		b.WriteString(m.renderFrozen(frozen))
+	}
+
+	b.WriteString("\n")
+	if m.typing {
+		b.WriteString(helpStyle.Render("  Type the process name exactly  [Enter] Confirm  [Esc] Cancel"))
+	} else if m.confirmFreeze {
+		b.WriteString(helpStyle.Render("  [left/right] Duration  [Enter] Freeze  [Esc] Cancel"))
+	} else {
+		b.WriteString(helpStyle.Render("  [up/down] Navigate  [Enter] Kill  [f] Freeze  [u] Thaw  [r] Refresh"))
+	}
+
+	return b.String()
+}
+
+// renderFrozen renders the list of processes suspended from this dojo
+func (m Model) renderFrozen(frozen []process.Frozen) string {
+	var b strings.Builder
+
+	b.WriteString(frozenStyle.Bold(true).Render(fmt.Sprintf("  Frozen (%d)", len(frozen))))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("  [up/down] Navigate  [Enter] Kill  [r] Refresh"))
+
+	now := time.Now()
+	for _, fr := range frozen {
+		thaw := "on exit"
+		if !fr.ThawAt.IsZero() {
+			thaw = "in " + fr.ThawAt.Sub(now).Round(time.Second).String()
+		}
+		row := fmt.Sprintf("  %-7d %-31s thaws %s", fr.PID, truncate(fr.Name, 30), thaw)
+		b.WriteString(frozenStyle.Render(row))
+		b.WriteString("\n")
+	}
 
 	return b.String()
 }
 
 func (m Model) visibleProcessCount() int {
-	// Reserve lines for header, title, help, status bar
+	// Reserve lines for header, title, help, status bar and frozen list
 	available := m.height - 10
+	if n := len(m.freezer.List()); n > 0 {
+		available -= n + 2
+	}
 	if available < 5 {
 		available = 5
 	}
@@ -74,3 +145,23 @@ func truncate(s string, max int) string {
 	}
 	return s[:max-1] + "~"
 }
+
+// formatFreezeDuration formats a freeze timeout for the duration prompt
+func formatFreezeDuration(d time.Duration) string {
+	if d == 0 {
+		return "until exit"
+	}
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%ds", int(d.Seconds()))
+}
+
+// formatThawed lists thawed processes as "PID 123 (name), PID 456 (name)"
+func formatThawed(thawed []process.Frozen) string {
+	parts := make([]string, len(thawed))
+	for i, fr := range thawed {
+		parts[i] = fmt.Sprintf("PID %d (%s)", fr.PID, truncate(fr.Name, 15))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,49 @@
+package dojo
+
+import (
+	"math"
+	"strings"
+)
+
+// sparkBlocks are the eight block heights used to draw sparklines
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// historySize is how many samples sparklines keep (2 minutes at 2s ticks)
+const historySize = 60
+
+// sparkline renders values as a row of block characters scaled to
+// ceiling. A ceiling of zero scales to the largest value.
+func sparkline(values []float64, ceiling float64) string {
+	if ceiling <= 0 {
+		for _, v := range values {
+			ceiling = math.Max(ceiling, v)
+		}
+	}
+	if ceiling <= 0 {
+		ceiling = 1
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		idx := int(v / ceiling * float64(len(sparkBlocks)-1))
+		idx = min(max(idx, 0), len(sparkBlocks)-1)
+		b.WriteRune(sparkBlocks[idx])
+	}
+	return b.String()
+}
+
+// meter renders a percentage as a fixed-width bar, e.g. [█████░░░░░]
+func meter(percent float64, width int) string {
+	filled := int(math.Round(percent / 100 * float64(width)))
+	filled = min(max(filled, 0), width)
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
+}
+
+// appendHistory appends v, keeping at most historySize samples
+func appendHistory(history []float64, v float64) []float64 {
+	history = append(history, v)
+	if len(history) > historySize {
+		history = history[len(history)-historySize:]
+	}
+	return history
+}
@@ -1,13 +1,21 @@
 package dojo
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/lipgloss"
+	"system-shinobi/sensei/internal/icon"
+)
+
+// defaultLevels are the built-in Idle/Low/Medium/High levels, the source
+// of the dojo's level colors
+var defaultLevels = icon.DefaultClassifier().Levels()
 
-// Ninja theme colors (matching icon states from icon/generator.go)
+// Ninja theme colors
 var (
-	colorIdle   = lipgloss.Color("#505050") // gray
-	colorLow    = lipgloss.Color("#4CAF50") // green
-	colorMedium = lipgloss.Color("#FFC107") // amber
-	colorHigh   = lipgloss.Color("#F44336") // red
+	colorIdle   = lipgloss.Color(icon.Hex(defaultLevels[icon.StateIdle].Color))   // gray
+	colorLow    = lipgloss.Color(icon.Hex(defaultLevels[icon.StateLow].Color))    // green
+	colorMedium = lipgloss.Color(icon.Hex(defaultLevels[icon.StateMedium].Color)) // amber
+	colorHigh   = lipgloss.Color(icon.Hex(defaultLevels[icon.StateHigh].Color))   // red
+	colorFrozen = lipgloss.Color("#64B5F6")                                       // ice blue
 	colorDim    = lipgloss.Color("#555555")
 	colorBright = lipgloss.Color("#EEEEEE")
 	colorBg     = lipgloss.Color("#1A1A2E") // dark navy
@@ -52,6 +60,13 @@ var (
 			Background(colorAccent).
 			Padding(0, 1)
 
+	frozenStyle = lipgloss.NewStyle().
+			Foreground(colorFrozen)
+
+	protectedStyle = lipgloss.NewStyle().
+			Foreground(colorMedium).
+			Italic(true)
+
 	statusBarStyle = lipgloss.NewStyle().
 			Foreground(colorDim)
 
@@ -62,6 +77,9 @@ var (
 			Foreground(colorHigh).
 			Bold(true)
 
+	warnStyle = lipgloss.NewStyle().
+			Foreground(colorMedium)
+
 	infoLabelStyle = lipgloss.NewStyle().
 			Foreground(colorDim).
 			Width(14)
@@ -70,17 +88,8 @@ var (
 			Foreground(colorBright)
 )
 
-// cpuColor returns a lipgloss style colored by CPU percentage
-func cpuColor(cpu float64) lipgloss.Style {
-	base := lipgloss.NewStyle()
-	if cpu >= 70 {
-		return base.Foreground(colorHigh)
-	}
-	if cpu >= 40 {
-		return base.Foreground(colorMedium)
-	}
-	if cpu >= 15 {
-		return base.Foreground(colorLow)
-	}
-	return base.Foreground(colorDim)
+// cpuColor returns a lipgloss style colored by CPU percentage, using the
+// same levels (and gradient) as the tray icon
+func (m Model) cpuColor(cpu float64) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(icon.Hex(m.classifier.Color(cpu))))
 }
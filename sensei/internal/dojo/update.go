@@ -5,7 +5,13 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"system-shinobi/sensei/internal/audit"
+	"system-shinobi/sensei/internal/cgroup"
+	"system-shinobi/sensei/internal/logging"
+	"system-shinobi/sensei/internal/metrics"
 	"system-shinobi/sensei/internal/process"
+	"system-shinobi/sensei/internal/protect"
+	"system-shinobi/sensei/internal/selfstat"
 	"system-shinobi/sensei/internal/sysinfo"
 )
 
@@ -26,7 +32,37 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case shadowRefreshMsg:
-		m.shadowProcs = []process.Process(msg)
+		m.shadowProcs = msg.procs
+		m.shadowSockets = msg.sockets
+		m.shadowIOErr = ""
+		if msg.ioErr != nil {
+			m.shadowIOErr = msg.ioErr.Error()
+		}
+		if msg.io != nil {
+			if !m.shadowIOAt.IsZero() {
+				m.shadowRates = process.IORates(m.shadowIOPrev, msg.io, msg.at.Sub(m.shadowIOAt))
+			}
+			m.shadowIOPrev = msg.io
+			m.shadowIOAt = msg.at
+		}
+		m.shadowGroups = msg.groups
+		m.shadowCgErr = ""
+		if msg.groupErr != nil {
+			m.shadowCgErr = msg.groupErr.Error()
+		}
+		if msg.cgStats != nil {
+			if !m.shadowCgAt.IsZero() {
+				elapsed := msg.at.Sub(m.shadowCgAt)
+				m.shadowCgCPU = make(map[string]float64, len(msg.cgStats))
+				for path, st := range msg.cgStats {
+					if prev, ok := m.shadowCgPrev[path]; ok {
+						m.shadowCgCPU[path] = cgroup.CPUPercent(prev, st, elapsed)
+					}
+				}
+			}
+			m.shadowCgPrev = msg.cgStats
+			m.shadowCgAt = msg.at
+		}
 		return m, nil
 
 	case cpuUpdateMsg:
@@ -37,6 +73,100 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.sysInfo = sysinfo.Info(msg)
 		return m, nil
 
+	case memoryMsg:
+		mem := metrics.Memory(msg)
+		m.memory = &mem
+		m.memHistory = appendHistory(m.memHistory, mem.UsedPercent())
+		return m, nil
+
+	case networkMsg:
+		if !m.netPrevAt.IsZero() {
+			m.netRates = metrics.NetRates(m.netPrev, msg.counters, msg.at.Sub(m.netPrevAt))
+			history := make(map[string][]float64, len(m.netRates))
+			for _, r := range m.netRates {
+				history[r.Name] = appendHistory(m.netHistory[r.Name], r.RxBytesPerSec+r.TxBytesPerSec)
+			}
+			m.netHistory = history
+		}
+		m.netPrev = msg.counters
+		m.netPrevAt = msg.at
+		return m, nil
+
+	case diskMsg:
+		if msg.err != nil {
+			m.diskErr = msg.err.Error()
+			return m, nil
+		}
+		if !m.diskPrevAt.IsZero() {
+			m.diskRates = metrics.DiskRates(m.diskPrev, msg.counters, msg.at.Sub(m.diskPrevAt))
+		}
+		m.diskPrev = msg.counters
+		m.diskPrevAt = msg.at
+		return m, nil
+
+	case filesystemsMsg:
+		m.filesystems = []metrics.Filesystem(msg)
+		return m, nil
+
+	case unitsMsg:
+		cgStats := make(map[string]cgroup.Stats)
+		for _, u := range msg.units {
+			if u.stats != nil {
+				cgStats[u.cgroupPath] = *u.stats
+			}
+		}
+		if !m.unitCgAt.IsZero() {
+			elapsed := msg.at.Sub(m.unitCgAt)
+			m.unitCPU = make(map[string]float64, len(cgStats))
+			for path, st := range cgStats {
+				if prev, ok := m.unitCgPrev[path]; ok {
+					m.unitCPU[path] = cgroup.CPUPercent(prev, st, elapsed)
+				}
+			}
+		}
+		m.unitCgPrev = cgStats
+		m.unitCgAt = msg.at
+		m.units = msg.units
+		if i := m.unitIndex(m.confirmName); m.confirmUnit != unitNone && i >= 0 {
+			m.unitIdx = i // keep the prompt on its unit as the list changes
+		}
+		if m.unitIdx >= len(m.units) {
+			m.unitIdx = max(len(m.units)-1, 0)
+		}
+		return m, nil
+
+	case unitResultMsg:
+		if msg.err != nil {
+			m.unitResult = errorStyle.Render(fmt.Sprintf("  %s %s failed: %v", msg.action, msg.name, msg.err))
+		} else {
+			m.unitResult = scrollTitleStyle.Render(fmt.Sprintf("  %s: %s done.", msg.name, msg.action))
+		}
+		return m, fetchUnits(m.unitMgr)
+
+	case ledgerMsg:
+		m.ledger = []audit.Entry(msg)
+		if m.ledgerIdx >= len(m.ledger) {
+			m.ledgerIdx = max(len(m.ledger)-1, 0)
+		}
+		return m, nil
+
+	case logsMsg:
+		m.logs = []logging.Entry(msg)
+		if m.logIdx >= len(m.logs) {
+			m.logIdx = max(len(m.logs)-1, 0)
+		}
+		return m, nil
+
+	case mirrorMsg:
+		prev := make(map[string]selfstat.Report, len(m.mirror))
+		for _, r := range m.mirror {
+			prev[r.Process] = r
+		}
+		m.mirrorPrev = prev
+		m.mirror = msg.reports
+		m.mirrorErr = msg.err
+		return m, nil
+
 	case killResultMsg:
 		if msg.err != nil {
 			m.killResult = errorStyle.Render(fmt.Sprintf("  Kill failed: %v", msg.err))
@@ -45,16 +175,45 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.confirmKill = false
 		// Refresh process list after kill
-		return m, fetchProcesses
+		return m, m.processesCmd()
+
+	case freezeResultMsg:
+		if msg.err != nil {
+			m.killResult = errorStyle.Render(fmt.Sprintf("  Freeze failed: %v", msg.err))
+		} else {
+			m.killResult = frozenStyle.Render(fmt.Sprintf("  PID %d frozen.", msg.pid))
+		}
+		m.confirmFreeze = false
+		return m, m.processesCmd()
+
+	case thawResultMsg:
+		if msg.err != nil {
+			m.killResult = errorStyle.Render(fmt.Sprintf("  Thaw failed: %v", msg.err))
+		} else if len(msg.thawed) > 0 {
+			m.killResult = scrollTitleStyle.Render(fmt.Sprintf("  Thawed %s.", formatThawed(msg.thawed)))
+		}
+		return m, nil
 
 	case tickMsg:
-		// Periodic refresh: update shadow processes and CPU
+		// Periodic refresh: update shadow processes and CPU, resume
+		// processes whose freeze timeout has run out
 		cmds := []tea.Cmd{
-			fetchCPU,
+			m.cpuCmd(),
+			m.memoryCmd(),
+			fetchNetwork,
+			fetchDisk,
+			thawExpired(m.freezer),
 			tickEvery(2 * time.Second),
 		}
-		if m.currentScroll == ScrollShadow {
-			cmds = append(cmds, fetchShadow)
+		switch m.currentScroll {
+		case ScrollShadow:
+			cmds = append(cmds, m.shadowCmd())
+		case ScrollKura:
+			cmds = append(cmds, fetchFilesystems)
+		case ScrollClan:
+			cmds = append(cmds, fetchUnits(m.unitMgr))
+		case ScrollMirror:
+			cmds = append(cmds, fetchMirror(m.controlPath))
 		}
 		return m, tea.Batch(cmds...)
 
@@ -67,33 +226,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// A typed confirmation swallows every key until it is done
+	if m.typing {
+		return m.handleTypedKey(msg)
+	}
+
 	// Global keys
+	if scroll, ok := scrollKeys[msg.String()]; ok {
+		return m.switchScroll(scroll)
+	}
 	switch msg.String() {
 	case "ctrl+c", "q":
-		if !m.confirmKill {
+		if !m.confirmKill && !m.confirmFreeze && m.confirmUnit == unitNone {
 			return m, tea.Quit
 		}
 	case "tab":
-		m.confirmKill = false
-		m.killResult = ""
-		m.currentScroll = (m.currentScroll + 1) % 3
-		return m, m.scrollEnterCmd()
+		return m.switchScroll((m.currentScroll + 1) % scrollCount)
 	case "shift+tab":
-		m.confirmKill = false
-		m.killResult = ""
-		m.currentScroll = (m.currentScroll + 2) % 3 // wraps backward
-		return m, m.scrollEnterCmd()
-	case "1":
-		m.currentScroll = ScrollShuriken
-		m.confirmKill = false
-		m.killResult = ""
-		return m, fetchProcesses
-	case "2":
-		m.currentScroll = ScrollShadow
-		return m, fetchShadow
-	case "3":
-		m.currentScroll = ScrollClone
-		return m, tea.Batch(fetchSysInfo, fetchCPU)
+		return m.switchScroll((m.currentScroll + scrollCount - 1) % scrollCount) // wraps backward
 	case "r":
 		return m, m.scrollEnterCmd()
 	}
@@ -102,12 +252,32 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch m.currentScroll {
 	case ScrollShuriken:
 		return m.handleShurikenKey(msg)
+	case ScrollShadow:
+		return m.handleShadowKey(msg)
+	case ScrollClan:
+		return m.handleClanKey(msg)
+	case ScrollLedger:
+		return m.handleLedgerKey(msg)
+	case ScrollScribe:
+		return m.handleScribeKey(msg)
 	}
 
 	return m, nil
 }
 
 func (m Model) handleShurikenKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirmFreeze {
+		return m.handleFreezeKey(msg)
+	}
+
+	switch msg.String() {
+	case "enter", "f", "u":
+		if m.replay != nil {
+			m.killResult = errorStyle.Render("  Replaying a recording, signals are disabled.")
+			return m, nil
+		}
+	}
+
 	switch msg.String() {
 	case "up", "k":
 		if m.selectedIdx > 0 {
@@ -123,25 +293,157 @@ func (m Model) handleShurikenKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.killResult = ""
 		}
 	case "enter":
-		if m.confirmKill && m.selectedIdx < len(m.processes) {
-			return m, killProcess(m.processes[m.selectedIdx].PID)
+		if m.selectedIdx >= len(m.processes) {
+			break
+		}
+		p := m.processes[m.selectedIdx]
+		if m.confirmKill {
+			return m, killProcess(p, m.freezer, m.auditLog)
+		}
+		switch d := m.policy.Check(p); d.Verdict {
+		case protect.Refuse:
+			m.killResult = refusedMessage(p, d)
+		case protect.Confirm:
+			m = m.startTyping(actionKill)
+		default:
+			m.confirmKill = true
+		}
+	case "f":
+		if m.selectedIdx >= len(m.processes) {
+			break
+		}
+		p := m.processes[m.selectedIdx]
+		if m.freezer.IsFrozen(p.PID) {
+			break
+		}
+		m.confirmKill = false
+		if d := m.policy.Check(p); d.Verdict == protect.Refuse {
+			m.killResult = refusedMessage(p, d)
+		} else {
+			m.confirmFreeze = true
+		}
+	case "u":
+		if m.selectedIdx < len(m.processes) {
+			if fr, ok := m.freezer.Get(m.processes[m.selectedIdx].PID); ok {
+				return m, thawProcess(fr, m.freezer)
+			}
 		}
-		m.confirmKill = true
 	case "esc":
 		m.confirmKill = false
 	}
 	return m, nil
 }
 
+// handleFreezeKey handles keys while the freeze duration prompt is open
+func (m Model) handleFreezeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "left", "h":
+		m.freezeChoice = (m.freezeChoice + len(freezeDurations) - 1) % len(freezeDurations)
+	case "right", "l":
+		m.freezeChoice = (m.freezeChoice + 1) % len(freezeDurations)
+	case "enter":
+		if m.selectedIdx >= len(m.processes) {
+			m.confirmFreeze = false
+			break
+		}
+		p := m.processes[m.selectedIdx]
+		if m.policy.Check(p).Verdict == protect.Confirm {
+			m.confirmFreeze = false
+			m = m.startTyping(actionFreeze)
+			break
+		}
+		return m, freezeProcess(p, freezeDurations[m.freezeChoice], m.freezer)
+	case "esc":
+		m.confirmFreeze = false
+	}
+	return m, nil
+}
+
+// startTyping opens the typed confirmation prompt for a protected
+// process or unit
+func (m Model) startTyping(action pendingAction) Model {
+	m.confirmKill = false
+	m.typing = true
+	m.typed = ""
+	m.pending = action
+	return m
+}
+
+// handleTypedKey collects the typed confirmation for a protected process
+// or unit. The action only runs if the name is typed exactly.
+func (m Model) handleTypedKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.typing = false
+		m.confirmUnit, m.confirmName = unitNone, ""
+	case tea.KeyBackspace:
+		if len(m.typed) > 0 {
+			r := []rune(m.typed)
+			m.typed = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		m.typed += " "
+	case tea.KeyRunes:
+		m.typed += string(msg.Runes)
+	case tea.KeyEnter:
+		m.typing = false
+		if m.pending == actionUnit {
+			return m.finishTypedUnit()
+		}
+		if m.selectedIdx >= len(m.processes) {
+			break
+		}
+		p := m.processes[m.selectedIdx]
+		if m.typed != p.Name {
+			m.killResult = errorStyle.Render("  Confirmation did not match, nothing was sent.")
+			break
+		}
+		if m.pending == actionFreeze {
+			return m, freezeProcess(p, freezeDurations[m.freezeChoice], m.freezer)
+		}
+		return m, killProcess(p, m.freezer, m.auditLog)
+	}
+	return m, nil
+}
+
+func refusedMessage(p process.Process, d protect.Decision) string {
+	return errorStyle.Render(fmt.Sprintf("  Refused: PID %d (%s) is protected, %s.", p.PID, truncate(p.Name, 20), d.Reason))
+}
+
+// switchScroll makes scroll the active one, dropping any pending
+// confirmation, and fetches its data
+func (m Model) switchScroll(scroll ScrollType) (tea.Model, tea.Cmd) {
+	m.confirmKill = false
+	m.confirmFreeze = false
+	m.confirmUnit, m.confirmName = unitNone, ""
+	m.killResult = ""
+	m.currentScroll = scroll
+	return m, m.scrollEnterCmd()
+}
+
 // scrollEnterCmd returns the command to run when entering a scroll
 func (m Model) scrollEnterCmd() tea.Cmd {
 	switch m.currentScroll {
 	case ScrollShuriken:
-		return fetchProcesses
+		return m.processesCmd()
 	case ScrollShadow:
-		return fetchShadow
+		return m.shadowCmd()
 	case ScrollClone:
-		return tea.Batch(fetchSysInfo, fetchCPU)
+		return tea.Batch(fetchSysInfo, m.cpuCmd())
+	case ScrollLedger:
+		return fetchLedger(m.auditLog.Path())
+	case ScrollChakra:
+		return m.memoryCmd()
+	case ScrollKunai:
+		return fetchNetwork
+	case ScrollKura:
+		return tea.Batch(fetchDisk, fetchFilesystems)
+	case ScrollClan:
+		return fetchUnits(m.unitMgr)
+	case ScrollScribe:
+		return fetchLogs(m.logPath)
+	case ScrollMirror:
+		return fetchMirror(m.controlPath)
 	}
 	return nil
 }
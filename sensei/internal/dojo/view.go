@@ -3,6 +3,7 @@ package dojo
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 const ninjaHeader = `
@@ -30,6 +31,20 @@ func (m Model) View() string {
 		b.WriteString(m.renderShadow())
 	case ScrollClone:
 		b.WriteString(m.renderClone())
+	case ScrollLedger:
+		b.WriteString(m.renderLedger())
+	case ScrollChakra:
+		b.WriteString(m.renderChakra())
+	case ScrollKunai:
+		b.WriteString(m.renderKunai())
+	case ScrollKura:
+		b.WriteString(m.renderKura())
+	case ScrollClan:
+		b.WriteString(m.renderClan())
+	case ScrollScribe:
+		b.WriteString(m.renderScribe())
+	case ScrollMirror:
+		b.WriteString(m.renderMirror())
 	}
 
 	// Error display
@@ -59,6 +74,13 @@ func (m Model) renderTabs() string {
 		{"!shuriken", ScrollShuriken},
 		{"!shadow", ScrollShadow},
 		{"!clone", ScrollClone},
+		{"!ledger", ScrollLedger},
+		{"!chakra", ScrollChakra},
+		{"!kunai", ScrollKunai},
+		{"!kura", ScrollKura},
+		{"!clan", ScrollClan},
+		{"!scribe", ScrollScribe},
+		{"!mirror", ScrollMirror},
 	}
 
 	var parts []string
@@ -78,6 +100,9 @@ func (m Model) renderStatusBar() string {
 	if m.cpuPercent >= 0 {
 		cpuStr = fmt.Sprintf("%.1f%%", m.cpuPercent)
 	}
-	status := fmt.Sprintf(" CPU: %s  |  [Tab] Switch  [q] Quit  [1/2/3] Jump ", cpuStr)
+	status := fmt.Sprintf(" CPU: %s  |  [Tab] Switch  [q] Quit  [1-9,0] Jump ", cpuStr)
+	if m.replay != nil {
+		status = " " + m.replayStatus(time.Now()) + "  |" + status
+	}
 	return statusBarStyle.Render(status)
 }
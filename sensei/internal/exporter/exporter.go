@@ -0,0 +1,277 @@
+// Package exporter serves sensei's latest readings in the Prometheus text
+// exposition format
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"system-shinobi/sensei/internal/icon"
+	"system-shinobi/sensei/internal/metrics"
+	"system-shinobi/sensei/internal/pipe"
+	"system-shinobi/sensei/internal/selfstat"
+	"system-shinobi/sensei/internal/sink"
+	"system-shinobi/sensei/internal/supervisor"
+)
+
+// MetricsPath is where the exporter serves its metrics
+const MetricsPath = "/metrics"
+
+// average is a CPU average over one of sink.Windows
+type average struct {
+	label string
+	value float64
+}
+
+// Exporter is a sink that remembers the latest reading for scrapes
+type Exporter struct {
+	probe func() supervisor.Health // nil unless sensei manages the probe
+
+	mu        sync.Mutex
+	connected bool
+	at        time.Time
+	reading   pipe.CpuReading
+	level     string
+	averages  []average
+}
+
+// New returns an exporter. probe reports the managed probe's health and
+// may be nil.
+func New(probe func() supervisor.Health) *Exporter {
+	return &Exporter{probe: probe}
+}
+
+// Update records a new reading
+func (e *Exporter) Update(s sink.Sample) {
+	var averages []average
+	for _, w := range sink.Windows {
+		if avg, ok := s.History.Average(s.Time, w.Window); ok {
+			averages = append(averages, average{w.Label, avg})
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.connected = true
+	e.at = s.Time
+	e.reading = s.Reading
+	e.level = s.Class.Name
+	e.averages = averages
+}
+
+// Disconnected drops the last reading so scrapes don't report stale values
+func (e *Exporter) Disconnected(now time.Time, idle icon.Class) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.connected = false
+	e.reading = pipe.CpuReading{}
+	e.level = ""
+	e.averages = nil
+}
+
+// ServeHTTP writes the metrics
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e.Write(w)
+}
+
+// Write writes the metrics in the Prometheus text format
+func (e *Exporter) Write(out io.Writer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	m := &metricWriter{w: out, seen: make(map[string]bool)}
+	m.gauge("shinobi_probe_connected", "Whether the probe is sending readings", boolValue(e.connected))
+	if e.probe != nil {
+		h := e.probe()
+		m.gauge("shinobi_probe_running", "Whether the managed probe process is running", boolValue(h.Running))
+		m.counter("shinobi_probe_restarts_total", "Times sensei restarted the managed probe", float64(h.Restarts))
+	}
+
+	reports := []selfstat.Report{selfstat.Snapshot("sensei")}
+	if e.connected {
+		e.writeReading(m)
+		if e.reading.Self != nil {
+			reports = append(reports, *e.reading.Self)
+		}
+	}
+	writeSelf(m, reports)
+}
+
+// writeReading writes the latest reading's metrics
+func (e *Exporter) writeReading(m *metricWriter) {
+	r := e.reading
+	m.gauge("shinobi_last_reading_timestamp_seconds", "When the latest reading arrived", float64(e.at.UnixMilli())/1000)
+	m.gauge("shinobi_cpu_percent", "CPU usage from the latest reading", r.CpuPercent)
+	for _, avg := range e.averages {
+		m.gauge("shinobi_cpu_average_percent", "Mean CPU usage over the window", avg.value, "window", avg.label)
+	}
+	m.gauge("shinobi_level", "The current load level, set to 1", 1, "level", e.level)
+
+	if mem := r.Memory; mem != nil {
+		m.gauge("shinobi_memory_total_bytes", "Total physical memory", float64(mem.Total))
+		m.gauge("shinobi_memory_used_bytes", "Used physical memory", float64(mem.Used))
+		m.gauge("shinobi_swap_total_bytes", "Total swap", float64(mem.SwapTotal))
+		m.gauge("shinobi_swap_used_bytes", "Used swap", float64(mem.SwapUsed))
+	}
+
+	if load := r.Load; load != nil {
+		m.gauge("shinobi_load_average", "System load average", load.Load1, "window", "1m")
+		m.gauge("shinobi_load_average", "System load average", load.Load5, "window", "5m")
+		m.gauge("shinobi_load_average", "System load average", load.Load15, "window", "15m")
+	}
+
+	// Samples of one metric must be adjacent, so pressure is gathered here
+	pressure := []struct {
+		resource string
+		p        *metrics.Pressure
+	}{{"memory", nil}, {"cpu", nil}, {"io", nil}}
+	if r.Memory != nil {
+		pressure[0].p = r.Memory.Pressure
+	}
+	if r.Load != nil {
+		pressure[1].p, pressure[2].p = r.Load.CPUPressure, r.Load.IOPressure
+	}
+	for _, p := range pressure {
+		if p.p != nil {
+			m.gauge("shinobi_pressure_some_avg10_percent", "Share of time some tasks stalled, 10s average", p.p.SomeAvg10, "resource", p.resource)
+		}
+	}
+
+	if net := r.Network; net != nil {
+		m.gauge("shinobi_network_receive_bytes_per_second", "Received bytes per second across interfaces", net.RxBytesPerSec)
+		m.gauge("shinobi_network_transmit_bytes_per_second", "Transmitted bytes per second across interfaces", net.TxBytesPerSec)
+	}
+
+	if power := r.Power; power != nil {
+		m.gauge("shinobi_on_ac", "Whether the machine runs on AC power", boolValue(power.OnAC))
+		if power.Battery != nil {
+			m.gauge("shinobi_battery_percent", "Battery charge", power.Battery.Percent)
+		}
+		if power.CPUTempC > 0 {
+			m.gauge("shinobi_cpu_temperature_celsius", "CPU temperature", power.CPUTempC)
+		}
+	}
+}
+
+// writeSelf writes the overhead of sensei and the probe, one metric at
+// a time across both
+func writeSelf(m *metricWriter, reports []selfstat.Report) {
+	for _, r := range reports {
+		m.counter("shinobi_self_cpu_seconds_total", "CPU time used by the process", r.CPUTime.Seconds(), "process", r.Process)
+	}
+	for _, r := range reports {
+		m.gauge("shinobi_self_resident_memory_bytes", "Resident memory of the process", float64(r.RSS), "process", r.Process)
+	}
+	for _, r := range reports {
+		m.gauge("shinobi_self_goroutines", "Goroutines in the process", float64(r.Goroutines), "process", r.Process)
+	}
+	for _, r := range reports {
+		for _, c := range r.Collections {
+			m.counter("shinobi_self_collections_total", "Collections run", float64(c.Count), "process", r.Process, "collection", c.Name)
+		}
+	}
+	for _, r := range reports {
+		for _, c := range r.Collections {
+			m.counter("shinobi_self_collection_seconds_total", "Time spent in collections", c.Total.Seconds(), "process", r.Process, "collection", c.Name)
+		}
+	}
+	for _, r := range reports {
+		for _, c := range r.Collections {
+			m.gauge("shinobi_self_collection_max_seconds", "Slowest run of the collection", c.Max.Seconds(), "process", r.Process, "collection", c.Name)
+		}
+	}
+}
+
+// metricWriter writes samples, with the HELP and TYPE lines before the
+// first sample of each metric
+type metricWriter struct {
+	w    io.Writer
+	seen map[string]bool
+}
+
+func (m *metricWriter) gauge(name, help string, value float64, labels ...string) {
+	m.sample("gauge", name, help, value, labels)
+}
+
+func (m *metricWriter) counter(name, help string, value float64, labels ...string) {
+	m.sample("counter", name, help, value, labels)
+}
+
+func (m *metricWriter) sample(kind, name, help string, value float64, labels []string) {
+	if !m.seen[name] {
+		m.seen[name] = true
+		fmt.Fprintf(m.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, "%s=\"%s\"", labels[i], labelEscaper.Replace(labels[i+1]))
+		}
+		b.WriteByte('}')
+	}
+	fmt.Fprintf(m.w, "%s %g\n", b.String(), value)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Server serves an exporter over HTTP
+type Server struct {
+	http *http.Server
+	addr string
+}
+
+// Listen starts serving e at addr, such as "127.0.0.1:9464"
+func Listen(addr string, e *Exporter) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, e)
+	s := &Server{
+		http: &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second},
+		addr: ln.Addr().String(),
+	}
+	go func() {
+		if err := s.http.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Exporter stopped", "err", err)
+		}
+	}()
+	return s, nil
+}
+
+// Addr returns the address the server listens on
+func (s *Server) Addr() string {
+	return s.addr
+}
+
+// Close stops the server. It is safe to call on a nil Server.
+func (s *Server) Close() {
+	if s == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	s.http.Shutdown(ctx)
+}
@@ -0,0 +1,121 @@
+package exporter
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"system-shinobi/sensei/internal/icon"
+	"system-shinobi/sensei/internal/metrics"
+	"system-shinobi/sensei/internal/pipe"
+	"system-shinobi/sensei/internal/selfstat"
+	"system-shinobi/sensei/internal/sink"
+	"system-shinobi/sensei/internal/supervisor"
+)
+
+func sample(now time.Time, reading pipe.CpuReading, level string) sink.Sample {
+	h := &sink.History{}
+	h.Add(now, reading.CpuPercent, reading.Memory)
+	return sink.Sample{Time: now, Reading: reading, Class: icon.Class{Name: level}, History: h}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	e := New(func() supervisor.Health { return supervisor.Health{Running: true, Restarts: 2} })
+	now := time.Unix(1700000000, 500_000_000)
+	e.Update(sample(now, pipe.CpuReading{
+		CpuPercent: 42.5,
+		Memory:     &metrics.Memory{Total: 1024, Used: 512, Pressure: &metrics.Pressure{SomeAvg10: 1}},
+		Load: &metrics.Load{Load1: 1.5, Load5: 1, Load15: 0.5,
+			CPUPressure: &metrics.Pressure{SomeAvg10: 3}},
+		Self: &selfstat.Report{Process: "probe", CPUTime: 2 * time.Second, RSS: 4096,
+			Collections: []selfstat.Collection{{Name: "cpu", Count: 3, Total: 30 * time.Millisecond}}},
+	}, `Hot "zone"`))
+
+	var b strings.Builder
+	e.Write(&b)
+	out := b.String()
+	for _, want := range []string{
+		"# TYPE shinobi_probe_connected gauge\nshinobi_probe_connected 1\n",
+		"# TYPE shinobi_probe_restarts_total counter\nshinobi_probe_restarts_total 2\n",
+		"shinobi_last_reading_timestamp_seconds 1.7000000005e+09\n",
+		"shinobi_cpu_percent 42.5\n",
+		"shinobi_cpu_average_percent{window=\"1m\"} 42.5\n",
+		"shinobi_level{level=\"Hot \\\"zone\\\"\"} 1\n",
+		"shinobi_memory_used_bytes 512\n",
+		"shinobi_load_average{window=\"5m\"} 1\n",
+		"shinobi_pressure_some_avg10_percent{resource=\"cpu\"} 3\n",
+		"shinobi_self_cpu_seconds_total{process=\"probe\"} 2\n",
+		"shinobi_self_resident_memory_bytes{process=\"probe\"} 4096\n",
+		"shinobi_self_collections_total{process=\"probe\",collection=\"cpu\"} 3\n",
+		"shinobi_self_collection_seconds_total{process=\"probe\",collection=\"cpu\"} 0.03\n",
+		"shinobi_self_goroutines{process=\"sensei\"}",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output missing %q:\n%s", want, out)
+		}
+	}
+	for _, name := range []string{"shinobi_load_average", "shinobi_pressure_some_avg10_percent", "shinobi_self_cpu_seconds_total"} {
+		if n := strings.Count(out, "# TYPE "+name+" "); n != 1 {
+			t.Errorf("Expected one TYPE line for %s, got %d", name, n)
+		}
+		if !adjacent(out, name) {
+			t.Errorf("Expected the samples of %s to be adjacent:\n%s", name, out)
+		}
+	}
+}
+
+// adjacent reports whether every sample line of the named metric sits
+// in one unbroken run, as the text format requires
+func adjacent(out, name string) bool {
+	first, last, count := -1, -1, 0
+	for i, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, name+"{") || strings.HasPrefix(line, name+" ") {
+			if first < 0 {
+				first = i
+			}
+			last = i
+			count++
+		}
+	}
+	return count == 0 || last-first+1 == count
+}
+
+func TestWriteDisconnected(t *testing.T) {
+	e := New(nil)
+	e.Update(sample(time.Now(), pipe.CpuReading{CpuPercent: 10}, "Idle"))
+	e.Disconnected(time.Now(), icon.Class{})
+
+	var b strings.Builder
+	e.Write(&b)
+	out := b.String()
+	if !strings.Contains(out, "shinobi_probe_connected 0\n") {
+		t.Errorf("Expected a disconnected probe:\n%s", out)
+	}
+	if strings.Contains(out, "shinobi_cpu_percent") || strings.Contains(out, "shinobi_probe_restarts_total") {
+		t.Errorf("Expected no reading or supervisor metrics:\n%s", out)
+	}
+}
+
+func TestListenServesMetrics(t *testing.T) {
+	e := New(nil)
+	server, err := Listen("127.0.0.1:0", e)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer server.Close()
+
+	resp, err := http.Get("http://" + server.Addr() + MetricsPath)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", resp.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(string(body), "shinobi_probe_connected 0") {
+		t.Errorf("Unexpected body:\n%s", body)
+	}
+}
@@ -0,0 +1,136 @@
+package icon
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+
+	"system-shinobi/sensei/internal/config"
+)
+
+// Level is one band of a Classifier
+type Level struct {
+	Name  string
+	From  float64 // percentage where the level starts
+	Color color.NRGBA
+	State IconState // icon drawn while in this level
+}
+
+// defaultLevels are the built-in ninja levels
+var defaultLevels = []Level{
+	{Name: "Idle", From: 0, Color: color.NRGBA{80, 80, 80, 255}, State: StateIdle},       // Gray
+	{Name: "Low", From: 15, Color: color.NRGBA{76, 175, 80, 255}, State: StateLow},       // Green
+	{Name: "Medium", From: 40, Color: color.NRGBA{255, 193, 7, 255}, State: StateMedium}, // Amber
+	{Name: "High", From: 70, Color: color.NRGBA{244, 67, 54, 255}, State: StateHigh},     // Red
+}
+
+// Class is the result of classifying a percentage
+type Class struct {
+	Level int // index into the classifier's levels
+	Name  string
+	State IconState
+	Color color.NRGBA // the level's color, or the interpolated one in gradient mode
+}
+
+// Classifier maps a percentage onto ordered levels and their colors. It is
+// the single model behind the tray icon, its menu and the dojo's colors.
+type Classifier struct {
+	levels   []Level
+	gradient bool
+	custom   bool // levels came from config rather than defaultLevels
+}
+
+// DefaultClassifier returns the built-in four-level classifier
+func DefaultClassifier() *Classifier {
+	return &Classifier{levels: defaultLevels}
+}
+
+// NewClassifier builds a classifier from config; no levels means the built-in ones
+func NewClassifier(cfg config.Classify) (*Classifier, error) {
+	if len(cfg.Levels) == 0 {
+		return &Classifier{levels: defaultLevels, gradient: cfg.Gradient}, nil
+	}
+	if cfg.Levels[0].From != 0 {
+		return nil, errors.New("the first level must start at 0")
+	}
+
+	levels := make([]Level, len(cfg.Levels))
+	for i, spec := range cfg.Levels {
+		if i > 0 && spec.From <= cfg.Levels[i-1].From {
+			return nil, fmt.Errorf("level %d starts at %g, not above the previous level", i+1, spec.From)
+		}
+		c, err := parseColor(spec.Color)
+		if err != nil || c.A == 0 {
+			return nil, fmt.Errorf("level %d: unsupported color %q", i+1, spec.Color)
+		}
+		l := Level{Name: spec.Name, From: spec.From, Color: c, State: spreadState(i, len(cfg.Levels))}
+		if l.Name == "" {
+			l.Name = fmt.Sprintf("Level %d", i+1)
+		}
+		if spec.Icon != "" {
+			if l.State, err = parseState(spec.Icon); err != nil {
+				return nil, fmt.Errorf("level %d: %w", i+1, err)
+			}
+		}
+		levels[i] = l
+	}
+	return &Classifier{levels: levels, gradient: cfg.Gradient, custom: true}, nil
+}
+
+// spreadState picks an icon for level i of n, spreading the levels evenly
+// from Idle to High
+func spreadState(i, n int) IconState {
+	if n < 2 {
+		return StateIdle
+	}
+	return IconState(math.Round(float64(i) * float64(StateHigh) / float64(n-1)))
+}
+
+// Levels returns the classifier's levels in ascending order
+func (c *Classifier) Levels() []Level {
+	return c.levels
+}
+
+// Tinted reports whether the classifier's colors should override an icon
+// theme's own state colors: true for configured levels or gradient mode
+func (c *Classifier) Tinted() bool {
+	return c.custom || c.gradient
+}
+
+// Classify places a percentage in its level
+func (c *Classifier) Classify(percent float64) Class {
+	i := c.level(percent)
+	l := c.levels[i]
+	return Class{Level: i, Name: l.Name, State: l.State, Color: c.Color(percent)}
+}
+
+// Color returns the color for a percentage: its level's color, or in
+// gradient mode a blend between the surrounding levels' colors
+func (c *Classifier) Color(percent float64) color.NRGBA {
+	i := c.level(percent)
+	if !c.gradient || i == len(c.levels)-1 {
+		return c.levels[i].Color
+	}
+	lo, hi := c.levels[i], c.levels[i+1]
+	return lerp(lo.Color, hi.Color, (percent-lo.From)/(hi.From-lo.From))
+}
+
+func (c *Classifier) level(percent float64) int {
+	i := 0
+	for i+1 < len(c.levels) && percent >= c.levels[i+1].From {
+		i++
+	}
+	return i
+}
+
+func lerp(a, b color.NRGBA, t float64) color.NRGBA {
+	t = math.Max(0, math.Min(t, 1))
+	mix := func(x, y uint8) uint8 { return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t)) }
+	return color.NRGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), mix(a.A, b.A)}
+}
+
+// Hex formats a color as #rrggbb
+func Hex(c color.NRGBA) string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
@@ -0,0 +1,120 @@
+package icon
+
+import (
+	"image/color"
+	"testing"
+
+	"system-shinobi/sensei/internal/config"
+)
+
+func TestDefaultClassifierMatchesIcons(t *testing.T) {
+	c := DefaultClassifier()
+	for _, tt := range []struct {
+		percent float64
+		name    string
+		state   IconState
+	}{
+		{0, "Idle", StateIdle},
+		{15, "Low", StateLow},
+		{69.9, "Medium", StateMedium},
+		{100, "High", StateHigh},
+	} {
+		class := c.Classify(tt.percent)
+		if class.Name != tt.name || class.State != tt.state {
+			t.Errorf("Classify(%v) = %+v, expected %s/%v", tt.percent, class, tt.name, tt.state)
+		}
+		if class.Color != getColorForState(tt.state, false) {
+			t.Errorf("Classify(%v) color %v drifted from the icon's %v", tt.percent, class.Color, getColorForState(tt.state, false))
+		}
+	}
+}
+
+func TestCustomLevels(t *testing.T) {
+	c, err := NewClassifier(config.Classify{Levels: []config.LevelSpec{
+		{Name: "Calm", From: 0, Color: "#000"},
+		{Name: "Busy", From: 50, Color: "#888888"},
+		{Name: "Hot", From: 90, Color: "#fff", Icon: "medium"},
+	}})
+	if err != nil {
+		t.Fatalf("NewClassifier failed: %v", err)
+	}
+
+	tests := []struct {
+		percent float64
+		level   int
+		state   IconState
+	}{
+		{10, 0, StateIdle},
+		{50, 1, StateMedium}, // middle of three levels spreads to Medium
+		{95, 2, StateMedium}, // explicit icon
+	}
+	for _, tt := range tests {
+		class := c.Classify(tt.percent)
+		if class.Level != tt.level || class.State != tt.state {
+			t.Errorf("Classify(%v) = %+v, expected level %d with %v", tt.percent, class, tt.level, tt.state)
+		}
+	}
+	if got := c.Color(60); got != (color.NRGBA{136, 136, 136, 255}) {
+		t.Errorf("Stepped mode should use the level color, got %v", got)
+	}
+}
+
+func TestGradient(t *testing.T) {
+	c, err := NewClassifier(config.Classify{Gradient: true, Levels: []config.LevelSpec{
+		{From: 0, Color: "#000000"},
+		{From: 100, Color: "#ffffff"},
+	}})
+	if err != nil {
+		t.Fatalf("NewClassifier failed: %v", err)
+	}
+	if got := c.Color(50); got != (color.NRGBA{128, 128, 128, 255}) {
+		t.Errorf("Color(50) = %v, expected mid gray", got)
+	}
+	if got := c.Classify(25).Color; got != (color.NRGBA{64, 64, 64, 255}) {
+		t.Errorf("Classify(25).Color = %v, expected the interpolated color", got)
+	}
+	if got := c.Color(150); got != (color.NRGBA{255, 255, 255, 255}) {
+		t.Errorf("Color past the last level should clamp, got %v", got)
+	}
+	if c.Classify(0).Name != "Level 1" {
+		t.Errorf("Expected a default level name, got %q", c.Classify(0).Name)
+	}
+}
+
+func TestNewClassifierValidation(t *testing.T) {
+	tests := map[string][]config.LevelSpec{
+		"not from zero": {{From: 10, Color: "#000"}},
+		"not ascending": {{From: 0, Color: "#000"}, {From: 50, Color: "#111"}, {From: 50, Color: "#222"}},
+		"bad color":     {{From: 0, Color: "teal"}},
+		"no color":      {{From: 0}},
+		"bad icon":      {{From: 0, Color: "#000", Icon: "panic"}},
+	}
+	for name, levels := range tests {
+		if _, err := NewClassifier(config.Classify{Levels: levels}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestInputPercents(t *testing.T) {
+	if got := LoadPercent(4, 8); got != 40 {
+		t.Errorf("LoadPercent(4, 8) = %v, expected 40", got)
+	}
+	if got := LoadPercent(100, 4); got != 100 {
+		t.Errorf("LoadPercent should clamp at 100, got %v", got)
+	}
+	if got := PressurePercent(25); got != 70 {
+		t.Errorf("PressurePercent(25) = %v, expected 70", got)
+	}
+}
+
+func TestTinted(t *testing.T) {
+	if DefaultClassifier().Tinted() {
+		t.Error("The built-in levels should leave theme colors alone")
+	}
+	gradient, _ := NewClassifier(config.Classify{Gradient: true})
+	custom, _ := NewClassifier(config.Classify{Levels: []config.LevelSpec{{From: 0, Color: "#000"}}})
+	if !gradient.Tinted() || !custom.Tinted() {
+		t.Error("Gradient mode and custom levels should tint the icon")
+	}
+}
@@ -2,23 +2,97 @@ package icon
 
 import (
 	"bytes"
+	"fmt"
 	"image"
 	"image/color"
 	"image/png"
+	"math"
+	"strconv"
 )
 
 const (
-	iconSize = 22
+	// DefaultSize is the logical icon size used when Options.Size is zero
+	DefaultSize = 22
+	MinSize     = 16
+	MaxSize     = 64
+
+	// AnimationFrames is the length of the frame sequence for animated states
+	AnimationFrames = 4
+
+	// designSize is the grid the ninja is drawn on before scaling
+	designSize = 22
+)
+
+// Overlay selects the value indicator drawn on top of the ninja
+type Overlay int
+
+const (
+	OverlayNone   Overlay = iota
+	OverlayBar            // CPU% bar along the bottom edge
+	OverlayNumber         // CPU% digits in the bottom-right corner
 )
 
+// ParseOverlay validates an overlay name; empty means OverlayNone
+func ParseOverlay(s string) (Overlay, error) {
+	switch s {
+	case "", "none":
+		return OverlayNone, nil
+	case "bar":
+		return OverlayBar, nil
+	case "number":
+		return OverlayNumber, nil
+	}
+	return OverlayNone, fmt.Errorf("unknown icon overlay %q (want none, bar or number)", s)
+}
+
+// Options controls how an icon is rendered
+type Options struct {
+	Size     int         // logical size in points, 16-64; zero means DefaultSize
+	Scale    int         // pixel density, 1 or 2 (@2x); zero means 1
+	Template bool        // black on transparent for macOS light/dark mode
+	Overlay  Overlay     // value indicator to draw
+	Percent  float64     // value shown by the overlay, 0-100
+	Frame    int         // animation frame for animated states, wrapping; see Frames
+	Theme    Theme       // icon set to draw; nil means Ninja
+	Tint     color.NRGBA // overrides the theme's state color when opaque, e.g. a Classifier color
+}
+
+// Validate checks the size and scale are in range
+func (o Options) Validate() error {
+	if o.Size != 0 && (o.Size < MinSize || o.Size > MaxSize) {
+		return fmt.Errorf("icon size %d out of range (%d-%d)", o.Size, MinSize, MaxSize)
+	}
+	if o.Scale < 0 || o.Scale > 2 {
+		return fmt.Errorf("icon scale %d unsupported (want 1 or 2)", o.Scale)
+	}
+	return nil
+}
+
+// Pixels returns the width and height of the rendered image
+func (o Options) Pixels() int {
+	size, scale := o.Size, o.Scale
+	if size == 0 {
+		size = DefaultSize
+	}
+	if scale == 0 {
+		scale = 1
+	}
+	return size * scale
+}
+
+// Animated reports whether a state has a frame sequence
+func Animated(state IconState) bool {
+	return state == StateHigh
+}
+
 // Generate creates a 22x22 PNG icon for the given state
 func Generate(state IconState) []byte {
-	return generateIcon(state, false)
+	return generateIcon(state, Options{})
 }
 
 // GenerateTemplate creates a template icon (black on transparent) for macOS
 func GenerateTemplate(state IconState) []byte {
-	return generateIcon(state, true)
+	return generateIcon(state, Options{Template: true})
 }
 
 // GenerateAll pre-generates all four icon states
@@ -31,14 +105,63 @@ func GenerateAll() map[IconState][]byte {
 	return icons
 }
 
-func generateIcon(state IconState, template bool) []byte {
-	img := image.NewNRGBA(image.Rect(0, 0, iconSize, iconSize))
+// Render creates a PNG icon for the given state with the given options
+func Render(state IconState, opts Options) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	data := generateIcon(state, opts)
+	if data == nil {
+		return nil, fmt.Errorf("failed to encode %s icon", state)
+	}
+	return data, nil
+}
 
-	// Get the color for this state
-	ninjaColor := getColorForState(state, template)
+// Frames renders the animation sequence for a state. States that don't
+// animate return a single frame.
+func Frames(state IconState, opts Options) ([][]byte, error) {
+	count := 1
+	if Animated(state) {
+		count = AnimationFrames
+	}
+	frames := make([][]byte, count)
+	for i := range frames {
+		opts.Frame = i
+		data, err := Render(state, opts)
+		if err != nil {
+			return nil, err
+		}
+		frames[i] = data
+	}
+	return frames, nil
+}
 
-	// Draw ninja face based on state
-	drawNinjaFace(img, ninjaColor, state)
+func generateIcon(state IconState, opts Options) []byte {
+	size := opts.Pixels()
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	theme := opts.Theme
+	if theme == nil {
+		theme = Ninja
+	}
+
+	c := theme.Color(state, opts.Template)
+	if !opts.Template && opts.Tint.A != 0 {
+		c = opts.Tint
+	}
+
+	// Draw the theme's image for this state, pulsing it when animated
+	theme.draw(img, state, c, opts.Template)
+	if Animated(state) {
+		// Frames loop, counting back from the end when negative
+		fade(img, pulse[(opts.Frame%AnimationFrames+AnimationFrames)%AnimationFrames])
+	}
+
+	switch opts.Overlay {
+	case OverlayBar:
+		drawBarOverlay(newGrid(img), c, opts.Percent)
+	case OverlayNumber:
+		drawNumberOverlay(newGrid(img), c, opts.Percent)
+	}
 
 	// Encode to PNG
 	var buf bytes.Buffer
@@ -48,97 +171,187 @@ func generateIcon(state IconState, template bool) []byte {
 	return buf.Bytes()
 }
 
+// pulse is the icon opacity for each frame of the High animation
+var pulse = [AnimationFrames]uint8{255, 205, 155, 205}
+
 func getColorForState(state IconState, template bool) color.NRGBA {
 	if template {
 		// Template icons are black on transparent for macOS light/dark mode
 		return color.NRGBA{0, 0, 0, 255}
 	}
+	if state >= StateIdle && state <= StateHigh {
+		return defaultLevels[state].Color
+	}
+	return color.NRGBA{128, 128, 128, 255}
+}
 
-	switch state {
-	case StateIdle:
-		return color.NRGBA{80, 80, 80, 255} // Gray
-	case StateLow:
-		return color.NRGBA{76, 175, 80, 255} // Green
-	case StateMedium:
-		return color.NRGBA{255, 193, 7, 255} // Amber
-	case StateHigh:
-		return color.NRGBA{244, 67, 54, 255} // Red
-	default:
-		return color.NRGBA{128, 128, 128, 255}
+// grid maps the 22-unit design grid onto an image of any size, so the
+// ninja is drawn at full resolution rather than upscaled
+type grid struct {
+	img *image.NRGBA
+	k   float64 // pixels per design unit
+}
+
+func newGrid(img *image.NRGBA) grid {
+	return grid{img: img, k: float64(img.Rect.Dx()) / designSize}
+}
+
+// edge converts a design-grid cell boundary to a pixel boundary
+func (g grid) edge(v float64) int {
+	return int(math.Round(v * g.k))
+}
+
+// point converts the center of a design-grid cell to a pixel
+func (g grid) point(v float64) float64 {
+	return (v+0.5)*g.k - 0.5
+}
+
+// width is the pixel thickness of a one-unit stroke
+func (g grid) width() int {
+	return max(1, int(math.Round(g.k)))
+}
+
+// rect fills design cells [x0,x1) x [y0,y1), always covering at least one pixel row
+func (g grid) rect(x0, y0, x1, y1 float64, c color.NRGBA) {
+	px0, px1 := g.edge(x0), g.edge(x1)
+	py0, py1 := g.edge(y0), max(g.edge(y1), g.edge(y0)+1)
+	for y := py0; y < py1; y++ {
+		drawHorizontalLine(g.img, px0, y, px1-px0, c)
+	}
+}
+
+// rectInHead fills design cells [x0,x1) x [y0,y1) clipped to the head circle
+func (g grid) rectInHead(x0, y0, x1, y1 float64, c color.NRGBA) {
+	cx, r := g.point(11), g.point(9)-g.point(0)
+	for y := g.edge(y0); y < g.edge(y1); y++ {
+		for x := g.edge(x0); x < g.edge(x1); x++ {
+			dx := float64(x) - cx
+			dy := float64(y) - cx
+			if dx*dx+dy*dy <= r*r {
+				g.img.Set(x, y, c)
+			}
+		}
 	}
 }
 
-func drawNinjaFace(img *image.NRGBA, c color.NRGBA, state IconState) {
+// circle fills a circle centered on design cell (cx, cy)
+func (g grid) circle(cx, cy, r float64, c color.NRGBA) {
+	drawFilledCircle(g.img, g.point(cx), g.point(cy), g.point(r)-g.point(0), c)
+}
+
+// line strokes a one-unit line between the centers of two design cells
+func (g grid) line(x1, y1, x2, y2 float64, c color.NRGBA) {
+	p := func(v float64) int { return int(math.Round(g.point(v))) }
+	drawAngledLine(g.img, p(x1), p(y1), p(x2), p(y2), g.width(), c)
+}
+
+func drawNinjaFace(g grid, c color.NRGBA, state IconState) {
 	// Draw ninja head: circle centered at (11, 11) with radius 9
-	drawFilledCircle(img, 11, 11, 9, c)
+	g.circle(11, 11, 9, c)
 
 	// Draw mask covering lower half (creates the ninja look)
 	// Mask is a filled rectangle from y=12 to bottom
-	for y := 12; y < 20; y++ {
-		for x := 4; x < 18; x++ {
-			// Only draw if within the head circle
-			dx := x - 11
-			dy := y - 11
-			if dx*dx+dy*dy <= 81 { // radius 9 squared
-				img.Set(x, y, c)
-			}
-		}
-	}
+	g.rectInHead(4, 12, 18, 20, c)
 
 	// Cut out transparent area for the face opening (forehead/eyes area)
 	transparent := color.NRGBA{0, 0, 0, 0}
 	// Clear a horizontal band for the eyes (y = 8-11)
-	for y := 8; y <= 11; y++ {
-		for x := 5; x < 17; x++ {
-			dx := x - 11
-			dy := y - 11
-			if dx*dx+dy*dy <= 81 { // Only within head circle
-				img.Set(x, y, transparent)
-			}
-		}
-	}
+	g.rectInHead(5, 8, 17, 12, transparent)
 
 	// Draw different eye expressions based on state
 	switch state {
 	case StateIdle:
 		// Chill/relaxed: closed eyes (horizontal lines)
-		drawHorizontalLine(img, 6, 10, 4, c)  // Left eye
-		drawHorizontalLine(img, 12, 10, 4, c) // Right eye
+		g.rect(6, 10, 10, 11, c)  // Left eye
+		g.rect(12, 10, 16, 11, c) // Right eye
 
 	case StateLow:
 		// Alert: eyes dilate (small dots)
-		drawFilledCircle(img, 8, 10, 1, c)  // Left eye
-		drawFilledCircle(img, 14, 10, 1, c) // Right eye
+		g.circle(8, 10, 1, c)  // Left eye
+		g.circle(14, 10, 1, c) // Right eye
 
 	case StateMedium:
 		// Ready: normal eyes + headband
-		drawFilledCircle(img, 8, 10, 1, c)  // Left eye
-		drawFilledCircle(img, 14, 10, 1, c) // Right eye
-		// Headband across forehead
-		drawHorizontalLine(img, 5, 6, 12, c)
-		drawHorizontalLine(img, 5, 7, 12, c) // Make it thicker
+		g.circle(8, 10, 1, c)  // Left eye
+		g.circle(14, 10, 1, c) // Right eye
+		// Headband across forehead, two units thick
+		g.rect(5, 6, 17, 8, c)
 
 	case StateHigh:
 		// Angry: sharp angled eyes + headband
 		// Left eye: angled up-right
-		drawAngledLine(img, 6, 11, 10, 9, c)
+		g.line(6, 11, 10, 9, c)
 		// Right eye: angled up-left
-		drawAngledLine(img, 12, 9, 16, 11, c)
+		g.line(12, 9, 16, 11, c)
 		// Thick headband
-		drawHorizontalLine(img, 5, 6, 12, c)
-		drawHorizontalLine(img, 5, 7, 12, c)
+		g.rect(5, 6, 17, 8, c)
 	}
 }
 
+// drawBarOverlay draws a percentage bar along the bottom two rows, over a
+// faint track and separated from the mask by a transparent gap
+func drawBarOverlay(g grid, c color.NRGBA, percent float64) {
+	frac := math.Max(0, math.Min(percent, 100)) / 100
+	track := c
+	track.A = 70
+
+	g.rect(0, 19, designSize, 20, color.NRGBA{})
+	g.rect(1, 20, designSize-1, designSize, track)
+	if fill := (designSize - 2) * frac; fill > 0 {
+		g.rect(1, 20, 1+fill, designSize, c)
+	}
+}
+
+// digitFont is a 3x5 bitmap font for the number overlay
+var digitFont = [10][5]string{
+	{"###", "#.#", "#.#", "#.#", "###"},
+	{".#.", "##.", ".#.", ".#.", "###"},
+	{"###", "..#", "###", "#..", "###"},
+	{"###", "..#", ".##", "..#", "###"},
+	{"#.#", "#.#", "###", "..#", "..#"},
+	{"###", "#..", "###", "..#", "###"},
+	{"###", "#..", "###", "#.#", "###"},
+	{"###", "..#", ".#.", ".#.", ".#."},
+	{"###", "#.#", "###", "#.#", "###"},
+	{"###", "#.#", "###", "..#", "###"},
+}
+
+// drawNumberOverlay prints the rounded percentage in the bottom-right
+// corner on a transparent backing so it reads over the mask
+func drawNumberOverlay(g grid, c color.NRGBA, percent float64) {
+	text := strconv.Itoa(int(math.Round(math.Max(0, math.Min(percent, 100)))))
+	width := float64(4*len(text) - 1)
+	x0, y0 := designSize-width, float64(designSize-5)
+
+	g.rect(x0-1, y0-1, designSize, designSize, color.NRGBA{})
+	for i, ch := range text {
+		glyph := digitFont[ch-'0']
+		left := x0 + float64(4*i)
+		for row, bits := range glyph {
+			for col, bit := range bits {
+				if bit == '#' {
+					x, y := left+float64(col), y0+float64(row)
+					g.rect(x, y, x+1, y+1, c)
+				}
+			}
+		}
+	}
+}
+
+func inBounds(img *image.NRGBA, x, y int) bool {
+	return image.Pt(x, y).In(img.Rect)
+}
+
 func drawHorizontalLine(img *image.NRGBA, x, y, length int, c color.NRGBA) {
 	for i := 0; i < length; i++ {
-		if x+i >= 0 && x+i < iconSize && y >= 0 && y < iconSize {
+		if inBounds(img, x+i, y) {
 			img.Set(x+i, y, c)
 		}
 	}
 }
 
-func drawAngledLine(img *image.NRGBA, x1, y1, x2, y2 int, c color.NRGBA) {
+// drawAngledLine draws a line width pixels thick
+func drawAngledLine(img *image.NRGBA, x1, y1, x2, y2, width int, c color.NRGBA) {
 	// Bresenham's line algorithm for drawing angled lines
 	dx := x2 - x1
 	dy := y2 - y1
@@ -160,10 +373,11 @@ func drawAngledLine(img *image.NRGBA, x1, y1, x2, y2 int, c color.NRGBA) {
 
 	err := dx - dy
 	x, y := x1, y1
+	off := (width - 1) / 2
 
 	for {
-		if x >= 0 && x < iconSize && y >= 0 && y < iconSize {
-			img.Set(x, y, c)
+		for py := y - off; py < y-off+width; py++ {
+			drawHorizontalLine(img, x-off, py, width, c)
 		}
 
 		if x == x2 && y == y2 {
@@ -182,13 +396,13 @@ func drawAngledLine(img *image.NRGBA, x1, y1, x2, y2 int, c color.NRGBA) {
 	}
 }
 
-func drawFilledCircle(img *image.NRGBA, cx, cy, r int, c color.NRGBA) {
-	for y := cy - r; y <= cy+r; y++ {
-		for x := cx - r; x <= cx+r; x++ {
-			dx := x - cx
-			dy := y - cy
+func drawFilledCircle(img *image.NRGBA, cx, cy, r float64, c color.NRGBA) {
+	for y := int(math.Floor(cy - r)); y <= int(math.Ceil(cy+r)); y++ {
+		for x := int(math.Floor(cx - r)); x <= int(math.Ceil(cx+r)); x++ {
+			dx := float64(x) - cx
+			dy := float64(y) - cy
 			if dx*dx+dy*dy <= r*r {
-				if x >= 0 && x < iconSize && y >= 0 && y < iconSize {
+				if inBounds(img, x, y) {
 					img.Set(x, y, c)
 				}
 			}
@@ -221,11 +435,7 @@ func drawFilledTriangle(img *image.NRGBA, x1, y1, x2, y2, x3, y3 int, c color.NR
 		if len(intersections) >= 2 {
 			xMin := min(intersections[0], intersections[1])
 			xMax := max(intersections[0], intersections[1])
-			for x := xMin; x <= xMax; x++ {
-				if x >= 0 && x < iconSize && y >= 0 && y < iconSize {
-					img.Set(x, y, c)
-				}
-			}
+			drawHorizontalLine(img, xMin, y, xMax-xMin+1, c)
 		}
 	}
 }
@@ -248,4 +458,3 @@ func lineIntersectY(x1, y1, x2, y2, y int) (int, bool) {
 	x := float64(x1) + t*float64(x2-x1)
 	return int(x + 0.5), true
 }
-
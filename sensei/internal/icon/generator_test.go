@@ -72,3 +72,118 @@ func TestDistinctIcons(t *testing.T) {
 		}
 	}
 }
+
+func decodeSize(t *testing.T, data []byte) int {
+	t.Helper()
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+	if img.Bounds().Dx() != img.Bounds().Dy() {
+		t.Fatalf("Expected a square image, got %v", img.Bounds())
+	}
+	return img.Bounds().Dx()
+}
+
+func TestRenderSizes(t *testing.T) {
+	tests := []struct {
+		opts     Options
+		expected int
+	}{
+		{Options{}, 22},
+		{Options{Size: 16}, 16},
+		{Options{Size: 32, Scale: 2}, 64},
+		{Options{Size: 64, Scale: 2}, 128},
+	}
+
+	for _, tt := range tests {
+		data, err := Render(StateMedium, tt.opts)
+		if err != nil {
+			t.Fatalf("Render(%+v) failed: %v", tt.opts, err)
+		}
+		if got := decodeSize(t, data); got != tt.expected {
+			t.Errorf("Render(%+v) = %dpx, expected %dpx", tt.opts, got, tt.expected)
+		}
+	}
+}
+
+func TestRenderRejectsBadSize(t *testing.T) {
+	for _, opts := range []Options{{Size: 8}, {Size: 65}, {Scale: 3}} {
+		if _, err := Render(StateIdle, opts); err == nil {
+			t.Errorf("Expected Render(%+v) to fail", opts)
+		}
+	}
+}
+
+func TestRenderDefaultMatchesGenerate(t *testing.T) {
+	data, err := Render(StateHigh, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, Generate(StateHigh)) {
+		t.Error("Render with default options should match Generate")
+	}
+}
+
+func TestOverlays(t *testing.T) {
+	plain, _ := Render(StateLow, Options{})
+	for _, overlay := range []Overlay{OverlayBar, OverlayNumber} {
+		low, _ := Render(StateLow, Options{Overlay: overlay, Percent: 20})
+		high, _ := Render(StateLow, Options{Overlay: overlay, Percent: 90})
+		if bytes.Equal(plain, low) {
+			t.Errorf("Overlay %v did not change the icon", overlay)
+		}
+		if bytes.Equal(low, high) {
+			t.Errorf("Overlay %v shows the same icon for 20%% and 90%%", overlay)
+		}
+	}
+}
+
+func TestParseOverlay(t *testing.T) {
+	for s, expected := range map[string]Overlay{"": OverlayNone, "none": OverlayNone, "bar": OverlayBar, "number": OverlayNumber} {
+		got, err := ParseOverlay(s)
+		if err != nil || got != expected {
+			t.Errorf("ParseOverlay(%q) = %v, %v", s, got, err)
+		}
+	}
+	if _, err := ParseOverlay("dial"); err == nil {
+		t.Error("Expected an unknown overlay to fail")
+	}
+}
+
+func TestFrames(t *testing.T) {
+	frames, err := Frames(StateHigh, Options{Size: 32, Scale: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != AnimationFrames {
+		t.Fatalf("Expected %d frames for High, got %d", AnimationFrames, len(frames))
+	}
+	if bytes.Equal(frames[0], frames[1]) {
+		t.Error("Expected consecutive animation frames to differ")
+	}
+	if decodeSize(t, frames[1]) != 64 {
+		t.Error("Frames should honor the requested size")
+	}
+
+	still, err := Frames(StateIdle, Options{})
+	if err != nil || len(still) != 1 {
+		t.Errorf("Expected a single frame for Idle, got %d (%v)", len(still), err)
+	}
+}
+
+func TestRenderWrapsFrame(t *testing.T) {
+	frames, err := Frames(StateHigh, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for frame, want := range map[int]int{-1: AnimationFrames - 1, AnimationFrames: 0} {
+		got, err := Render(StateHigh, Options{Frame: frame})
+		if err != nil {
+			t.Fatalf("Render frame %d failed: %v", frame, err)
+		}
+		if !bytes.Equal(got, frames[want]) {
+			t.Errorf("Frame %d should match frame %d", frame, want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package icon
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"system-shinobi/sensei/internal/config"
+)
+
+// Smoother sits between raw readings and a Classifier so the tray doesn't
+// flap around a level boundary. It averages the input (EMA or a sliding
+// window), needs a margin past a boundary before changing level, and holds
+// each level for a minimum dwell time. It is not safe for concurrent use.
+type Smoother struct {
+	classifier *Classifier
+	method     string
+	alpha      float64
+	window     int
+	dwell      time.Duration
+	up, down   float64
+
+	samples []float64 // window mode: the most recent readings
+	value   float64   // the smoothed percentage
+	level   int
+	since   time.Time // when the current level was entered
+	started bool
+}
+
+// NewSmoother validates the smoothing config and wraps classifier with it
+func NewSmoother(classifier *Classifier, cfg config.Smoothing) (*Smoother, error) {
+	s := &Smoother{
+		classifier: classifier,
+		method:     cfg.Method,
+		alpha:      cfg.Alpha,
+		window:     cfg.Window,
+		dwell:      cfg.Dwell.Duration,
+		up:         cfg.UpMargin,
+		down:       cfg.DownMargin,
+	}
+	switch s.method {
+	case "", "ema":
+		s.method = "ema"
+		if s.alpha <= 0 || s.alpha > 1 {
+			return nil, fmt.Errorf("smoothing alpha %g must be in (0, 1]", s.alpha)
+		}
+	case "window":
+		if s.window < 1 {
+			return nil, fmt.Errorf("smoothing window %d must be at least 1", s.window)
+		}
+	case "none":
+	default:
+		return nil, fmt.Errorf("unknown smoothing method %q (want ema, window or none)", s.method)
+	}
+	if s.dwell < 0 || s.up < 0 || s.down < 0 {
+		return nil, fmt.Errorf("smoothing dwell and margins must not be negative")
+	}
+	return s, nil
+}
+
+// Update feeds one reading taken at now and returns the level to show
+func (s *Smoother) Update(now time.Time, percent float64) Class {
+	s.value = s.average(percent)
+	levels := s.classifier.Levels()
+
+	if !s.started {
+		s.started = true
+		s.level = s.classifier.level(s.value)
+		s.since = now
+		return s.class()
+	}
+
+	target := s.level
+	for target+1 < len(levels) && s.value >= levels[target+1].From+s.up {
+		target++
+	}
+	for target > 0 && s.value < levels[target].From-s.down {
+		target--
+	}
+	if target != s.level && now.Sub(s.since) >= s.dwell {
+		s.level = target
+		s.since = now
+	}
+	return s.class()
+}
+
+// Value returns the current smoothed percentage
+func (s *Smoother) Value() float64 {
+	return s.value
+}
+
+// Reset forgets all history, e.g. after the probe disconnects
+func (s *Smoother) Reset() {
+	s.samples = s.samples[:0]
+	s.value, s.level, s.started = 0, 0, false
+}
+
+func (s *Smoother) average(percent float64) float64 {
+	switch s.method {
+	case "ema":
+		if !s.started {
+			return percent
+		}
+		return s.alpha*percent + (1-s.alpha)*s.value
+	case "window":
+		s.samples = append(s.samples, percent)
+		if len(s.samples) > s.window {
+			s.samples = s.samples[len(s.samples)-s.window:]
+		}
+		var sum float64
+		for _, v := range s.samples {
+			sum += v
+		}
+		return sum / float64(len(s.samples))
+	}
+	return percent
+}
+
+// class describes the held level, with its gradient color taken from the
+// smoothed value clamped into that level's range
+func (s *Smoother) class() Class {
+	levels := s.classifier.Levels()
+	l := levels[s.level]
+	v := math.Max(s.value, l.From)
+	if s.level+1 < len(levels) {
+		v = math.Min(v, math.Nextafter(levels[s.level+1].From, l.From))
+	}
+	return Class{Level: s.level, Name: l.Name, State: l.State, Color: s.classifier.Color(v)}
+}
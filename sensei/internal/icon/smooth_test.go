@@ -0,0 +1,118 @@
+package icon
+
+import (
+	"testing"
+	"time"
+
+	"system-shinobi/sensei/internal/config"
+)
+
+// feed plays readings one second apart and returns the level after each
+func feed(s *Smoother, readings []float64) []int {
+	start := time.Unix(1707860342, 0)
+	levels := make([]int, len(readings))
+	for i, r := range readings {
+		levels[i] = s.Update(start.Add(time.Duration(i)*time.Second), r).Level
+	}
+	return levels
+}
+
+func changes(levels []int) int {
+	n := 0
+	for i := 1; i < len(levels); i++ {
+		if levels[i] != levels[i-1] {
+			n++
+		}
+	}
+	return n
+}
+
+func newSmoother(t *testing.T, cfg config.Smoothing) *Smoother {
+	t.Helper()
+	s, err := NewSmoother(DefaultClassifier(), cfg)
+	if err != nil {
+		t.Fatalf("NewSmoother failed: %v", err)
+	}
+	return s
+}
+
+// hovering alternates just either side of the 40% Low/Medium boundary
+var hovering = []float64{38, 42, 37, 43, 39, 41, 36, 44, 38, 42, 39, 41, 38, 42, 37, 43}
+
+func TestUnsmoothedFlaps(t *testing.T) {
+	s := newSmoother(t, config.Smoothing{Method: "none"})
+	if n := changes(feed(s, hovering)); n != len(hovering)-1 {
+		t.Errorf("Expected the raw classification to change every reading, got %d changes", n)
+	}
+}
+
+func TestDefaultSmoothingStopsFlapping(t *testing.T) {
+	s := newSmoother(t, config.Default().Tray.Smoothing)
+	if n := changes(feed(s, hovering)); n > 1 {
+		t.Errorf("Expected at most one level change while hovering, got %d", n)
+	}
+}
+
+func TestSmoothingFollowsRealChanges(t *testing.T) {
+	s := newSmoother(t, config.Default().Tray.Smoothing)
+	readings := []float64{5, 5, 5, 95, 95, 95, 95, 95, 95, 95, 95, 95}
+	levels := feed(s, readings)
+	if levels[0] != 0 || levels[len(levels)-1] != 3 {
+		t.Errorf("Expected Idle rising to High, got %v", levels)
+	}
+}
+
+func TestDwellHoldsLevel(t *testing.T) {
+	s := newSmoother(t, config.Smoothing{Method: "none", Dwell: config.Duration{Duration: 5 * time.Second}})
+	levels := feed(s, []float64{10, 80, 80, 80, 80, 80, 10})
+	expected := []int{0, 0, 0, 0, 0, 3, 3}
+	for i := range expected {
+		if levels[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, levels)
+		}
+	}
+}
+
+func TestMargins(t *testing.T) {
+	s := newSmoother(t, config.Smoothing{Method: "none", UpMargin: 3, DownMargin: 5})
+	levels := feed(s, []float64{30, 41, 43, 38, 36, 34})
+	expected := []int{1, 1, 2, 2, 2, 1}
+	for i := range expected {
+		if levels[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, levels)
+		}
+	}
+}
+
+func TestWindowAverage(t *testing.T) {
+	s := newSmoother(t, config.Smoothing{Method: "window", Window: 3})
+	feed(s, []float64{0, 30, 60, 90})
+	if s.Value() != 60 {
+		t.Errorf("Expected the mean of the last 3 readings, got %v", s.Value())
+	}
+}
+
+func TestSmoothedGradientStaysInLevel(t *testing.T) {
+	c, _ := NewClassifier(config.Classify{Gradient: true})
+	s, _ := NewSmoother(c, config.Smoothing{Method: "none", DownMargin: 5})
+	start := time.Unix(0, 0)
+	s.Update(start, 50)
+	held := s.Update(start.Add(time.Second), 37) // held in Medium by the margin
+	if held.Level != 2 || held.Color != c.Color(40) {
+		t.Errorf("Expected Medium at its lowest color, got %+v", held)
+	}
+}
+
+func TestNewSmootherValidation(t *testing.T) {
+	for _, cfg := range []config.Smoothing{
+		{Method: "ema", Alpha: 0},
+		{Method: "ema", Alpha: 1.5},
+		{Method: "window"},
+		{Method: "median"},
+		{Method: "none", DownMargin: -1},
+	} {
+		if _, err := NewSmoother(DefaultClassifier(), cfg); err == nil {
+			t.Errorf("Expected %+v to be rejected", cfg)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package icon
 
+import "fmt"
+
 // IconState represents different CPU load states
 type IconState int
 
@@ -12,16 +14,76 @@ const (
 
 // Classify determines the IconState based on CPU percentage
 func Classify(cpuPercent float64) IconState {
-	if cpuPercent < 15.0 {
-		return StateIdle
+	return DefaultClassifier().Classify(cpuPercent).State
+}
+
+// ClassifyLoad determines the IconState from the 1-minute load average
+// relative to the number of cores, so a run queue longer than the
+// machine can serve reads as High even when CPU% has plateaued
+func ClassifyLoad(load1 float64, cores int) IconState {
+	return Classify(LoadPercent(load1, cores))
+}
+
+// ClassifyPressure determines the IconState from the Linux PSI "some"
+// avg10 percentage: the share of time runnable tasks waited for a CPU
+func ClassifyPressure(someAvg10 float64) IconState {
+	return Classify(PressurePercent(someAvg10))
+}
+
+// loadPoints maps load per core onto the CPU% scale the levels are
+// defined on: a quarter core is Low, half is Medium, a full core is High
+var loadPoints = [][2]float64{{0, 0}, {0.25, 15}, {0.5, 40}, {1, 70}, {2, 100}}
+
+// pressurePoints maps PSI "some" avg10 onto the CPU% scale
+var pressurePoints = [][2]float64{{0, 0}, {1, 15}, {10, 40}, {25, 70}, {100, 100}}
+
+// LoadPercent converts the 1-minute load average into a CPU-equivalent
+// percentage so it can be classified like CPU%
+func LoadPercent(load1 float64, cores int) float64 {
+	if cores < 1 {
+		cores = 1
+	}
+	return piecewise(load1/float64(cores), loadPoints)
+}
+
+// PressurePercent converts a PSI avg10 percentage into a CPU-equivalent percentage
+func PressurePercent(someAvg10 float64) float64 {
+	return piecewise(someAvg10, pressurePoints)
+}
+
+// piecewise interpolates v along ascending (x, y) points, clamping past the ends
+func piecewise(v float64, points [][2]float64) float64 {
+	if v <= points[0][0] {
+		return points[0][1]
 	}
-	if cpuPercent < 40.0 {
-		return StateLow
+	for i := 1; i < len(points); i++ {
+		x0, y0 := points[i-1][0], points[i-1][1]
+		x1, y1 := points[i][0], points[i][1]
+		if v < x1 {
+			return y0 + (v-x0)/(x1-x0)*(y1-y0)
+		}
 	}
-	if cpuPercent < 70.0 {
-		return StateMedium
+	return points[len(points)-1][1]
+}
+
+// Input selects which metric drives the ninja state
+type Input string
+
+const (
+	InputCPU      Input = "cpu"      // CPU percentage (default)
+	InputLoad     Input = "load"     // 1-minute load average per core
+	InputPressure Input = "pressure" // CPU pressure stall (Linux only)
+)
+
+// ParseInput validates an Input name; empty means InputCPU
+func ParseInput(s string) (Input, error) {
+	switch Input(s) {
+	case "", InputCPU:
+		return InputCPU, nil
+	case InputLoad, InputPressure:
+		return Input(s), nil
 	}
-	return StateHigh
+	return "", fmt.Errorf("unknown classification input %q (want cpu, load or pressure)", s)
 }
 
 // String returns the string representation of an IconState
@@ -43,3 +43,58 @@ func TestIconStateString(t *testing.T) {
 		}
 	}
 }
+
+func TestClassifyLoad(t *testing.T) {
+	tests := []struct {
+		load1    float64
+		cores    int
+		expected IconState
+	}{
+		{0.5, 8, StateIdle},
+		{2.0, 8, StateLow},
+		{4.0, 8, StateMedium},
+		{7.9, 8, StateMedium},
+		{8.0, 8, StateHigh},
+		{40.0, 8, StateHigh},
+		{0.3, 0, StateLow}, // unknown core count treated as one core
+	}
+
+	for _, tt := range tests {
+		result := ClassifyLoad(tt.load1, tt.cores)
+		if result != tt.expected {
+			t.Errorf("ClassifyLoad(%f, %d) = %v, expected %v", tt.load1, tt.cores, result, tt.expected)
+		}
+	}
+}
+
+func TestClassifyPressure(t *testing.T) {
+	tests := []struct {
+		someAvg10 float64
+		expected  IconState
+	}{
+		{0.0, StateIdle},
+		{0.99, StateIdle},
+		{1.0, StateLow},
+		{10.0, StateMedium},
+		{25.0, StateHigh},
+		{80.0, StateHigh},
+	}
+
+	for _, tt := range tests {
+		result := ClassifyPressure(tt.someAvg10)
+		if result != tt.expected {
+			t.Errorf("ClassifyPressure(%f) = %v, expected %v", tt.someAvg10, result, tt.expected)
+		}
+	}
+}
+
+func TestParseInput(t *testing.T) {
+	for _, s := range []string{"", "cpu", "load", "pressure"} {
+		if _, err := ParseInput(s); err != nil {
+			t.Errorf("ParseInput(%q) failed: %v", s, err)
+		}
+	}
+	if _, err := ParseInput("temperature"); err == nil {
+		t.Error("Expected an error for an unknown input")
+	}
+}
@@ -0,0 +1,296 @@
+package icon
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// svgDoc is an SVG image restricted to the subset the drawing primitives
+// can render: <svg>, <g>, <rect>, <circle>, <line> and <polygon>, with
+// fill, stroke, stroke-width, opacity and fill-opacity. Shapes are filled
+// (lines are stroked) and painted in order without blending.
+type svgDoc struct {
+	minX, minY    float64
+	width, height float64
+	shapes        []svgShape
+}
+
+type svgShape struct {
+	kind        string    // rect, circle, line or polygon
+	pts         []float64 // x,y pairs: rect corner and size, circle center, line ends, polygon vertices
+	r           float64   // circle radius
+	paint       color.NRGBA
+	strokeWidth float64
+}
+
+// svgStyle is the paint state inherited from enclosing <g> elements
+type svgStyle struct {
+	fill, stroke color.NRGBA
+	strokeWidth  float64
+	opacity      float64
+	fillOpacity  float64
+}
+
+func parseSVG(data []byte) (*svgDoc, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	doc := &svgDoc{}
+	stack := []svgStyle{{fill: color.NRGBA{0, 0, 0, 255}, strokeWidth: 1, opacity: 1, fillOpacity: 1}}
+	seenRoot := false
+	skip := 0 // depth inside ignored metadata elements
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch el := tok.(type) {
+		case xml.StartElement:
+			if skip > 0 {
+				skip++
+				continue
+			}
+			attrs := make(map[string]string)
+			for _, a := range el.Attr {
+				attrs[a.Name.Local] = a.Value
+			}
+			style, err := stack[len(stack)-1].inherit(attrs)
+			if err != nil {
+				return nil, fmt.Errorf("<%s>: %w", el.Name.Local, err)
+			}
+			stack = append(stack, style)
+
+			if !seenRoot {
+				if el.Name.Local != "svg" {
+					return nil, fmt.Errorf("root element is <%s>, not <svg>", el.Name.Local)
+				}
+				if err := doc.parseViewport(attrs); err != nil {
+					return nil, err
+				}
+				seenRoot = true
+				continue
+			}
+
+			switch el.Name.Local {
+			case "g":
+			case "title", "desc", "metadata", "defs":
+				skip = 1
+			case "rect", "circle", "line", "polygon":
+				shape, err := parseShape(el.Name.Local, attrs, style)
+				if err != nil {
+					return nil, fmt.Errorf("<%s>: %w", el.Name.Local, err)
+				}
+				doc.shapes = append(doc.shapes, shape)
+			default:
+				return nil, fmt.Errorf("unsupported SVG element <%s>", el.Name.Local)
+			}
+
+		case xml.EndElement:
+			if skip > 1 {
+				skip--
+				continue
+			}
+			skip = 0
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	if !seenRoot {
+		return nil, errors.New("no <svg> element")
+	}
+	return doc, nil
+}
+
+// parseViewport reads the coordinate system from viewBox, else width and height
+func (d *svgDoc) parseViewport(attrs map[string]string) error {
+	if vb, ok := attrs["viewBox"]; ok {
+		nums, err := parseNumbers(vb)
+		if err != nil || len(nums) != 4 {
+			return fmt.Errorf("invalid viewBox %q", vb)
+		}
+		d.minX, d.minY, d.width, d.height = nums[0], nums[1], nums[2], nums[3]
+	} else {
+		var err error
+		if d.width, err = parseLength(attrs["width"]); err != nil {
+			return fmt.Errorf("<svg> needs a viewBox or width and height: %w", err)
+		}
+		if d.height, err = parseLength(attrs["height"]); err != nil {
+			return fmt.Errorf("<svg> needs a viewBox or width and height: %w", err)
+		}
+	}
+	if d.width <= 0 || d.height <= 0 {
+		return errors.New("<svg> has an empty viewport")
+	}
+	return nil
+}
+
+// inherit applies an element's presentation attributes on top of its parent's
+func (s svgStyle) inherit(attrs map[string]string) (svgStyle, error) {
+	var err error
+	if v, ok := attrs["fill"]; ok {
+		if s.fill, err = parseColor(v); err != nil {
+			return s, err
+		}
+	}
+	if v, ok := attrs["stroke"]; ok {
+		if s.stroke, err = parseColor(v); err != nil {
+			return s, err
+		}
+	}
+	for name, dst := range map[string]*float64{"stroke-width": &s.strokeWidth, "opacity": &s.opacity, "fill-opacity": &s.fillOpacity} {
+		if v, ok := attrs[name]; ok {
+			f, err := parseLength(v)
+			if err != nil {
+				return s, fmt.Errorf("%s: %w", name, err)
+			}
+			if name == "opacity" {
+				f *= s.opacity // opacity compounds down the tree
+			}
+			*dst = f
+		}
+	}
+	return s, nil
+}
+
+func parseShape(kind string, attrs map[string]string, style svgStyle) (svgShape, error) {
+	names := map[string][]string{
+		"rect":   {"x", "y", "width", "height"},
+		"circle": {"cx", "cy", "r"},
+		"line":   {"x1", "y1", "x2", "y2"},
+	}[kind]
+
+	var nums []float64
+	if kind == "polygon" {
+		var err error
+		nums, err = parseNumbers(attrs["points"])
+		if err != nil || len(nums) < 6 || len(nums)%2 != 0 {
+			return svgShape{}, fmt.Errorf("invalid points %q", attrs["points"])
+		}
+	} else {
+		for _, name := range names {
+			v, ok := attrs[name]
+			if !ok && (kind == "rect" && (name == "x" || name == "y")) {
+				v = "0"
+			}
+			f, err := parseLength(v)
+			if err != nil {
+				return svgShape{}, fmt.Errorf("%s: %w", name, err)
+			}
+			nums = append(nums, f)
+		}
+	}
+
+	shape := svgShape{kind: kind, pts: nums, strokeWidth: style.strokeWidth}
+	if kind == "circle" {
+		shape.pts, shape.r = nums[:2], nums[2]
+	}
+	if kind == "line" {
+		shape.paint = withOpacity(style.stroke, style.opacity)
+	} else {
+		shape.paint = withOpacity(style.fill, style.opacity*style.fillOpacity)
+	}
+	return shape, nil
+}
+
+// draw scales the viewport to fill the icon, preserving the aspect ratio
+func (d *svgDoc) draw(img *image.NRGBA) {
+	k := float64(img.Rect.Dx()) / math.Max(d.width, d.height)
+	px := func(x float64) float64 { return (x - d.minX) * k }
+	py := func(y float64) float64 { return (y - d.minY) * k }
+	round := func(v float64) int { return int(math.Round(v)) }
+
+	for _, s := range d.shapes {
+		if s.paint.A == 0 {
+			continue
+		}
+		switch s.kind {
+		case "rect":
+			x0, x1 := round(px(s.pts[0])), round(px(s.pts[0]+s.pts[2]))
+			y0, y1 := round(py(s.pts[1])), round(py(s.pts[1]+s.pts[3]))
+			for y := y0; y < y1; y++ {
+				drawHorizontalLine(img, x0, y, x1-x0, s.paint)
+			}
+		case "circle":
+			drawFilledCircle(img, px(s.pts[0])-0.5, py(s.pts[1])-0.5, s.r*k, s.paint)
+		case "line":
+			width := max(1, round(s.strokeWidth*k))
+			drawAngledLine(img, round(px(s.pts[0])-0.5), round(py(s.pts[1])-0.5),
+				round(px(s.pts[2])-0.5), round(py(s.pts[3])-0.5), width, s.paint)
+		case "polygon":
+			// Fan triangulation, exact for convex polygons
+			x0, y0 := round(px(s.pts[0])-0.5), round(py(s.pts[1])-0.5)
+			for i := 2; i+3 < len(s.pts); i += 2 {
+				drawFilledTriangle(img, x0, y0,
+					round(px(s.pts[i])-0.5), round(py(s.pts[i+1])-0.5),
+					round(px(s.pts[i+2])-0.5), round(py(s.pts[i+3])-0.5), s.paint)
+			}
+		}
+	}
+}
+
+func withOpacity(c color.NRGBA, opacity float64) color.NRGBA {
+	c.A = uint8(math.Round(float64(c.A) * math.Max(0, math.Min(opacity, 1))))
+	return c
+}
+
+// namedColors are the color keywords accepted besides hex notation
+var namedColors = map[string]color.NRGBA{
+	"black": {0, 0, 0, 255},
+	"white": {255, 255, 255, 255},
+	"gray":  {128, 128, 128, 255},
+	"grey":  {128, 128, 128, 255},
+	"red":   {255, 0, 0, 255},
+	"green": {0, 128, 0, 255},
+	"blue":  {0, 0, 255, 255},
+	"none":  {},
+}
+
+// parseColor accepts #rgb, #rrggbb and a few color keywords
+func parseColor(s string) (color.NRGBA, error) {
+	s = strings.TrimSpace(s)
+	if c, ok := namedColors[strings.ToLower(s)]; ok {
+		return c, nil
+	}
+	hex, ok := strings.CutPrefix(s, "#")
+	if ok && len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if !ok || len(hex) != 6 {
+		return color.NRGBA{}, fmt.Errorf("unsupported color %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("unsupported color %q", s)
+	}
+	return color.NRGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, nil
+}
+
+// parseLength parses a number, allowing a px suffix
+func parseLength(s string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "px"), 64)
+}
+
+// parseNumbers parses a comma- or space-separated list of numbers
+func parseNumbers(s string) ([]float64, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' })
+	nums := make([]float64, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, err
+		}
+		nums[i] = v
+	}
+	return nums, nil
+}
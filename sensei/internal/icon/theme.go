@@ -0,0 +1,225 @@
+package icon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestFile is the name of the manifest inside a theme directory
+const ManifestFile = "theme.json"
+
+// allStates lists every IconState a theme must provide an image for
+var allStates = []IconState{StateIdle, StateLow, StateMedium, StateHigh}
+
+// Theme draws the icon for each state
+type Theme interface {
+	Name() string
+	// Color is the accent for a state, used by the value overlays
+	Color(state IconState, template bool) color.NRGBA
+	// draw renders a state; c is the state's color, which themes may ignore
+	draw(img *image.NRGBA, state IconState, c color.NRGBA, template bool)
+}
+
+// Ninja is the built-in default theme
+var Ninja Theme = ninjaTheme{}
+
+type ninjaTheme struct{}
+
+func (ninjaTheme) Name() string { return "ninja" }
+
+func (ninjaTheme) Color(state IconState, template bool) color.NRGBA {
+	return getColorForState(state, template)
+}
+
+func (ninjaTheme) draw(img *image.NRGBA, state IconState, c color.NRGBA, template bool) {
+	drawNinjaFace(newGrid(img), c, state)
+}
+
+// manifest is the theme.json file of a theme directory, e.g.
+//
+//	{
+//	  "name": "dot",
+//	  "states": {"idle": "idle.svg", "low": "low.svg", "medium": "medium.svg", "high": "high.png"},
+//	  "colors": {"high": "#f44336"}
+//	}
+type manifest struct {
+	Name   string            `json:"name"`   // defaults to the directory name
+	States map[string]string `json:"states"` // state name -> PNG or SVG file in the theme directory
+	Colors map[string]string `json:"colors"` // state name -> overlay color, defaults to the ninja's
+}
+
+// source is one state's image, drawn to fill the icon
+type source interface {
+	draw(img *image.NRGBA)
+}
+
+// imageTheme is a theme loaded from disk
+type imageTheme struct {
+	name   string
+	images map[IconState]source
+	colors map[IconState]color.NRGBA
+}
+
+func (t *imageTheme) Name() string { return t.name }
+
+func (t *imageTheme) Color(state IconState, template bool) color.NRGBA {
+	if template {
+		return getColorForState(state, true)
+	}
+	if c, ok := t.colors[state]; ok {
+		return c
+	}
+	return getColorForState(state, false)
+}
+
+func (t *imageTheme) draw(img *image.NRGBA, state IconState, c color.NRGBA, template bool) {
+	t.images[state].draw(img)
+	if template {
+		blacken(img)
+	}
+}
+
+// LoadTheme reads a theme directory: a theme.json manifest naming a PNG
+// or SVG-subset image for every IconState
+func LoadTheme(dir string) (Theme, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("reading theme: %w", err)
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath.Join(dir, ManifestFile), err)
+	}
+
+	t := &imageTheme{
+		name:   m.Name,
+		images: make(map[IconState]source),
+		colors: make(map[IconState]color.NRGBA),
+	}
+	if t.name == "" {
+		t.name = filepath.Base(dir)
+	}
+
+	for name, file := range m.States {
+		state, err := parseState(name)
+		if err != nil {
+			return nil, fmt.Errorf("theme %s: %w", t.name, err)
+		}
+		src, err := loadSource(dir, file)
+		if err != nil {
+			return nil, fmt.Errorf("theme %s, state %s: %w", t.name, name, err)
+		}
+		t.images[state] = src
+	}
+	for _, state := range allStates {
+		if t.images[state] == nil {
+			return nil, fmt.Errorf("theme %s has no image for state %s", t.name, state)
+		}
+	}
+
+	for name, value := range m.Colors {
+		state, err := parseState(name)
+		if err != nil {
+			return nil, fmt.Errorf("theme %s: %w", t.name, err)
+		}
+		c, err := parseColor(value)
+		if err != nil {
+			return nil, fmt.Errorf("theme %s, color for %s: %w", t.name, name, err)
+		}
+		t.colors[state] = c
+	}
+	return t, nil
+}
+
+func parseState(name string) (IconState, error) {
+	for _, state := range allStates {
+		if state.String() == name {
+			return state, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown state %q (want idle, low, medium or high)", name)
+}
+
+// loadSource decodes a state image, which must live inside the theme directory
+func loadSource(dir, file string) (source, error) {
+	if !filepath.IsLocal(file) {
+		return nil, fmt.Errorf("image %q must be a path inside the theme directory", file)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".png":
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", file, err)
+		}
+		return pngSource{img}, nil
+	case ".svg":
+		doc, err := parseSVG(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", file, err)
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("image %q is not a .png or .svg file", file)
+}
+
+// pngSource is a bitmap scaled to the icon size
+type pngSource struct {
+	img image.Image
+}
+
+// samples is the per-axis supersampling used when scaling bitmaps
+const samples = 4
+
+// draw resamples the bitmap by averaging a grid of samples per pixel, which
+// keeps downscaled PNGs smooth and upscaled ones sharp-edged
+func (s pngSource) draw(img *image.NRGBA) {
+	src := s.img.Bounds()
+	size := img.Rect.Dx()
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			var r, g, b, a uint32
+			for sy := 0; sy < samples; sy++ {
+				for sx := 0; sx < samples; sx++ {
+					u := src.Min.X + (x*samples+sx)*src.Dx()/(size*samples)
+					v := src.Min.Y + (y*samples+sy)*src.Dy()/(size*samples)
+					pr, pg, pb, pa := s.img.At(u, v).RGBA() // premultiplied
+					r, g, b, a = r+pr, g+pg, b+pb, a+pa
+				}
+			}
+			n := uint32(samples * samples)
+			if a == 0 {
+				continue
+			}
+			img.Set(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(b / n), uint16(a / n)})
+		}
+	}
+}
+
+// blacken turns every pixel black, keeping its alpha, for template icons
+func blacken(img *image.NRGBA) {
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2] = 0, 0, 0
+	}
+}
+
+// fade scales the alpha of every pixel by a/255
+func fade(img *image.NRGBA, a uint8) {
+	if a == 255 {
+		return
+	}
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = uint8(uint16(img.Pix[i]) * uint16(a) / 255)
+	}
+}
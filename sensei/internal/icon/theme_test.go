@@ -0,0 +1,174 @@
+package icon
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const dotSVG = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 16 16">
+  <title>dot</title>
+  <g fill="#4caf50">
+    <circle cx="8" cy="8" r="6"/>
+  </g>
+</svg>`
+
+func writeTheme(t *testing.T, manifest string, files map[string][]byte) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func solidPNG(t *testing.T, c color.NRGBA) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func renderNRGBA(t *testing.T, state IconState, opts Options) *image.NRGBA {
+	t.Helper()
+	data, err := Render(state, opts)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+	nrgba := image.NewNRGBA(img.Bounds())
+	draw.Draw(nrgba, nrgba.Rect, img, image.Point{}, draw.Src)
+	return nrgba
+}
+
+func TestLoadTheme(t *testing.T) {
+	dir := writeTheme(t, `{
+		"name": "dot",
+		"states": {"idle": "dot.svg", "low": "dot.svg", "medium": "dot.svg", "high": "high.png"},
+		"colors": {"high": "#00f"}
+	}`, map[string][]byte{
+		"dot.svg":  []byte(dotSVG),
+		"high.png": solidPNG(t, color.NRGBA{200, 0, 0, 255}),
+	})
+
+	theme, err := LoadTheme(dir)
+	if err != nil {
+		t.Fatalf("LoadTheme failed: %v", err)
+	}
+	if theme.Name() != "dot" {
+		t.Errorf("Expected name dot, got %q", theme.Name())
+	}
+	if c := theme.Color(StateHigh, false); c != (color.NRGBA{0, 0, 255, 255}) {
+		t.Errorf("Expected the manifest's high color, got %v", c)
+	}
+	if c := theme.Color(StateIdle, false); c != getColorForState(StateIdle, false) {
+		t.Errorf("Expected the default idle color, got %v", c)
+	}
+
+	img := renderNRGBA(t, StateLow, Options{Size: 32, Theme: theme})
+	if c := img.NRGBAAt(16, 16); c != (color.NRGBA{76, 175, 80, 255}) {
+		t.Errorf("Expected the dot's fill at the center, got %v", c)
+	}
+	if c := img.NRGBAAt(0, 0); c.A != 0 {
+		t.Errorf("Expected a transparent corner, got %v", c)
+	}
+
+	img = renderNRGBA(t, StateHigh, Options{Size: 32, Scale: 2, Theme: theme})
+	if img.Rect.Dx() != 64 || img.NRGBAAt(63, 63) != (color.NRGBA{200, 0, 0, 255}) {
+		t.Errorf("Expected the PNG scaled to fill 64px, got %v at the corner", img.NRGBAAt(63, 63))
+	}
+
+	img = renderNRGBA(t, StateLow, Options{Theme: theme, Template: true})
+	if c := img.NRGBAAt(11, 11); c != (color.NRGBA{0, 0, 0, 255}) {
+		t.Errorf("Expected template icons to be black, got %v", c)
+	}
+}
+
+func TestLoadThemeValidation(t *testing.T) {
+	files := map[string][]byte{"dot.svg": []byte(dotSVG)}
+	tests := []struct {
+		name     string
+		manifest string
+		files    map[string][]byte
+		want     string
+	}{
+		{"missing state", `{"states": {"idle": "dot.svg", "low": "dot.svg", "medium": "dot.svg"}}`, files, "no image for state high"},
+		{"unknown state", `{"states": {"idle": "dot.svg", "low": "dot.svg", "medium": "dot.svg", "high": "dot.svg", "panic": "dot.svg"}}`, files, "unknown state"},
+		{"missing file", `{"states": {"idle": "dot.svg", "low": "dot.svg", "medium": "dot.svg", "high": "gone.svg"}}`, files, "gone.svg"},
+		{"escaping path", `{"states": {"idle": "../dot.svg", "low": "dot.svg", "medium": "dot.svg", "high": "dot.svg"}}`, files, "inside the theme directory"},
+		{"unsupported svg", `{"states": {"idle": "p.svg", "low": "dot.svg", "medium": "dot.svg", "high": "dot.svg"}}`,
+			map[string][]byte{"dot.svg": []byte(dotSVG), "p.svg": []byte(`<svg viewBox="0 0 4 4"><path d="M0 0L4 4"/></svg>`)}, "unsupported SVG element <path>"},
+		{"bad color", `{"states": {"idle": "dot.svg", "low": "dot.svg", "medium": "dot.svg", "high": "dot.svg"}, "colors": {"high": "hotpink"}}`, files, "unsupported color"},
+	}
+
+	for _, tt := range tests {
+		dir := writeTheme(t, tt.manifest, tt.files)
+		_, err := LoadTheme(dir)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestParseSVGShapes(t *testing.T) {
+	doc, err := parseSVG([]byte(`<svg width="22px" height="22px">
+		<rect width="22" height="4" fill="#fff" opacity="0.5"/>
+		<line x1="0" y1="10" x2="21" y2="10" stroke="black" stroke-width="2"/>
+		<polygon points="0,22 11,12 22,22" fill="red"/>
+	</svg>`))
+	if err != nil {
+		t.Fatalf("parseSVG failed: %v", err)
+	}
+	if doc.width != 22 || len(doc.shapes) != 3 {
+		t.Fatalf("Unexpected document: %+v", doc)
+	}
+	if doc.shapes[0].paint != (color.NRGBA{255, 255, 255, 128}) {
+		t.Errorf("Expected a half-transparent white rect, got %v", doc.shapes[0].paint)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, 22, 22))
+	doc.draw(img)
+	if img.NRGBAAt(5, 1).A != 128 || img.NRGBAAt(5, 10).A != 255 || img.NRGBAAt(11, 20).R != 255 {
+		t.Errorf("Shapes not drawn where expected")
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := map[string]color.NRGBA{
+		"#f44336": {244, 67, 54, 255},
+		"#0f0":    {0, 255, 0, 255},
+		"Black":   {0, 0, 0, 255},
+		"none":    {},
+	}
+	for s, expected := range tests {
+		got, err := parseColor(s)
+		if err != nil || got != expected {
+			t.Errorf("parseColor(%q) = %v, %v; expected %v", s, got, err, expected)
+		}
+	}
+	for _, s := range []string{"#12345", "rgb(1,2,3)", "#ggg"} {
+		if _, err := parseColor(s); err == nil {
+			t.Errorf("Expected parseColor(%q) to fail", s)
+		}
+	}
+}
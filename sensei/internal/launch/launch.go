@@ -0,0 +1,132 @@
+// Package launch opens commands in a new terminal window
+package launch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// placeholder marks where the command goes in a terminal template
+const placeholder = "{}"
+
+// launchGrace is how long Open waits for a terminal that fails immediately
+const launchGrace = time.Second
+
+// knownTerminals maps terminal binaries to how they take a command to run
+var knownTerminals = map[string][]string{
+	"x-terminal-emulator": {"-e", placeholder},
+	"gnome-terminal":      {"--", placeholder},
+	"konsole":             {"-e", placeholder},
+	"kitty":               {placeholder},
+	"alacritty":           {"-e", placeholder},
+	"wezterm":             {"start", "--", placeholder},
+	"foot":                {placeholder},
+	"xterm":               {"-e", placeholder},
+}
+
+// Launcher opens a command in a new terminal window
+type Launcher struct {
+	name  string
+	build func(command []string) []string // full argv for the terminal
+}
+
+// Name returns the terminal the launcher uses
+func (l *Launcher) Name() string {
+	return l.name
+}
+
+// Args returns the argv that opens command in the terminal
+func (l *Launcher) Args(command ...string) []string {
+	return l.build(command)
+}
+
+// Open starts the terminal running command. A terminal that exits with an
+// error straight away is reported, with what it printed.
+func (l *Launcher) Open(command ...string) error {
+	argv := l.build(command)
+	cmd := exec.Command(argv[0], argv[1:]...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%s: %w", l.name, err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return fmt.Errorf("%s: %w: %s", l.name, err, msg)
+			}
+			return fmt.Errorf("%s: %w", l.name, err)
+		}
+	case <-time.After(launchGrace):
+	}
+	return nil
+}
+
+// Detect picks the terminal to use: the override from config if set, then
+// $TERMINAL, then the first installed terminal this platform knows
+func Detect(override string) (*Launcher, error) {
+	return detect(override, os.Getenv, exec.LookPath)
+}
+
+func detect(override string, getenv func(string) string, lookPath func(string) (string, error)) (*Launcher, error) {
+	if override = strings.TrimSpace(override); override != "" {
+		return fromTemplate(override), nil
+	}
+	if term := strings.TrimSpace(getenv("TERMINAL")); term != "" {
+		return fromTemplate(term), nil
+	}
+	for _, name := range candidates {
+		if path, err := lookPath(name); err == nil {
+			return fromArgs(name, append([]string{path}, knownTerminals[name]...)), nil
+		}
+	}
+	if fallback != nil {
+		return fallback, nil
+	}
+	return nil, errors.New("no terminal emulator found: set $TERMINAL or dojo.terminal in the config")
+}
+
+// fromTemplate builds a launcher from a command line such as "kitty" or
+// "foot --app-id dojo {}". Known terminals get their usual arguments and
+// anything else is given "-e".
+func fromTemplate(template string) *Launcher {
+	argv := strings.Fields(template)
+	name := filepath.Base(argv[0])
+	if !slices.Contains(argv, placeholder) {
+		if args, ok := knownTerminals[name]; ok && len(argv) == 1 {
+			argv = append(argv, args...)
+		} else {
+			argv = append(argv, "-e", placeholder)
+		}
+	}
+	return fromArgs(name, argv)
+}
+
+// fromArgs builds a launcher that substitutes the command for the placeholder
+func fromArgs(name string, argv []string) *Launcher {
+	return &Launcher{
+		name: name,
+		build: func(command []string) []string {
+			var out []string
+			for _, arg := range argv {
+				if arg == placeholder {
+					out = append(out, command...)
+				} else {
+					out = append(out, arg)
+				}
+			}
+			return out
+		},
+	}
+}
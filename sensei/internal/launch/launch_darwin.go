@@ -0,0 +1,29 @@
+package launch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// candidates are tried in order when neither config nor $TERMINAL names a
+// terminal; on macOS Terminal.app is always there, so none are probed
+var candidates []string
+
+// fallback opens the command in a new Terminal.app window through AppleScript
+var fallback = &Launcher{
+	name: "Terminal.app",
+	build: func(command []string) []string {
+		quoted := make([]string, len(command))
+		for i, arg := range command {
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		script := strings.ReplaceAll(strings.Join(quoted, " "), `\`, `\\`)
+		script = strings.ReplaceAll(script, `"`, `\"`)
+		return []string{"osascript",
+			"-e", `tell application "Terminal"`,
+			"-e", fmt.Sprintf(`do script "%s"`, script),
+			"-e", `activate`,
+			"-e", `end tell`,
+		}
+	},
+}
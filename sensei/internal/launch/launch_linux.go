@@ -0,0 +1,9 @@
+package launch
+
+// candidates are tried in order when neither config nor $TERMINAL names a terminal
+var candidates = []string{
+	"x-terminal-emulator", "gnome-terminal", "konsole", "kitty", "alacritty", "wezterm", "foot", "xterm",
+}
+
+// fallback is used when no candidate is installed; Linux has no default terminal
+var fallback *Launcher
@@ -0,0 +1,90 @@
+package launch
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func fakeEnv(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func fakePath(installed ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		for _, n := range installed {
+			if n == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func TestDetectOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		override string
+		env      map[string]string
+		path     []string
+		expected []string
+	}{
+		{"override wins", "foot --app-id dojo {}", map[string]string{"TERMINAL": "kitty"}, []string{"konsole"},
+			[]string{"foot", "--app-id", "dojo", "/opt/dojo"}},
+		{"$TERMINAL known", "", map[string]string{"TERMINAL": "wezterm"}, nil,
+			[]string{"wezterm", "start", "--", "/opt/dojo"}},
+		{"$TERMINAL unknown", "", map[string]string{"TERMINAL": "/usr/local/bin/st"}, nil,
+			[]string{"/usr/local/bin/st", "-e", "/opt/dojo"}},
+		{"override with flags", "alacritty --class dojo", nil, nil,
+			[]string{"alacritty", "--class", "dojo", "-e", "/opt/dojo"}},
+	}
+
+	for _, tt := range tests {
+		l, err := detect(tt.override, fakeEnv(tt.env), fakePath(tt.path...))
+		if err != nil {
+			t.Errorf("%s: detect failed: %v", tt.name, err)
+			continue
+		}
+		if got := l.Args("/opt/dojo"); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: Args = %q, expected %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestDetectProbesCandidates(t *testing.T) {
+	if len(candidates) == 0 {
+		t.Skip("this platform doesn't probe for terminals")
+	}
+	l, err := detect("", fakeEnv(nil), fakePath("kitty", "konsole"))
+	if err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	// konsole comes before kitty in the candidate list
+	if l.Name() != "konsole" {
+		t.Errorf("Expected konsole, got %s", l.Name())
+	}
+	if got := l.Args("/opt/dojo", "-config", "c.json"); !reflect.DeepEqual(got, []string{"/usr/bin/konsole", "-e", "/opt/dojo", "-config", "c.json"}) {
+		t.Errorf("Unexpected args %q", got)
+	}
+}
+
+func TestDetectNothingInstalled(t *testing.T) {
+	if fallback != nil {
+		t.Skip("this platform has a default terminal")
+	}
+	_, err := detect("", fakeEnv(nil), fakePath())
+	if err == nil || !strings.Contains(err.Error(), "no terminal emulator found") {
+		t.Errorf("Expected a helpful error, got %v", err)
+	}
+}
+
+func TestOpenReportsImmediateFailure(t *testing.T) {
+	l := fromTemplate("false {}")
+	if err := l.Open("dojo"); err == nil {
+		t.Error("Expected a terminal that exits with an error to be reported")
+	}
+	if err := fromTemplate("definitely-not-a-terminal").Open("dojo"); err == nil {
+		t.Error("Expected a missing terminal to be reported")
+	}
+}
@@ -0,0 +1,173 @@
+package logging
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotatingFile is a log file that is renamed to path.1 once it reaches
+// its size limit, shifting older files up to path.N
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+}
+
+// OpenRotating opens path for appending, creating it and its directory
+// if needed. maxFiles rotated files are kept.
+func OpenRotating(path string, maxSize int64, maxFiles int) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f := &RotatingFile{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// Write appends p, rotating first if p would take the file past its limit
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *RotatingFile) rotate() error {
+	f.file.Close()
+	f.file = nil
+	for i := f.maxFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+	}
+	if f.maxFiles > 0 {
+		if err := os.Rename(f.path, f.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(f.path); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// Path returns the file's location
+func (f *RotatingFile) Path() string {
+	return f.path
+}
+
+// Close closes the file. It is safe to call on a nil RotatingFile.
+func (f *RotatingFile) Close() error {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// Entry is one line of the JSON log file
+type Entry struct {
+	Time  time.Time
+	Level string
+	Msg   string
+	Attrs string // the remaining attributes as key=value pairs
+}
+
+// ReadTail returns up to the last n entries of the log file at path,
+// newest first. Lines that aren't JSON are skipped.
+func ReadTail(path string, n int) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		e, ok := parseEntry(scanner.Bytes())
+		if !ok {
+			continue
+		}
+		entries = append(entries, e)
+		if len(entries) > n {
+			entries = entries[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Newest first
+	slices.Reverse(entries)
+	return entries, nil
+}
+
+func parseEntry(line []byte) (Entry, bool) {
+	var fields map[string]any
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return Entry{}, false
+	}
+
+	var e Entry
+	if s, ok := fields["time"].(string); ok {
+		e.Time, _ = time.Parse(time.RFC3339Nano, s)
+	}
+	e.Level, _ = fields["level"].(string)
+	e.Msg, _ = fields["msg"].(string)
+	delete(fields, "time")
+	delete(fields, "level")
+	delete(fields, "msg")
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	attrs := make([]string, len(keys))
+	for i, k := range keys {
+		attrs[i] = fmt.Sprintf("%s=%v", k, fields[k])
+	}
+	e.Attrs = strings.Join(attrs, " ")
+	return e, true
+}
@@ -0,0 +1,133 @@
+// Package logging sets up sensei's structured logging: human-readable
+// lines on stderr and JSON lines in a rotating file in the state
+// directory, with repeated warnings rate limited
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"system-shinobi/sensei/internal/config"
+)
+
+// Defaults for the zero values in config.Log
+const (
+	defaultMaxSize        = 10 << 20 // bytes before the file is rotated
+	defaultMaxFiles       = 3        // rotated files kept besides the current one
+	defaultRepeatInterval = time.Minute
+)
+
+// DefaultPath returns the sensei log location inside stateDir
+func DefaultPath(stateDir string) string {
+	return filepath.Join(stateDir, "sensei.log")
+}
+
+// ParseLevel parses "debug", "info", "warn" or "error". Empty means info.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", s)
+}
+
+// ErrFileUnavailable is returned by Setup when logging works but only to
+// stderr, because the log file couldn't be opened
+var ErrFileUnavailable = errors.New("log file unavailable")
+
+// Setup makes slog's default logger, and with it the standard log
+// package, write to stderr and to a rotating file at path. level
+// overrides cfg.Level when not empty. If the file can't be opened
+// logging continues on stderr and the error wraps ErrFileUnavailable.
+func Setup(cfg config.Log, level, path string) (io.Closer, error) {
+	if level == "" {
+		level = cfg.Level
+	}
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxSize < 0 || cfg.MaxFiles < 0 || cfg.RepeatInterval.Duration < 0 {
+		return nil, fmt.Errorf("log max_size_mb, max_files and repeat_interval must not be negative")
+	}
+
+	maxSize := int64(cfg.MaxSize) << 20
+	if maxSize == 0 {
+		maxSize = defaultMaxSize
+	}
+	maxFiles := cfg.MaxFiles
+	if maxFiles == 0 {
+		maxFiles = defaultMaxFiles
+	}
+	interval := cfg.RepeatInterval.Duration
+	if interval == 0 {
+		interval = defaultRepeatInterval
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	handlers := []slog.Handler{slog.NewTextHandler(os.Stderr, opts)}
+	file, fileErr := OpenRotating(path, maxSize, maxFiles)
+	if fileErr == nil {
+		handlers = append(handlers, slog.NewJSONHandler(file, opts))
+	}
+
+	slog.SetDefault(slog.New(RateLimit(fanout(handlers), interval)))
+	if fileErr != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFileUnavailable, fileErr)
+	}
+	return file, nil
+}
+
+// fanout passes records to every handler that wants them
+type fanout []slog.Handler
+
+func (f fanout) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanout) Handle(ctx context.Context, r slog.Record) error {
+	var first error
+	for _, h := range f {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(fanout, len(f))
+	for i, h := range f {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (f fanout) WithGroup(name string) slog.Handler {
+	out := make(fanout, len(f))
+	for i, h := range f {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
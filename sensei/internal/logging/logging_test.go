@@ -0,0 +1,184 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"system-shinobi/sensei/internal/config"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected slog.Level
+	}{
+		{"", slog.LevelInfo},
+		{"debug", slog.LevelDebug},
+		{"WARN", slog.LevelWarn},
+		{"warning", slog.LevelWarn},
+		{" error ", slog.LevelError},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if err != nil || got != tt.expected {
+			t.Errorf("ParseLevel(%q) = %v, %v, expected %v", tt.in, got, err, tt.expected)
+		}
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
+
+func TestRateLimitSuppressesRepeats(t *testing.T) {
+	var buf bytes.Buffer
+	h := RateLimit(slog.NewTextHandler(&buf, nil), time.Minute).(*rateLimiter)
+	now := time.Date(2024, 2, 13, 14, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+	logger := slog.New(h)
+
+	for i := 0; i < 5; i++ {
+		logger.Warn("Skipping malformed JSON line", "line", i)
+		logger.Info("Reading", "n", i)
+	}
+	if n := strings.Count(buf.String(), "Skipping malformed"); n != 1 {
+		t.Errorf("Expected 1 warning within the interval, got %d:\n%s", n, buf.String())
+	}
+	if n := strings.Count(buf.String(), "msg=Reading"); n != 5 {
+		t.Errorf("Expected info records to pass through, got %d", n)
+	}
+
+	// Derived loggers share the limit
+	logger.With("source", "pipe").Warn("Skipping malformed JSON line")
+
+	buf.Reset()
+	now = now.Add(time.Minute)
+	logger.Warn("Skipping malformed JSON line", "line", 99)
+	if !strings.Contains(buf.String(), "suppressed=5") {
+		t.Errorf("Expected the suppressed count, got:\n%s", buf.String())
+	}
+}
+
+func TestRateLimitPassesEvents(t *testing.T) {
+	var buf bytes.Buffer
+	h := RateLimit(slog.NewTextHandler(&buf, nil), time.Minute).(*rateLimiter)
+	now := time.Date(2024, 2, 13, 14, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+	logger := slog.New(h)
+
+	ctx := Event(context.Background())
+	for _, name := range []string{"hot", "battery", "hot"} {
+		logger.WarnContext(ctx, "Alert firing", "alert", name)
+	}
+	for _, name := range []string{"alert=hot", "alert=battery"} {
+		if !strings.Contains(buf.String(), name) {
+			t.Errorf("Expected %s to be logged, got:\n%s", name, buf.String())
+		}
+	}
+	if n := strings.Count(buf.String(), "Alert firing"); n != 3 {
+		t.Errorf("Expected every alert event to pass, got %d:\n%s", n, buf.String())
+	}
+}
+
+func TestSetupWithoutFile(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	// A regular file where the log directory should be
+	blocker := filepath.Join(t.TempDir(), "state")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	closer, err := Setup(config.Log{}, "", filepath.Join(blocker, "sensei.log"))
+	if !errors.Is(err, ErrFileUnavailable) || closer != nil {
+		t.Errorf("Expected ErrFileUnavailable and no closer, got %v, %v", closer, err)
+	}
+
+	if _, err := Setup(config.Log{}, "loud", filepath.Join(t.TempDir(), "sensei.log")); err == nil || errors.Is(err, ErrFileUnavailable) {
+		t.Errorf("Expected a config error for a bad level, got %v", err)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "sensei.log")
+	f, err := OpenRotating(path, 20, 2)
+	if err != nil {
+		t.Fatalf("OpenRotating failed: %v", err)
+	}
+	defer f.Close()
+
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(f, "line %d 0123456\n", i) // 15 bytes, one per file
+	}
+
+	for name, expected := range map[string]string{
+		"sensei.log":   "line 4",
+		"sensei.log.1": "line 3",
+		"sensei.log.2": "line 2",
+	} {
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(path), name))
+		if err != nil {
+			t.Fatalf("Reading %s: %v", name, err)
+		}
+		if !strings.HasPrefix(string(data), expected) {
+			t.Errorf("%s = %q, expected it to start with %q", name, data, expected)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected only 2 rotated files, got %v", err)
+	}
+}
+
+func TestReadTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sensei.log")
+	f, err := OpenRotating(path, 1<<20, 1)
+	if err != nil {
+		t.Fatalf("OpenRotating failed: %v", err)
+	}
+	logger := slog.New(slog.NewJSONHandler(f, nil))
+	logger.Info("Probe started", "pid", 42, "path", "/usr/bin/probe")
+	logger.Warn("Pipe closed - probe disconnected")
+	fmt.Fprintln(f, "not json")
+	logger.Error("Exporter stopped", "err", "boom")
+	f.Close()
+
+	entries, err := ReadTail(path, 2)
+	if err != nil {
+		t.Fatalf("ReadTail failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Msg != "Exporter stopped" || entries[0].Level != "ERROR" || entries[0].Attrs != "err=boom" {
+		t.Errorf("Newest entry = %+v", entries[0])
+	}
+	if entries[1].Level != "WARN" || entries[1].Time.IsZero() {
+		t.Errorf("Second entry = %+v", entries[1])
+	}
+
+	all, _ := ReadTail(path, 10)
+	if got := all[2].Attrs; got != "path=/usr/bin/probe pid=42" {
+		t.Errorf("Attrs = %q, expected sorted key=value pairs", got)
+	}
+}
+
+func TestFanoutHonorsLevels(t *testing.T) {
+	var debug, warn bytes.Buffer
+	h := fanout{
+		slog.NewTextHandler(&debug, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		slog.NewTextHandler(&warn, &slog.HandlerOptions{Level: slog.LevelWarn}),
+	}
+	if !h.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("Expected debug to be enabled by the first handler")
+	}
+	slog.New(h).Debug("details")
+	if !strings.Contains(debug.String(), "details") || warn.Len() != 0 {
+		t.Errorf("Expected debug only in the first handler, got %q and %q", debug.String(), warn.String())
+	}
+}
@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// maxTracked bounds how many distinct messages the rate limiter remembers
+const maxTracked = 1000
+
+// repeat tracks one warning message
+type repeat struct {
+	last       time.Time // when it was last let through
+	suppressed int       // dropped since then
+}
+
+// limitState is shared by a rate limiter and the handlers derived from it
+type limitState struct {
+	mu   sync.Mutex
+	seen map[string]*repeat
+}
+
+// eventKey marks a context whose records are events, see Event
+type eventKey struct{}
+
+// Event returns a context for logging an event, such as an alert firing
+// or a lost audit entry, that is never rate limited:
+//
+//	slog.WarnContext(logging.Event(ctx), "Alert firing", "alert", name)
+func Event(ctx context.Context) context.Context {
+	return context.WithValue(ctx, eventKey{}, true)
+}
+
+// rateLimiter lets each warning or error message through at most once
+// per interval, whatever its attributes, unless it is an Event. The next
+// one let through carries a "suppressed" count of those dropped in between.
+type rateLimiter struct {
+	next     slog.Handler
+	interval time.Duration
+	state    *limitState
+	now      func() time.Time
+}
+
+// RateLimit wraps next so that a warning or error with the same message
+// is logged at most once per interval. Lower levels and events pass
+// straight through.
+func RateLimit(next slog.Handler, interval time.Duration) slog.Handler {
+	return &rateLimiter{
+		next:     next,
+		interval: interval,
+		state:    &limitState{seen: make(map[string]*repeat)},
+		now:      time.Now,
+	}
+}
+
+func (l *rateLimiter) Enabled(ctx context.Context, level slog.Level) bool {
+	return l.next.Enabled(ctx, level)
+}
+
+func (l *rateLimiter) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelWarn || ctx.Value(eventKey{}) != nil {
+		return l.next.Handle(ctx, r)
+	}
+
+	suppressed, ok := l.allow(r.Level.String() + " " + r.Message)
+	if !ok {
+		return nil
+	}
+	if suppressed > 0 {
+		r = r.Clone()
+		r.AddAttrs(slog.Int("suppressed", suppressed))
+	}
+	return l.next.Handle(ctx, r)
+}
+
+// allow reports whether a record with key may be logged now and how many
+// were suppressed since the last one
+func (l *rateLimiter) allow(key string) (int, bool) {
+	now := l.now()
+	s := l.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rep, ok := s.seen[key]; ok {
+		if now.Sub(rep.last) < l.interval {
+			rep.suppressed++
+			return 0, false
+		}
+		suppressed := rep.suppressed
+		*rep = repeat{last: now}
+		return suppressed, true
+	}
+
+	if len(s.seen) >= maxTracked {
+		for k, rep := range s.seen {
+			if now.Sub(rep.last) >= l.interval {
+				delete(s.seen, k)
+			}
+		}
+	}
+	s.seen[key] = &repeat{last: now}
+	return 0, true
+}
+
+func (l *rateLimiter) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *l
+	c.next = l.next.WithAttrs(attrs)
+	return &c
+}
+
+func (l *rateLimiter) WithGroup(name string) slog.Handler {
+	c := *l
+	c.next = l.next.WithGroup(name)
+	return &c
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// CPUTimes holds cumulative CPU time counters (in ticks) summed across
+// all cores, as reported by the kernel
+type CPUTimes struct {
+	User    uint64
+	Nice    uint64
+	System  uint64
+	Idle    uint64
+	IOWait  uint64
+	IRQ     uint64
+	SoftIRQ uint64
+	Steal   uint64
+}
+
+func (t CPUTimes) total() uint64 {
+	return t.User + t.Nice + t.System + t.Idle + t.IOWait + t.IRQ + t.SoftIRQ + t.Steal
+}
+
+func (t CPUTimes) idle() uint64 {
+	return t.Idle + t.IOWait
+}
+
+// CPUPercent returns CPU usage between two samples (0.0 - 100.0). ok is
+// false when no ticks elapsed, in which case callers should keep their
+// last reading (mirrors cpu_delta in the C probe).
+func CPUPercent(prev, cur CPUTimes) (percent float64, ok bool) {
+	total := cur.total() - prev.total()
+	if total == 0 || cur.total() < prev.total() {
+		return 0, false
+	}
+	idle := cur.idle() - prev.idle()
+	if idle > total {
+		idle = total
+	}
+	return 100 * float64(total-idle) / float64(total), true
+}
+
+// parseProcStat parses the aggregate "cpu" line of /proc/stat
+func parseProcStat(data string) (CPUTimes, error) {
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+		var vals [8]uint64
+		for i := 0; i < len(vals) && i+1 < len(fields); i++ {
+			v, err := strconv.ParseUint(fields[i+1], 10, 64)
+			if err != nil {
+				return CPUTimes{}, err
+			}
+			vals[i] = v
+		}
+		return CPUTimes{
+			User: vals[0], Nice: vals[1], System: vals[2], Idle: vals[3],
+			IOWait: vals[4], IRQ: vals[5], SoftIRQ: vals[6], Steal: vals[7],
+		}, nil
+	}
+	return CPUTimes{}, errors.New("no aggregate cpu line in /proc/stat")
+}
@@ -0,0 +1,9 @@
+package metrics
+
+import "errors"
+
+// ReadCPUTimes is not available without cgo on macOS; the C probe reads
+// CPU ticks from the Mach host_processor_info API instead
+func ReadCPUTimes() (CPUTimes, error) {
+	return CPUTimes{}, errors.New("CPU counters are read by the C probe on macOS")
+}
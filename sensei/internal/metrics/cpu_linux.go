@@ -0,0 +1,12 @@
+package metrics
+
+import "os"
+
+// ReadCPUTimes reads the aggregate CPU counters from /proc/stat
+func ReadCPUTimes() (CPUTimes, error) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return CPUTimes{}, err
+	}
+	return parseProcStat(string(data))
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import "testing"
+
+func TestParseProcStat(t *testing.T) {
+	data := `cpu  4705 356 584 3699 23 0 7 0 0 0
+cpu0 1393 280 260 1005 11 0 4 0 0 0
+intr 114930548 113199788 3 0 5 263 0 4 [...]
+`
+	times, err := parseProcStat(data)
+	if err != nil {
+		t.Fatalf("parseProcStat failed: %v", err)
+	}
+	want := CPUTimes{User: 4705, Nice: 356, System: 584, Idle: 3699, IOWait: 23, SoftIRQ: 7}
+	if times != want {
+		t.Errorf("parseProcStat = %+v, expected %+v", times, want)
+	}
+}
+
+func TestCPUPercent(t *testing.T) {
+	prev := CPUTimes{User: 100, System: 50, Idle: 800, IOWait: 50}
+	cur := CPUTimes{User: 160, System: 70, Idle: 890, IOWait: 60}
+
+	pct, ok := CPUPercent(prev, cur)
+	if !ok {
+		t.Fatal("Expected a valid reading")
+	}
+	// 80 active ticks out of 180
+	if pct < 44.4 || pct > 44.5 {
+		t.Errorf("CPUPercent = %f, expected ~44.4", pct)
+	}
+
+	if _, ok := CPUPercent(cur, cur); ok {
+		t.Error("Expected no reading when no ticks elapsed")
+	}
+}
@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sectorSize is the unit /proc/diskstats counts in, regardless of the
+// device's real sector size
+const sectorSize = 512
+
+// DiskCounters holds cumulative I/O counters for one block device
+type DiskCounters struct {
+	Name         string
+	Reads        uint64 // completed read requests
+	Writes       uint64 // completed write requests
+	BytesRead    uint64
+	BytesWritten uint64
+}
+
+// DiskRate is the I/O on one block device between two samples
+type DiskRate struct {
+	Name             string
+	ReadBytesPerSec  float64
+	WriteBytesPerSec float64
+	ReadIOPS         float64
+	WriteIOPS        float64
+}
+
+// DiskRates computes per-device rates between two samples taken elapsed
+// apart. Devices missing from prev, or whose counters went backwards,
+// are skipped. The result is sorted by name.
+func DiskRates(prev, cur []DiskCounters, elapsed time.Duration) []DiskRate {
+	secs := elapsed.Seconds()
+	if secs <= 0 {
+		return nil
+	}
+
+	before := make(map[string]DiskCounters, len(prev))
+	for _, c := range prev {
+		before[c.Name] = c
+	}
+
+	var rates []DiskRate
+	for _, c := range cur {
+		p, ok := before[c.Name]
+		if !ok || c.Reads < p.Reads || c.Writes < p.Writes ||
+			c.BytesRead < p.BytesRead || c.BytesWritten < p.BytesWritten {
+			continue
+		}
+		rates = append(rates, DiskRate{
+			Name:             c.Name,
+			ReadBytesPerSec:  float64(c.BytesRead-p.BytesRead) / secs,
+			WriteBytesPerSec: float64(c.BytesWritten-p.BytesWritten) / secs,
+			ReadIOPS:         float64(c.Reads-p.Reads) / secs,
+			WriteIOPS:        float64(c.Writes-p.Writes) / secs,
+		})
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Name < rates[j].Name })
+	return rates
+}
+
+// parseDiskstats parses /proc/diskstats, keeping whole disks only:
+//
+//	8       0 sda 4512 120 301234 1500 9876 4321 765432 8800 0 9000 10300
+//	8       1 sda1 4400 118 300000 1490 9870 4320 765400 8790 0 8990 10280
+//
+// Partitions are dropped when their parent disk is listed, as are loop
+// and RAM devices, so traffic isn't counted twice.
+func parseDiskstats(data string) ([]DiskCounters, error) {
+	var all []DiskCounters
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		name := fields[2]
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") {
+			continue
+		}
+		// reads completed, reads merged, sectors read, ms reading,
+		// writes completed, writes merged, sectors written
+		vals, err := parseUints(fields[3:10])
+		if err != nil {
+			return nil, fmt.Errorf("device %s: %w", name, err)
+		}
+		all = append(all, DiskCounters{
+			Name:         name,
+			Reads:        vals[0],
+			BytesRead:    vals[2] * sectorSize,
+			Writes:       vals[4],
+			BytesWritten: vals[6] * sectorSize,
+		})
+	}
+
+	names := make(map[string]bool, len(all))
+	for _, d := range all {
+		names[d.Name] = true
+	}
+	var disks []DiskCounters
+	for _, d := range all {
+		if !isPartition(d.Name, names) {
+			disks = append(disks, d)
+		}
+	}
+	return disks, nil
+}
+
+// isPartition reports whether name is a partition of another listed
+// device, e.g. sda1 of sda or nvme0n1p2 of nvme0n1
+func isPartition(name string, names map[string]bool) bool {
+	for i := len(name) - 1; i > 0; i-- {
+		if name[i] < '0' || name[i] > '9' {
+			if i == len(name)-1 {
+				return false
+			}
+			parent := name[:i+1]
+			if names[parent] {
+				return true
+			}
+			// nvme0n1p2, mmcblk0p1
+			return name[i] == 'p' && names[name[:i]]
+		}
+	}
+	return false
+}
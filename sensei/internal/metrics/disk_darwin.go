@@ -0,0 +1,9 @@
+package metrics
+
+import "errors"
+
+// ReadDiskCounters is not available on macOS, where per-device I/O
+// statistics are only exposed through IOKit
+func ReadDiskCounters() ([]DiskCounters, error) {
+	return nil, errors.New("per-disk I/O counters are not available on macOS")
+}
@@ -0,0 +1,12 @@
+package metrics
+
+import "os"
+
+// ReadDiskCounters reads per-disk I/O counters from /proc/diskstats
+func ReadDiskCounters() ([]DiskCounters, error) {
+	data, err := os.ReadFile("/proc/diskstats")
+	if err != nil {
+		return nil, err
+	}
+	return parseDiskstats(string(data))
+}
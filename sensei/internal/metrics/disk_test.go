@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDiskstats(t *testing.T) {
+	data := `   7       0 loop0 50 0 400 10 0 0 0 0 0 20 10 0 0 0 0
+   8       0 sda 4512 120 301234 1500 9876 4321 765432 8800 0 9000 10300
+   8       1 sda1 4400 118 300000 1490 9870 4320 765400 8790 0 8990 10280
+ 259       0 nvme0n1 1000 0 2000 100 500 0 1000 50 0 120 150
+ 259       1 nvme0n1p1 900 0 1800 90 400 0 900 40 0 110 130
+ 253       0 dm-0 300 0 600 30 200 0 400 20 0 40 50
+`
+	disks, err := parseDiskstats(data)
+	if err != nil {
+		t.Fatalf("parseDiskstats failed: %v", err)
+	}
+
+	var names []string
+	for _, d := range disks {
+		names = append(names, d.Name)
+	}
+	if len(disks) != 3 || names[0] != "sda" || names[1] != "nvme0n1" || names[2] != "dm-0" {
+		t.Fatalf("Expected sda, nvme0n1 and dm-0, got %v", names)
+	}
+
+	want := DiskCounters{Name: "sda", Reads: 4512, Writes: 9876, BytesRead: 301234 * 512, BytesWritten: 765432 * 512}
+	if disks[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, disks[0])
+	}
+}
+
+func TestDiskRates(t *testing.T) {
+	prev := []DiskCounters{{Name: "sda", Reads: 100, Writes: 50, BytesRead: 4096, BytesWritten: 8192}}
+	cur := []DiskCounters{
+		{Name: "sda", Reads: 300, Writes: 150, BytesRead: 4096 + 2*1024*1024, BytesWritten: 8192 + 1024*1024},
+		{Name: "sdb", Reads: 5},
+	}
+
+	rates := DiskRates(prev, cur, 2*time.Second)
+	if len(rates) != 1 {
+		t.Fatalf("Expected a rate for sda only, got %+v", rates)
+	}
+	r := rates[0]
+	if r.ReadIOPS != 100 || r.WriteIOPS != 50 || r.ReadBytesPerSec != 1024*1024 || r.WriteBytesPerSec != 512*1024 {
+		t.Errorf("Unexpected sda rate: %+v", r)
+	}
+}
+
+func TestParseMounts(t *testing.T) {
+	data := `proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+/dev/nvme0n1p2 / ext4 rw,relatime 0 0
+/dev/nvme0n1p1 /boot/efi vfat rw,relatime 0 0
+/dev/loop3 /snap/core/1 squashfs ro,nodev,relatime 0 0
+/dev/sdb1 /mnt/USB\040Drive exfat rw,relatime 0 0
+/dev/nvme0n1p2 / ext4 rw,relatime 0 0
+`
+	mounts := parseMounts(data)
+	if len(mounts) != 3 {
+		t.Fatalf("Expected 3 mounts, got %+v", mounts)
+	}
+	if mounts[0] != (mountEntry{device: "/dev/nvme0n1p2", mount: "/", fsType: "ext4"}) {
+		t.Errorf("Unexpected root mount: %+v", mounts[0])
+	}
+	if mounts[2].mount != "/mnt/USB Drive" {
+		t.Errorf("Expected escaped space to be decoded, got %q", mounts[2].mount)
+	}
+}
+
+func TestFilesystemPercentages(t *testing.T) {
+	// 100 GB disk, 10 GB free of which 5 GB reserved for root
+	fs := Filesystem{Total: 100, Free: 10, Available: 5, Inodes: 1000, InodesFree: 250}
+	if got := fs.UsedPercent(); got < 94.7 || got > 94.8 {
+		t.Errorf("Expected ~94.7%% used, got %.2f", got)
+	}
+	if got := fs.InodePercent(); got != 75 {
+		t.Errorf("Expected 75%% inodes used, got %.2f", got)
+	}
+	if (Filesystem{}).UsedPercent() != 0 || (Filesystem{}).InodePercent() != 0 {
+		t.Error("Empty filesystem should report 0%")
+	}
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"bufio"
+	"strings"
+)
+
+// Filesystem is the space and inode usage of one mounted filesystem
+type Filesystem struct {
+	Mount      string
+	Device     string
+	Type       string
+	Total      uint64 // bytes
+	Free       uint64 // bytes free, including space reserved for root
+	Available  uint64 // bytes available to unprivileged users
+	Inodes     uint64
+	InodesFree uint64
+}
+
+// UsedPercent returns the percentage of space in use, counting reserved
+// blocks as used like df does
+func (f Filesystem) UsedPercent() float64 {
+	used := f.Total - f.Free
+	if used+f.Available == 0 {
+		return 0
+	}
+	return float64(used) / float64(used+f.Available) * 100
+}
+
+// InodePercent returns the percentage of inodes in use, or 0 for
+// filesystems without a fixed inode table
+func (f Filesystem) InodePercent() float64 {
+	if f.Inodes == 0 {
+		return 0
+	}
+	return float64(f.Inodes-f.InodesFree) / float64(f.Inodes) * 100
+}
+
+// mountEntry is one line of /proc/self/mounts
+type mountEntry struct {
+	device, mount, fsType string
+}
+
+// parseMounts parses /proc/self/mounts, keeping filesystems backed by a
+// device and the first mount of each mount point:
+//
+//	/dev/nvme0n1p2 / ext4 rw,relatime 0 0
+//	proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+func parseMounts(data string) []mountEntry {
+	var mounts []mountEntry
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || !strings.HasPrefix(fields[0], "/") || fields[2] == "squashfs" {
+			continue
+		}
+		mount := unescapeMount(fields[1])
+		if seen[mount] {
+			continue
+		}
+		seen[mount] = true
+		mounts = append(mounts, mountEntry{device: fields[0], mount: mount, fsType: fields[2]})
+	}
+	return mounts
+}
+
+// unescapeMount decodes the octal escapes (\040 for a space) the kernel
+// uses in mount paths
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) && isOctal(s[i+1]) && isOctal(s[i+2]) && isOctal(s[i+3]) {
+			b.WriteByte((s[i+1]-'0')<<6 | (s[i+2]-'0')<<3 | (s[i+3] - '0'))
+			i += 3
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isOctal(c byte) bool {
+	return c >= '0' && c <= '7'
+}
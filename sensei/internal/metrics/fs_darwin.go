@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// ReadFilesystems reports space and inode usage for every local,
+// user-visible mounted filesystem
+func ReadFilesystems() ([]Filesystem, error) {
+	n, err := unix.Getfsstat(nil, unix.MNT_NOWAIT)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]unix.Statfs_t, n)
+	n, err = unix.Getfsstat(stats, unix.MNT_NOWAIT)
+	if err != nil {
+		return nil, err
+	}
+
+	var filesystems []Filesystem
+	for _, st := range stats[:n] {
+		device := unix.ByteSliceToString(st.Mntfromname[:])
+		// Hidden APFS system volumes are flagged MNT_DONTBROWSE
+		if !strings.HasPrefix(device, "/dev/") || st.Flags&unix.MNT_DONTBROWSE != 0 {
+			continue
+		}
+		bsize := uint64(st.Bsize)
+		filesystems = append(filesystems, Filesystem{
+			Mount:      unix.ByteSliceToString(st.Mntonname[:]),
+			Device:     device,
+			Type:       unix.ByteSliceToString(st.Fstypename[:]),
+			Total:      st.Blocks * bsize,
+			Free:       st.Bfree * bsize,
+			Available:  st.Bavail * bsize,
+			Inodes:     st.Files,
+			InodesFree: st.Ffree,
+		})
+	}
+	return filesystems, nil
+}
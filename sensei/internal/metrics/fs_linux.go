@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// ReadFilesystems reports space and inode usage for every mounted
+// filesystem backed by a device
+func ReadFilesystems() ([]Filesystem, error) {
+	data, err := os.ReadFile("/proc/self/mounts")
+	if err != nil {
+		return nil, err
+	}
+
+	var filesystems []Filesystem
+	for _, m := range parseMounts(string(data)) {
+		var st unix.Statfs_t
+		if err := unix.Statfs(m.mount, &st); err != nil {
+			// Unreadable or stale mounts (e.g. a dead network share) are skipped
+			continue
+		}
+		bsize := uint64(st.Bsize)
+		filesystems = append(filesystems, Filesystem{
+			Mount:      m.mount,
+			Device:     m.device,
+			Type:       m.fsType,
+			Total:      st.Blocks * bsize,
+			Free:       st.Bfree * bsize,
+			Available:  st.Bavail * bsize,
+			Inodes:     st.Files,
+			InodesFree: st.Ffree,
+		})
+	}
+	return filesystems, nil
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Load holds the 1, 5 and 15 minute load averages, plus CPU and I/O
+// pressure stall information on Linux
+type Load struct {
+	Load1       float64   `json:"load1"`
+	Load5       float64   `json:"load5"`
+	Load15      float64   `json:"load15"`
+	CPUPressure *Pressure `json:"cpu_pressure,omitempty"`
+	IOPressure  *Pressure `json:"io_pressure,omitempty"`
+}
+
+// parseLoadavg parses /proc/loadavg, e.g. "0.52 0.58 0.59 2/1234 5678"
+func parseLoadavg(data string) (Load, error) {
+	fields := strings.Fields(data)
+	if len(fields) < 3 {
+		return Load{}, fmt.Errorf("unexpected loadavg format %q", data)
+	}
+	var vals [3]float64
+	for i := range vals {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return Load{}, err
+		}
+		vals[i] = v
+	}
+	return Load{Load1: vals[0], Load5: vals[1], Load15: vals[2]}, nil
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// ReadLoad reads load averages from the vm.loadavg sysctl
+func ReadLoad() (Load, error) {
+	// struct loadavg { fixpt_t ldavg[3]; long fscale; }
+	raw, err := unix.SysctlRaw("vm.loadavg")
+	if err != nil {
+		return Load{}, err
+	}
+	if len(raw) < 24 {
+		return Load{}, fmt.Errorf("vm.loadavg: unexpected size %d", len(raw))
+	}
+	scale := float64(binary.LittleEndian.Uint64(raw[16:24]))
+	if scale == 0 {
+		return Load{}, fmt.Errorf("vm.loadavg: zero fscale")
+	}
+	return Load{
+		Load1:  float64(binary.LittleEndian.Uint32(raw[0:4])) / scale,
+		Load5:  float64(binary.LittleEndian.Uint32(raw[4:8])) / scale,
+		Load15: float64(binary.LittleEndian.Uint32(raw[8:12])) / scale,
+	}, nil
+}
@@ -0,0 +1,24 @@
+package metrics
+
+import "os"
+
+// ReadLoad reads load averages from /proc/loadavg, with CPU and I/O
+// pressure from /proc/pressure when available
+func ReadLoad() (Load, error) {
+	data, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return Load{}, err
+	}
+	load, err := parseLoadavg(string(data))
+	if err != nil {
+		return Load{}, err
+	}
+
+	if p, err := readPressure("cpu"); err == nil {
+		load.CPUPressure = &p
+	}
+	if p, err := readPressure("io"); err == nil {
+		load.IOPressure = &p
+	}
+	return load, nil
+}
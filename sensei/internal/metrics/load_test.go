@@ -0,0 +1,17 @@
+package metrics
+
+import "testing"
+
+func TestParseLoadavg(t *testing.T) {
+	load, err := parseLoadavg("3.52 1.58 0.59 2/1234 5678\n")
+	if err != nil {
+		t.Fatalf("parseLoadavg failed: %v", err)
+	}
+	if load.Load1 != 3.52 || load.Load5 != 1.58 || load.Load15 != 0.59 {
+		t.Errorf("Unexpected load: %+v", load)
+	}
+
+	if _, err := parseLoadavg("garbage"); err == nil {
+		t.Error("Expected an error for malformed input")
+	}
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+)
+
+// Memory is a snapshot of physical memory and swap usage in bytes
+type Memory struct {
+	Total     uint64    `json:"total"`
+	Used      uint64    `json:"used"`
+	Available uint64    `json:"available"`
+	Cached    uint64    `json:"cached"`
+	SwapTotal uint64    `json:"swap_total"`
+	SwapUsed  uint64    `json:"swap_used"`
+	Pressure  *Pressure `json:"pressure,omitempty"` // Linux PSI, nil elsewhere
+}
+
+// UsedPercent returns used memory as a percentage of total
+func (m Memory) UsedPercent() float64 {
+	if m.Total == 0 {
+		return 0
+	}
+	return 100 * float64(m.Used) / float64(m.Total)
+}
+
+// SwapPercent returns used swap as a percentage of total swap
+func (m Memory) SwapPercent() float64 {
+	if m.SwapTotal == 0 {
+		return 0
+	}
+	return 100 * float64(m.SwapUsed) / float64(m.SwapTotal)
+}
+
+// parseMeminfo parses the contents of /proc/meminfo
+func parseMeminfo(data string) Memory {
+	fields := make(map[string]uint64)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		// Lines look like "MemTotal:       16314320 kB"
+		key, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		parts := strings.Fields(rest)
+		if len(parts) == 0 {
+			continue
+		}
+		val, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(parts) > 1 && parts[1] == "kB" {
+			val *= 1024
+		}
+		fields[key] = val
+	}
+
+	m := Memory{
+		Total:     fields["MemTotal"],
+		Available: fields["MemAvailable"],
+		Cached:    fields["Cached"] + fields["Buffers"] + fields["SReclaimable"],
+		SwapTotal: fields["SwapTotal"],
+	}
+	if m.Available > m.Total {
+		m.Available = m.Total
+	}
+	m.Used = m.Total - m.Available
+	if free := fields["SwapFree"]; free <= m.SwapTotal {
+		m.SwapUsed = m.SwapTotal - free
+	}
+	return m
+}
+
+// parseVmStat parses the output of macOS vm_stat into page counts keyed
+// by label, e.g. "Pages free"
+func parseVmStat(output string) map[string]uint64 {
+	pages := make(map[string]uint64)
+	for _, line := range strings.Split(output, "\n") {
+		key, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		s := strings.TrimSuffix(strings.TrimSpace(rest), ".")
+		val, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			continue
+		}
+		pages[strings.TrimSpace(key)] = val
+	}
+	return pages
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"encoding/binary"
+	"os/exec"
+
+	"golang.org/x/sys/unix"
+)
+
+// ReadMemory reads memory usage from sysctl and vm_stat. Available
+// memory counts free and inactive pages; cached counts file-backed pages.
+func ReadMemory() (Memory, error) {
+	total, err := unix.SysctlUint64("hw.memsize")
+	if err != nil {
+		return Memory{}, err
+	}
+	m := Memory{Total: total}
+
+	out, err := exec.Command("vm_stat").Output()
+	if err != nil {
+		return m, err
+	}
+	pages := parseVmStat(string(out))
+	pageSize := uint64(unix.Getpagesize())
+
+	m.Available = (pages["Pages free"] + pages["Pages inactive"]) * pageSize
+	if m.Available > total {
+		m.Available = total
+	}
+	m.Used = total - m.Available
+	m.Cached = pages["File-backed pages"] * pageSize
+
+	// vm.swapusage is a struct xsw_usage: total, avail, used (uint64) ...
+	if raw, err := unix.SysctlRaw("vm.swapusage"); err == nil && len(raw) >= 24 {
+		m.SwapTotal = binary.LittleEndian.Uint64(raw[0:8])
+		m.SwapUsed = binary.LittleEndian.Uint64(raw[16:24])
+	}
+	return m, nil
+}
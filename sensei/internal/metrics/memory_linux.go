@@ -0,0 +1,18 @@
+package metrics
+
+import "os"
+
+// ReadMemory reads memory and swap usage from /proc/meminfo, with
+// pressure stall information from /proc/pressure/memory when available
+func ReadMemory() (Memory, error) {
+	data, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return Memory{}, err
+	}
+	m := parseMeminfo(string(data))
+
+	if p, err := readPressure("memory"); err == nil {
+		m.Pressure = &p
+	}
+	return m, nil
+}
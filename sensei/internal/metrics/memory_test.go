@@ -0,0 +1,62 @@
+package metrics
+
+import "testing"
+
+func TestParseMeminfo(t *testing.T) {
+	data := `MemTotal:       16000000 kB
+MemFree:         2000000 kB
+MemAvailable:    6000000 kB
+Buffers:          100000 kB
+Cached:          3000000 kB
+SwapCached:        10000 kB
+SReclaimable:     200000 kB
+SwapTotal:       4000000 kB
+SwapFree:        3000000 kB
+HugePages_Total:       0
+`
+	m := parseMeminfo(data)
+
+	const kB = 1024
+	if m.Total != 16000000*kB {
+		t.Errorf("Total = %d", m.Total)
+	}
+	if m.Available != 6000000*kB || m.Used != 10000000*kB {
+		t.Errorf("Available = %d, Used = %d", m.Available, m.Used)
+	}
+	if m.Cached != 3300000*kB {
+		t.Errorf("Cached = %d, expected Cached+Buffers+SReclaimable", m.Cached)
+	}
+	if m.SwapTotal != 4000000*kB || m.SwapUsed != 1000000*kB {
+		t.Errorf("SwapTotal = %d, SwapUsed = %d", m.SwapTotal, m.SwapUsed)
+	}
+	if p := m.UsedPercent(); p != 62.5 {
+		t.Errorf("UsedPercent = %f, expected 62.5", p)
+	}
+	if p := m.SwapPercent(); p != 25 {
+		t.Errorf("SwapPercent = %f, expected 25", p)
+	}
+}
+
+func TestParseVmStat(t *testing.T) {
+	output := `Mach Virtual Memory Statistics: (page size of 16384 bytes)
+Pages free:                               12345.
+Pages active:                            400000.
+Pages inactive:                          300000.
+File-backed pages:                       250000.
+`
+	pages := parseVmStat(output)
+	if pages["Pages free"] != 12345 || pages["Pages inactive"] != 300000 || pages["File-backed pages"] != 250000 {
+		t.Errorf("Unexpected pages: %v", pages)
+	}
+}
+
+func TestParsePressure(t *testing.T) {
+	data := "some avg10=1.50 avg60=0.75 avg300=0.10 total=123456\n" +
+		"full avg10=0.25 avg60=0.05 avg300=0.00 total=789\n"
+
+	p := ParsePressure(data)
+	want := Pressure{SomeAvg10: 1.5, SomeAvg60: 0.75, SomeAvg300: 0.1, FullAvg10: 0.25, FullAvg60: 0.05}
+	if p != want {
+		t.Errorf("ParsePressure = %+v, expected %+v", p, want)
+	}
+}
@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NetCounters holds cumulative traffic counters for one interface
+type NetCounters struct {
+	Name      string
+	RxBytes   uint64
+	TxBytes   uint64
+	RxPackets uint64
+	TxPackets uint64
+	RxErrors  uint64
+	TxErrors  uint64
+}
+
+// NetRate is the traffic on one interface between two samples
+type NetRate struct {
+	Name            string
+	RxBytesPerSec   float64
+	TxBytesPerSec   float64
+	RxPacketsPerSec float64
+	TxPacketsPerSec float64
+	RxErrors        uint64 // cumulative
+	TxErrors        uint64 // cumulative
+}
+
+// NetTotals is the traffic summed across all non-loopback interfaces,
+// as streamed over the pipe
+type NetTotals struct {
+	RxBytesPerSec   float64 `json:"rx_bytes_per_sec"`
+	TxBytesPerSec   float64 `json:"tx_bytes_per_sec"`
+	RxPacketsPerSec float64 `json:"rx_packets_per_sec"`
+	TxPacketsPerSec float64 `json:"tx_packets_per_sec"`
+	RxErrors        uint64  `json:"rx_errors"`
+	TxErrors        uint64  `json:"tx_errors"`
+}
+
+// IsLoopback reports whether name looks like a loopback interface
+func IsLoopback(name string) bool {
+	return strings.HasPrefix(name, "lo")
+}
+
+// NetRates computes per-interface rates between two samples taken
+// elapsed apart. Interfaces missing from prev, or whose counters went
+// backwards (reset), are skipped. The result is sorted by name.
+func NetRates(prev, cur []NetCounters, elapsed time.Duration) []NetRate {
+	secs := elapsed.Seconds()
+	if secs <= 0 {
+		return nil
+	}
+
+	before := make(map[string]NetCounters, len(prev))
+	for _, c := range prev {
+		before[c.Name] = c
+	}
+
+	var rates []NetRate
+	for _, c := range cur {
+		p, ok := before[c.Name]
+		if !ok || c.RxBytes < p.RxBytes || c.TxBytes < p.TxBytes ||
+			c.RxPackets < p.RxPackets || c.TxPackets < p.TxPackets {
+			continue
+		}
+		rates = append(rates, NetRate{
+			Name:            c.Name,
+			RxBytesPerSec:   float64(c.RxBytes-p.RxBytes) / secs,
+			TxBytesPerSec:   float64(c.TxBytes-p.TxBytes) / secs,
+			RxPacketsPerSec: float64(c.RxPackets-p.RxPackets) / secs,
+			TxPacketsPerSec: float64(c.TxPackets-p.TxPackets) / secs,
+			RxErrors:        c.RxErrors,
+			TxErrors:        c.TxErrors,
+		})
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Name < rates[j].Name })
+	return rates
+}
+
+// SumNetRates totals the rates of every non-loopback interface
+func SumNetRates(rates []NetRate) NetTotals {
+	var t NetTotals
+	for _, r := range rates {
+		if IsLoopback(r.Name) {
+			continue
+		}
+		t.RxBytesPerSec += r.RxBytesPerSec
+		t.TxBytesPerSec += r.TxBytesPerSec
+		t.RxPacketsPerSec += r.RxPacketsPerSec
+		t.TxPacketsPerSec += r.TxPacketsPerSec
+		t.RxErrors += r.RxErrors
+		t.TxErrors += r.TxErrors
+	}
+	return t
+}
+
+// parseProcNetDev parses /proc/net/dev:
+//
+//	Inter-|   Receive                                                |  Transmit
+//	 face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs ...
+//	  eth0: 1234567    8910    0    0    0     0          0         0  7654321    5432    0 ...
+func parseProcNetDev(data string) ([]NetCounters, error) {
+	var counters []NetCounters
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) < 11 {
+			continue
+		}
+		vals, err := parseUints(fields[:11])
+		if err != nil {
+			return nil, fmt.Errorf("interface %s: %w", strings.TrimSpace(name), err)
+		}
+		counters = append(counters, NetCounters{
+			Name:      strings.TrimSpace(name),
+			RxBytes:   vals[0],
+			RxPackets: vals[1],
+			RxErrors:  vals[2],
+			TxBytes:   vals[8],
+			TxPackets: vals[9],
+			TxErrors:  vals[10],
+		})
+	}
+	return counters, nil
+}
+
+// parseNetstat parses the link-level rows of macOS `netstat -ibn`:
+//
+//	Name  Mtu   Network    Address            Ipkts Ierrs  Ibytes  Opkts Oerrs  Obytes  Coll
+//	en0   1500  <Link#6>   a4:83:e7:01:02:03  98765     0 1234567  54321     0  987654     0
+//
+// The address column is empty for some interfaces, so the counters are
+// read from the end of the line.
+func parseNetstat(output string) ([]NetCounters, error) {
+	var counters []NetCounters
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 10 || !strings.HasPrefix(fields[2], "<Link#") || seen[fields[0]] {
+			continue
+		}
+		vals, err := parseUints(fields[len(fields)-7 : len(fields)-1])
+		if err != nil {
+			return nil, fmt.Errorf("interface %s: %w", fields[0], err)
+		}
+		seen[fields[0]] = true
+		counters = append(counters, NetCounters{
+			Name:      fields[0],
+			RxPackets: vals[0],
+			RxErrors:  vals[1],
+			RxBytes:   vals[2],
+			TxPackets: vals[3],
+			TxErrors:  vals[4],
+			TxBytes:   vals[5],
+		})
+	}
+	return counters, nil
+}
+
+func parseUints(fields []string) ([]uint64, error) {
+	vals := make([]uint64, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
@@ -0,0 +1,12 @@
+package metrics
+
+import "os/exec"
+
+// ReadNetCounters reads per-interface traffic counters from netstat
+func ReadNetCounters() ([]NetCounters, error) {
+	out, err := exec.Command("netstat", "-ibn").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseNetstat(string(out))
+}
@@ -0,0 +1,12 @@
+package metrics
+
+import "os"
+
+// ReadNetCounters reads per-interface traffic counters from /proc/net/dev
+func ReadNetCounters() ([]NetCounters, error) {
+	data, err := os.ReadFile("/proc/net/dev")
+	if err != nil {
+		return nil, err
+	}
+	return parseProcNetDev(string(data))
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseProcNetDev(t *testing.T) {
+	data := `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:  123456     100    0    0    0     0          0         0   123456     100    0    0    0     0       0          0
+  eth0: 9000000    6000    2    0    0     0          0         0  3000000    4000    1    0    0     0       0          0
+`
+	counters, err := parseProcNetDev(data)
+	if err != nil {
+		t.Fatalf("parseProcNetDev failed: %v", err)
+	}
+	if len(counters) != 2 {
+		t.Fatalf("Expected 2 interfaces, got %d", len(counters))
+	}
+	want := NetCounters{Name: "eth0", RxBytes: 9000000, RxPackets: 6000, RxErrors: 2, TxBytes: 3000000, TxPackets: 4000, TxErrors: 1}
+	if counters[1] != want {
+		t.Errorf("Expected %+v, got %+v", want, counters[1])
+	}
+}
+
+func TestParseNetstat(t *testing.T) {
+	output := `Name       Mtu   Network       Address            Ipkts Ierrs     Ibytes    Opkts Oerrs     Obytes  Coll
+lo0        16384 <Link#1>                          5000     0     800000     5000     0     800000     0
+lo0        16384 127           127.0.0.1           5000     -     800000     5000     -     800000     -
+en0        1500  <Link#6>    a4:83:e7:01:02:03    98765     3    1234567    54321     1     987654     0
+en0        1500  192.168.1     192.168.1.20       98000     -    1200000    54000     -     980000     -
+`
+	counters, err := parseNetstat(output)
+	if err != nil {
+		t.Fatalf("parseNetstat failed: %v", err)
+	}
+	if len(counters) != 2 {
+		t.Fatalf("Expected 2 link-level rows, got %d: %+v", len(counters), counters)
+	}
+	want := NetCounters{Name: "en0", RxPackets: 98765, RxErrors: 3, RxBytes: 1234567, TxPackets: 54321, TxErrors: 1, TxBytes: 987654}
+	if counters[1] != want {
+		t.Errorf("Expected %+v, got %+v", want, counters[1])
+	}
+	if counters[0].Name != "lo0" || counters[0].RxBytes != 800000 {
+		t.Errorf("Loopback row without an address parsed wrong: %+v", counters[0])
+	}
+}
+
+func TestNetRatesAndTotals(t *testing.T) {
+	prev := []NetCounters{
+		{Name: "lo", RxBytes: 1000, TxBytes: 1000},
+		{Name: "eth0", RxBytes: 1000, TxBytes: 500, RxPackets: 10, TxPackets: 5},
+		{Name: "wlan0", RxBytes: 5000, TxBytes: 5000},
+	}
+	cur := []NetCounters{
+		{Name: "lo", RxBytes: 9000, TxBytes: 9000},
+		{Name: "eth0", RxBytes: 3000, TxBytes: 1500, RxPackets: 30, TxPackets: 15, RxErrors: 1},
+		{Name: "wlan0", RxBytes: 100, TxBytes: 100}, // counters reset
+		{Name: "tun0", RxBytes: 100},                // new interface
+	}
+
+	rates := NetRates(prev, cur, 2*time.Second)
+	if len(rates) != 2 {
+		t.Fatalf("Expected rates for eth0 and lo only, got %+v", rates)
+	}
+	eth := rates[0]
+	if eth.Name != "eth0" || eth.RxBytesPerSec != 1000 || eth.TxBytesPerSec != 500 || eth.RxPacketsPerSec != 10 {
+		t.Errorf("Unexpected eth0 rate: %+v", eth)
+	}
+
+	totals := SumNetRates(rates)
+	if totals.RxBytesPerSec != 1000 || totals.TxBytesPerSec != 500 || totals.RxErrors != 1 {
+		t.Errorf("Totals should exclude loopback: %+v", totals)
+	}
+
+	if NetRates(prev, cur, 0) != nil {
+		t.Error("Expected no rates for a zero interval")
+	}
+}
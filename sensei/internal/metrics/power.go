@@ -0,0 +1,183 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Battery is the state of the main battery
+type Battery struct {
+	Percent float64 `json:"percent"`
+	State   string  `json:"state"`           // charging, discharging, full, not charging or unknown
+	Watts   float64 `json:"watts,omitempty"` // charge or discharge rate, zero if unknown
+}
+
+// Power holds battery, AC and thermal state. Zero temperatures and
+// frequencies mean the platform doesn't report them.
+type Power struct {
+	Battery    *Battery `json:"battery,omitempty"` // nil on machines without a battery
+	OnAC       bool     `json:"on_ac"`
+	CPUTempC   float64  `json:"cpu_temp_c,omitempty"`
+	CPUFreqMHz float64  `json:"cpu_freq_mhz,omitempty"` // average current frequency across cores
+	MaxFreqMHz float64  `json:"max_freq_mhz,omitempty"`
+}
+
+// throttleTempC is the temperature above which a slow CPU is assumed to
+// be thermally throttled rather than idling
+const throttleTempC = 85
+
+// Throttled reports whether the CPU is hot and running at under half
+// its maximum frequency
+func (p Power) Throttled() bool {
+	return p.CPUTempC >= throttleTempC && p.CPUFreqMHz > 0 && p.MaxFreqMHz > 0 &&
+		p.CPUFreqMHz < p.MaxFreqMHz*0.5
+}
+
+// cpuThermalTypes are thermal zone types that measure the CPU package,
+// in order of preference
+var cpuThermalTypes = []string{"x86_pkg_temp", "cpu-thermal", "cpu_thermal", "TCPU", "k10temp", "coretemp", "acpitz"}
+
+// readPowerSupplies reads batteries and AC adapters under
+// root/class/power_supply
+func readPowerSupplies(root string) (battery *Battery, onAC bool) {
+	dirs, _ := filepath.Glob(filepath.Join(root, "class", "power_supply", "*"))
+	for _, dir := range dirs {
+		switch readTrimmed(filepath.Join(dir, "type")) {
+		case "Mains", "USB", "USB_C":
+			if readTrimmed(filepath.Join(dir, "online")) == "1" {
+				onAC = true
+			}
+		case "Battery":
+			// Peripheral batteries (mice, headsets) report scope Device
+			if battery != nil || readTrimmed(filepath.Join(dir, "scope")) == "Device" {
+				continue
+			}
+			capacity, err := strconv.ParseFloat(readTrimmed(filepath.Join(dir, "capacity")), 64)
+			if err != nil {
+				continue
+			}
+			battery = &Battery{
+				Percent: capacity,
+				State:   strings.ToLower(readTrimmed(filepath.Join(dir, "status"))),
+				Watts:   batteryWatts(dir),
+			}
+		}
+	}
+	return battery, onAC
+}
+
+// batteryWatts reads power_now (µW), or derives it from current_now
+// (µA) and voltage_now (µV) on batteries that only report those
+func batteryWatts(dir string) float64 {
+	if uw, err := strconv.ParseFloat(readTrimmed(filepath.Join(dir, "power_now")), 64); err == nil {
+		return uw / 1e6
+	}
+	ua, errA := strconv.ParseFloat(readTrimmed(filepath.Join(dir, "current_now")), 64)
+	uv, errV := strconv.ParseFloat(readTrimmed(filepath.Join(dir, "voltage_now")), 64)
+	if errA != nil || errV != nil {
+		return 0
+	}
+	return ua * uv / 1e12
+}
+
+// readCPUTemp returns the CPU temperature in °C from the thermal zones
+// under root/class/thermal, preferring zones known to measure the CPU
+// and falling back to the hottest zone
+func readCPUTemp(root string) float64 {
+	zones, _ := filepath.Glob(filepath.Join(root, "class", "thermal", "thermal_zone*"))
+	temps := make(map[string]float64)
+	var hottest float64
+	for _, zone := range zones {
+		milli, err := strconv.ParseFloat(readTrimmed(filepath.Join(zone, "temp")), 64)
+		if err != nil {
+			continue
+		}
+		c := milli / 1000
+		temps[readTrimmed(filepath.Join(zone, "type"))] = c
+		hottest = max(hottest, c)
+	}
+	for _, t := range cpuThermalTypes {
+		if c, ok := temps[t]; ok {
+			return c
+		}
+	}
+	return hottest
+}
+
+// readCPUFreq returns the average current and the highest maximum
+// frequency in MHz from root/devices/system/cpu/cpu*/cpufreq
+func readCPUFreq(root string) (cur, maxFreq float64) {
+	dirs, _ := filepath.Glob(filepath.Join(root, "devices", "system", "cpu", "cpu[0-9]*", "cpufreq"))
+	var sum float64
+	var n int
+	for _, dir := range dirs {
+		if khz, err := strconv.ParseFloat(readTrimmed(filepath.Join(dir, "scaling_cur_freq")), 64); err == nil {
+			sum += khz / 1000
+			n++
+		}
+		if khz, err := strconv.ParseFloat(readTrimmed(filepath.Join(dir, "cpuinfo_max_freq")), 64); err == nil {
+			maxFreq = max(maxFreq, khz/1000)
+		}
+	}
+	if n == 0 {
+		return 0, maxFreq
+	}
+	return sum / float64(n), maxFreq
+}
+
+// readPowerFrom reads everything under a sysfs root such as /sys
+func readPowerFrom(root string) Power {
+	var p Power
+	p.Battery, p.OnAC = readPowerSupplies(root)
+	p.CPUTempC = readCPUTemp(root)
+	p.CPUFreqMHz, p.MaxFreqMHz = readCPUFreq(root)
+	return p
+}
+
+func readTrimmed(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// pmsetBattery matches the battery line of `pmset -g batt`:
+//
+//	-InternalBattery-0 (id=4653155)	85%; charging; 1:23 remaining present: true
+var pmsetBattery = regexp.MustCompile(`(\d+)%;\s*([^;]+);`)
+
+// parsePmset parses `pmset -g batt`:
+//
+//	Now drawing from 'AC Power'
+//	 -InternalBattery-0 (id=4653155)	85%; charging; 1:23 remaining present: true
+func parsePmset(output string) Power {
+	var p Power
+	lines := strings.Split(output, "\n")
+	if len(lines) > 0 {
+		p.OnAC = strings.Contains(lines[0], "'AC Power'")
+	}
+	for _, line := range lines[1:] {
+		if !strings.Contains(line, "InternalBattery") {
+			continue
+		}
+		m := pmsetBattery.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		pct, _ := strconv.ParseFloat(m[1], 64)
+		state := strings.TrimSpace(m[2])
+		switch state {
+		case "charged", "finishing charge":
+			state = "full"
+		case "AC attached":
+			state = "not charging"
+		}
+		p.Battery = &Battery{Percent: pct, State: state}
+		break
+	}
+	return p
+}
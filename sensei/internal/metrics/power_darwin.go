@@ -0,0 +1,13 @@
+package metrics
+
+import "os/exec"
+
+// ReadPower reads battery and AC state from pmset. CPU temperature and
+// frequency need the SMC on macOS and aren't reported.
+func ReadPower() (Power, error) {
+	out, err := exec.Command("pmset", "-g", "batt").Output()
+	if err != nil {
+		return Power{}, err
+	}
+	return parsePmset(string(out)), nil
+}
@@ -0,0 +1,6 @@
+package metrics
+
+// ReadPower reads battery, AC, CPU temperature and frequency from sysfs
+func ReadPower() (Power, error) {
+	return readPowerFrom("/sys"), nil
+}
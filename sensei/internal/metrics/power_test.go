@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeSysfs(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for path, data := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(data+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadPowerFromSysfs(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, map[string]string{
+		"class/power_supply/AC/type":                          "Mains",
+		"class/power_supply/AC/online":                        "1",
+		"class/power_supply/BAT0/type":                        "Battery",
+		"class/power_supply/BAT0/capacity":                    "83",
+		"class/power_supply/BAT0/status":                      "Charging",
+		"class/power_supply/BAT0/current_now":                 "1500000",
+		"class/power_supply/BAT0/voltage_now":                 "12000000",
+		"class/power_supply/hidpp_battery_0/type":             "Battery",
+		"class/power_supply/hidpp_battery_0/scope":            "Device",
+		"class/power_supply/hidpp_battery_0/capacity":         "20",
+		"class/thermal/thermal_zone0/type":                    "acpitz",
+		"class/thermal/thermal_zone0/temp":                    "95000",
+		"class/thermal/thermal_zone1/type":                    "x86_pkg_temp",
+		"class/thermal/thermal_zone1/temp":                    "71500",
+		"devices/system/cpu/cpu0/cpufreq/scaling_cur_freq":    "1200000",
+		"devices/system/cpu/cpu0/cpufreq/cpuinfo_max_freq":    "4800000",
+		"devices/system/cpu/cpu1/cpufreq/scaling_cur_freq":    "1800000",
+		"devices/system/cpu/cpu1/cpufreq/cpuinfo_max_freq":    "4800000",
+		"devices/system/cpu/cpufreq/policy0/scaling_cur_freq": "9999999",
+	})
+
+	p := readPowerFrom(root)
+	if !p.OnAC {
+		t.Error("Expected AC to be online")
+	}
+	if p.Battery == nil {
+		t.Fatal("Expected a battery")
+	}
+	if p.Battery.Percent != 83 || p.Battery.State != "charging" || p.Battery.Watts != 18 {
+		t.Errorf("Unexpected battery (the mouse battery must be ignored): %+v", p.Battery)
+	}
+	if p.CPUTempC != 71.5 {
+		t.Errorf("Expected the x86_pkg_temp zone over the hotter acpitz, got %.1f", p.CPUTempC)
+	}
+	if p.CPUFreqMHz != 1500 || p.MaxFreqMHz != 4800 {
+		t.Errorf("Expected 1500 / 4800 MHz, got %.0f / %.0f", p.CPUFreqMHz, p.MaxFreqMHz)
+	}
+	if p.Throttled() {
+		t.Error("A slow CPU at 71.5°C is idling, not throttled")
+	}
+	p.CPUTempC = 96
+	if !p.Throttled() {
+		t.Error("1.5 GHz of 4.8 GHz at 96°C should count as throttled")
+	}
+}
+
+func TestReadPowerFromDesktop(t *testing.T) {
+	p := readPowerFrom(t.TempDir())
+	if p.Battery != nil || p.OnAC || p.CPUTempC != 0 || p.Throttled() {
+		t.Errorf("Expected nothing reported on an empty sysfs, got %+v", p)
+	}
+}
+
+func TestParsePmset(t *testing.T) {
+	output := "Now drawing from 'Battery Power'\n -InternalBattery-0 (id=4653155)\t42%; discharging; 3:10 remaining present: true\n"
+	p := parsePmset(output)
+	if p.OnAC {
+		t.Error("Expected battery power")
+	}
+	if p.Battery == nil || p.Battery.Percent != 42 || p.Battery.State != "discharging" {
+		t.Errorf("Unexpected battery: %+v", p.Battery)
+	}
+
+	p = parsePmset("Now drawing from 'AC Power'\n -InternalBattery-0 (id=4653155)\t100%; charged; 0:00 remaining present: true\n")
+	if !p.OnAC || p.Battery == nil || p.Battery.State != "full" {
+		t.Errorf("Unexpected charged reading: %+v", p)
+	}
+
+	if p := parsePmset("Now drawing from 'AC Power'\n"); p.Battery != nil {
+		t.Errorf("Desktop Macs have no battery, got %+v", p.Battery)
+	}
+}
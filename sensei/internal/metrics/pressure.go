@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+)
+
+// Pressure holds Linux pressure stall information (PSI): the share of
+// wall time in which some or all tasks were stalled on a resource,
+// averaged over 10s, 60s and 300s windows
+type Pressure struct {
+	SomeAvg10  float64 `json:"some_avg10"`
+	SomeAvg60  float64 `json:"some_avg60"`
+	SomeAvg300 float64 `json:"some_avg300"`
+	FullAvg10  float64 `json:"full_avg10"`
+	FullAvg60  float64 `json:"full_avg60"`
+	FullAvg300 float64 `json:"full_avg300"`
+}
+
+// ParsePressure parses a /proc/pressure/* or cgroup *.pressure file:
+//
+//	some avg10=0.00 avg60=0.12 avg300=0.05 total=12345
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=678
+func ParsePressure(data string) Pressure {
+	var p Pressure
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		var avg10, avg60, avg300 *float64
+		switch fields[0] {
+		case "some":
+			avg10, avg60, avg300 = &p.SomeAvg10, &p.SomeAvg60, &p.SomeAvg300
+		case "full":
+			avg10, avg60, avg300 = &p.FullAvg10, &p.FullAvg60, &p.FullAvg300
+		default:
+			continue
+		}
+		for _, f := range fields[1:] {
+			key, val, ok := strings.Cut(f, "=")
+			if !ok {
+				continue
+			}
+			v, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				continue
+			}
+			switch key {
+			case "avg10":
+				*avg10 = v
+			case "avg60":
+				*avg60 = v
+			case "avg300":
+				*avg300 = v
+			}
+		}
+	}
+	return p
+}
@@ -0,0 +1,13 @@
+package metrics
+
+import "os"
+
+// readPressure reads /proc/pressure/<resource>. It fails on kernels
+// without PSI support.
+func readPressure(resource string) (Pressure, error) {
+	data, err := os.ReadFile("/proc/pressure/" + resource)
+	if err != nil {
+		return Pressure{}, err
+	}
+	return ParsePressure(string(data)), nil
+}
@@ -0,0 +1,5 @@
+// Package notify shows desktop notifications
+package notify
+
+// appName is shown as the sender of every notification
+const appName = "System Shinobi"
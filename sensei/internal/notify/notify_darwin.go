@@ -0,0 +1,19 @@
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Send shows a notification through Notification Center
+func Send(title, message string) error {
+	script := fmt.Sprintf("display notification %s with title %s subtitle %s",
+		quote(message), quote(appName), quote(title))
+	return exec.Command("osascript", "-e", script).Run()
+}
+
+// quote makes s an AppleScript string literal
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
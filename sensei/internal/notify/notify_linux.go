@@ -0,0 +1,8 @@
+package notify
+
+import "os/exec"
+
+// Send shows a notification through the desktop's notification daemon
+func Send(title, message string) error {
+	return exec.Command("notify-send", "--app-name", appName, title, message).Run()
+}
@@ -4,14 +4,24 @@ import (
 	"bufio"
 	"encoding/json"
 	"io"
-	"log"
+	"log/slog"
 	"os"
+
+	"system-shinobi/sensei/internal/metrics"
+	"system-shinobi/sensei/internal/selfstat"
 )
 
-// CpuReading represents a single CPU measurement from the probe
+// CpuReading represents a single measurement from the probe. Fields
+// other than the CPU percentage are optional and nil when the probe
+// doesn't report them.
 type CpuReading struct {
-	CpuPercent float64 `json:"cpu_percent"`
-	Timestamp  int64   `json:"timestamp"`
+	CpuPercent float64            `json:"cpu_percent"`
+	Timestamp  int64              `json:"timestamp"`
+	Memory     *metrics.Memory    `json:"memory,omitempty"`
+	Load       *metrics.Load      `json:"load,omitempty"`
+	Network    *metrics.NetTotals `json:"network,omitempty"`
+	Power      *metrics.Power     `json:"power,omitempty"`
+	Self       *selfstat.Report   `json:"self,omitempty"` // the probe's own overhead
 }
 
 // PipeReader reads CPU readings from a named pipe (FIFO)
@@ -27,17 +37,19 @@ func NewPipeReader(path string) (*PipeReader, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &PipeReader{
-		reader:   file,
-		readings: make(chan CpuReading, 10),
-		done:     make(chan struct{}),
-	}, nil
+	return NewPipeReaderFromReadCloser(file), nil
 }
 
 // NewPipeReaderFromReader creates a PipeReader from an io.Reader (for testing)
 func NewPipeReaderFromReader(r io.Reader) *PipeReader {
+	return NewPipeReaderFromReadCloser(io.NopCloser(r))
+}
+
+// NewPipeReaderFromReadCloser creates a PipeReader over any stream of
+// JSON lines, such as a replayed recording. Stop closes it.
+func NewPipeReaderFromReadCloser(r io.ReadCloser) *PipeReader {
 	return &PipeReader{
-		reader:   io.NopCloser(r),
+		reader:   r,
 		readings: make(chan CpuReading, 10),
 		done:     make(chan struct{}),
 	}
@@ -77,7 +89,7 @@ func (pr *PipeReader) readLoop() {
 		var reading CpuReading
 		if err := json.Unmarshal([]byte(line), &reading); err != nil {
 			// Skip malformed lines with a warning
-			log.Printf("Skipping malformed JSON line: %s (error: %v)", line, err)
+			slog.Warn("Skipping malformed JSON line", "line", line, "err", err)
 			continue
 		}
 
@@ -89,6 +101,11 @@ func (pr *PipeReader) readLoop() {
 	}
 
 	if err := scanner.Err(); err != nil {
-		log.Printf("Error reading from pipe: %v", err)
+		select {
+		case <-pr.done:
+			// Stop closed the reader under us
+		default:
+			slog.Error("Error reading from pipe", "err", err)
+		}
 	}
 }
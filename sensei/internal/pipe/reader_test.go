@@ -108,3 +108,85 @@ func TestHundredCpu(t *testing.T) {
 		t.Fatal("Timeout waiting for reading")
 	}
 }
+
+func TestParseMemoryReading(t *testing.T) {
+	input := `{"cpu_percent":12.0,"timestamp":1707860342,"memory":{"total":16000,"used":9000,"available":7000,"cached":3000,"swap_total":4000,"swap_used":1000,"pressure":{"some_avg10":2.5,"full_avg10":0.5}}}` + "\n"
+	reader := NewPipeReaderFromReader(strings.NewReader(input))
+	reader.Start()
+	defer reader.Stop()
+
+	select {
+	case reading := <-reader.Readings():
+		if reading.Memory == nil {
+			t.Fatal("Expected memory metrics")
+		}
+		if reading.Memory.Used != 9000 || reading.Memory.SwapUsed != 1000 || reading.Memory.Cached != 3000 {
+			t.Errorf("Unexpected memory metrics: %+v", reading.Memory)
+		}
+		if reading.Memory.Pressure == nil || reading.Memory.Pressure.SomeAvg10 != 2.5 {
+			t.Errorf("Unexpected memory pressure: %+v", reading.Memory.Pressure)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Timeout waiting for reading")
+	}
+}
+
+func TestCpuOnlyReadingHasNoMemory(t *testing.T) {
+	input := `{"cpu_percent":45.3,"timestamp":1707860342}` + "\n"
+	reader := NewPipeReaderFromReader(strings.NewReader(input))
+	reader.Start()
+	defer reader.Stop()
+
+	select {
+	case reading := <-reader.Readings():
+		if reading.Memory != nil {
+			t.Errorf("Expected no memory metrics from a CPU-only probe, got %+v", reading.Memory)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Timeout waiting for reading")
+	}
+}
+
+func TestParseLoadReading(t *testing.T) {
+	input := `{"cpu_percent":99.0,"timestamp":1707860342,"load":{"load1":40.5,"load5":12.0,"load15":3.25,"cpu_pressure":{"some_avg10":55.0},"io_pressure":{"some_avg10":4.5,"full_avg10":2.0}}}` + "\n"
+	reader := NewPipeReaderFromReader(strings.NewReader(input))
+	reader.Start()
+	defer reader.Stop()
+
+	select {
+	case reading := <-reader.Readings():
+		if reading.Load == nil {
+			t.Fatal("Expected load metrics")
+		}
+		if reading.Load.Load1 != 40.5 || reading.Load.Load15 != 3.25 {
+			t.Errorf("Unexpected load averages: %+v", reading.Load)
+		}
+		if reading.Load.CPUPressure == nil || reading.Load.CPUPressure.SomeAvg10 != 55.0 {
+			t.Errorf("Unexpected CPU pressure: %+v", reading.Load.CPUPressure)
+		}
+		if reading.Load.IOPressure == nil || reading.Load.IOPressure.FullAvg10 != 2.0 {
+			t.Errorf("Unexpected IO pressure: %+v", reading.Load.IOPressure)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Timeout waiting for reading")
+	}
+}
+
+func TestParseNetworkReading(t *testing.T) {
+	input := `{"cpu_percent":12.0,"timestamp":1707860342,"network":{"rx_bytes_per_sec":125000,"tx_bytes_per_sec":2048,"rx_packets_per_sec":90,"tx_packets_per_sec":30,"rx_errors":1,"tx_errors":0}}` + "\n"
+	reader := NewPipeReaderFromReader(strings.NewReader(input))
+	reader.Start()
+	defer reader.Stop()
+
+	select {
+	case reading := <-reader.Readings():
+		if reading.Network == nil {
+			t.Fatal("Expected network totals")
+		}
+		if reading.Network.RxBytesPerSec != 125000 || reading.Network.TxBytesPerSec != 2048 || reading.Network.RxErrors != 1 {
+			t.Errorf("Unexpected network totals: %+v", reading.Network)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Timeout waiting for reading")
+	}
+}
@@ -0,0 +1,63 @@
+package pipe
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"syscall"
+)
+
+// PipeWriter writes readings to a named pipe (FIFO) as JSON lines, the
+// same protocol the C probe speaks
+type PipeWriter struct {
+	path   string
+	writer io.WriteCloser
+}
+
+// NewPipeWriter creates the FIFO at path, replacing any stale one, and
+// opens it for writing. It blocks until a reader opens the other end.
+func NewPipeWriter(path string) (*PipeWriter, error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err := syscall.Mkfifo(path, 0o666); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return &PipeWriter{path: path, writer: file}, nil
+}
+
+// NewPipeWriterFromWriter creates a PipeWriter around an io.Writer (for testing)
+func NewPipeWriterFromWriter(w io.Writer) *PipeWriter {
+	return &PipeWriter{writer: nopWriteCloser{w}}
+}
+
+// Write sends one reading as a single JSON line
+func (pw *PipeWriter) Write(reading CpuReading) error {
+	data, err := json.Marshal(reading)
+	if err != nil {
+		return err
+	}
+	_, err = pw.writer.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the pipe and removes the FIFO
+func (pw *PipeWriter) Close() error {
+	err := pw.writer.Close()
+	if pw.path != "" {
+		os.Remove(pw.path)
+	}
+	return err
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
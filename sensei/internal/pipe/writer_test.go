@@ -0,0 +1,39 @@
+package pipe
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"system-shinobi/sensei/internal/metrics"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewPipeWriterFromWriter(&buf)
+
+	sent := CpuReading{
+		CpuPercent: 33.3,
+		Timestamp:  1707860342,
+		Memory:     &metrics.Memory{Total: 16000, Used: 8000},
+	}
+	if err := writer.Write(sent); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		t.Errorf("Expected a newline-terminated JSON line, got %q", buf.String())
+	}
+
+	reader := NewPipeReaderFromReader(&buf)
+	reader.Start()
+	defer reader.Stop()
+
+	select {
+	case reading := <-reader.Readings():
+		if reading.CpuPercent != 33.3 || reading.Memory == nil || reading.Memory.Used != 8000 {
+			t.Errorf("Reading did not round-trip: %+v", reading)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Timeout waiting for reading")
+	}
+}
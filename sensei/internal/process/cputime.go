@@ -0,0 +1,58 @@
+package process
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks is the unit of the CPU times in /proc/[pid]/stat, which
+// the kernel always reports at USER_HZ = 100
+const clockTicks = 100
+
+// CPURates computes each process's CPU usage between two samples of
+// cumulative CPU time taken elapsed apart, as a percentage of one core
+// like ps reports. Processes missing from prev are skipped, as are PIDs
+// whose time went backwards because the PID was reused.
+func CPURates(prev, cur map[int]time.Duration, elapsed time.Duration) map[int]float64 {
+	if elapsed <= 0 {
+		return nil
+	}
+
+	rates := make(map[int]float64, len(cur))
+	for pid, c := range cur {
+		p, ok := prev[pid]
+		if !ok || c < p {
+			continue
+		}
+		rates[pid] = 100 * float64(c-p) / float64(elapsed)
+	}
+	return rates
+}
+
+// parseProcPIDStat returns the user plus system CPU time from
+// /proc/[pid]/stat. The command name in parentheses may contain spaces
+// and parentheses itself, so fields are counted from the last ')':
+//
+//	1234 (my (odd) cmd) S 1 1234 1234 0 -1 4194304 79 0 0 0 250 50 ...
+func parseProcPIDStat(data string) (time.Duration, error) {
+	end := strings.LastIndexByte(data, ')')
+	if end < 0 {
+		return 0, fmt.Errorf("no command name")
+	}
+	// After the name: state(3) ... utime(14) stime(15)
+	fields := strings.Fields(data[end+1:])
+	if len(fields) < 13 {
+		return 0, fmt.Errorf("only %d fields after the command name", len(fields))
+	}
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("utime: %w", err)
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("stime: %w", err)
+	}
+	return time.Duration(utime+stime) * time.Second / clockTicks, nil
+}
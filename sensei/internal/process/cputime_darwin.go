@@ -0,0 +1,12 @@
+package process
+
+import (
+	"errors"
+	"time"
+)
+
+// ReadCPUTime is not available on macOS. There ps already reports a
+// decaying average of recent usage rather than a lifetime one.
+func ReadCPUTime(pids []int) (map[int]time.Duration, error) {
+	return nil, errors.New("per-process CPU time is not available on macOS")
+}
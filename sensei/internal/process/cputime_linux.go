@@ -0,0 +1,23 @@
+package process
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// ReadCPUTime reads the cumulative CPU time of the given PIDs from
+// /proc/[pid]/stat. Processes that have exited are left out.
+func ReadCPUTime(pids []int) (map[int]time.Duration, error) {
+	times := make(map[int]time.Duration, len(pids))
+	for _, pid := range pids {
+		data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+		if err != nil {
+			continue
+		}
+		if t, err := parseProcPIDStat(string(data)); err == nil {
+			times[pid] = t
+		}
+	}
+	return times, nil
+}
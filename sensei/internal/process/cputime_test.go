@@ -0,0 +1,43 @@
+package process
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseProcPIDStat(t *testing.T) {
+	data := "1234 (my (odd) cmd) R 1 1234 1234 0 -1 4194304 79 0 0 0 250 50 0 0 20 0 1 0 593578 2703360 283\n"
+	got, err := parseProcPIDStat(data)
+	if err != nil {
+		t.Fatalf("parseProcPIDStat failed: %v", err)
+	}
+	if got != 3*time.Second {
+		t.Errorf("Expected 3s of CPU time, got %s", got)
+	}
+
+	for _, bad := range []string{"1234 cmd R 1", "1234 (cmd) R 1 2 3", "1234 (cmd) R 1 1234 1234 0 -1 4194304 79 0 0 0 x 50"} {
+		if _, err := parseProcPIDStat(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
+
+func TestCPURates(t *testing.T) {
+	prev := map[int]time.Duration{
+		10: 100 * time.Second, // long-lived, now spinning
+		20: 50 * time.Second,
+	}
+	cur := map[int]time.Duration{
+		10: 102 * time.Second,
+		20: time.Second, // PID reused
+		30: time.Second, // new process
+	}
+
+	rates := CPURates(prev, cur, 2*time.Second)
+	if len(rates) != 1 || rates[10] != 100 {
+		t.Errorf("Expected 100%% for PID 10 only, got %+v", rates)
+	}
+	if CPURates(prev, cur, 0) != nil {
+		t.Error("Expected no rates without elapsed time")
+	}
+}
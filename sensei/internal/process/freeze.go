@@ -0,0 +1,162 @@
+package process
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Frozen describes a process suspended with SIGSTOP
+type Frozen struct {
+	PID      int
+	Name     string
+	FrozenAt time.Time
+	ThawAt   time.Time // zero means the process stays frozen until ThawAll
+}
+
+// Freezer tracks processes it has suspended so they can be resumed with
+// SIGCONT when their timeout expires or when the owner shuts down.
+// It is safe for concurrent use.
+type Freezer struct {
+	// OnSignal, if set, is called after every SIGSTOP or SIGCONT the
+	// Freezer sends, including failed ones
+	OnSignal func(fr Frozen, sig syscall.Signal, err error)
+
+	mu     sync.Mutex
+	frozen map[int]Frozen
+	signal func(pid int, sig syscall.Signal) error
+}
+
+// NewFreezer creates an empty Freezer that signals real processes
+func NewFreezer() *Freezer {
+	return &Freezer{
+		frozen: make(map[int]Frozen),
+		signal: syscall.Kill,
+	}
+}
+
+// Freeze sends SIGSTOP to p and records it. A zero timeout keeps the
+// process frozen until it is thawed explicitly or ThawAll is called.
+func (f *Freezer) Freeze(p Process, timeout time.Duration, now time.Time) error {
+	fr := Frozen{PID: p.PID, Name: p.Name, FrozenAt: now}
+	if timeout > 0 {
+		fr.ThawAt = now.Add(timeout)
+	}
+
+	err := f.signal(p.PID, syscall.SIGSTOP)
+	f.notify(fr, syscall.SIGSTOP, err)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.frozen[p.PID] = fr
+	f.mu.Unlock()
+	return nil
+}
+
+// Thaw sends SIGCONT to a frozen process and forgets it. A process that
+// has already exited is forgotten without error.
+func (f *Freezer) Thaw(pid int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.thawLocked(pid)
+}
+
+// ThawExpired resumes every process whose timeout has passed and returns them
+func (f *Freezer) ThawExpired(now time.Time) ([]Frozen, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var thawed []Frozen
+	var errs []error
+	for pid, fr := range f.frozen {
+		if fr.ThawAt.IsZero() || now.Before(fr.ThawAt) {
+			continue
+		}
+		if err := f.thawLocked(pid); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		thawed = append(thawed, fr)
+	}
+	sortFrozen(thawed)
+	return thawed, errors.Join(errs...)
+}
+
+// ThawAll resumes every frozen process regardless of its timeout
+// and returns the ones that were resumed
+func (f *Freezer) ThawAll() ([]Frozen, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var thawed []Frozen
+	var errs []error
+	for pid, fr := range f.frozen {
+		if err := f.thawLocked(pid); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		thawed = append(thawed, fr)
+	}
+	sortFrozen(thawed)
+	return thawed, errors.Join(errs...)
+}
+
+// Get returns the record for pid if it is currently frozen by this Freezer
+func (f *Freezer) Get(pid int) (Frozen, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fr, ok := f.frozen[pid]
+	return fr, ok
+}
+
+// IsFrozen reports whether pid is currently frozen by this Freezer
+func (f *Freezer) IsFrozen(pid int) bool {
+	_, ok := f.Get(pid)
+	return ok
+}
+
+// List returns the frozen processes ordered by when they were frozen
+func (f *Freezer) List() []Frozen {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list := make([]Frozen, 0, len(f.frozen))
+	for _, fr := range f.frozen {
+		list = append(list, fr)
+	}
+	sortFrozen(list)
+	return list
+}
+
+func (f *Freezer) thawLocked(pid int) error {
+	fr, ok := f.frozen[pid]
+	if !ok {
+		return nil
+	}
+	err := f.signal(pid, syscall.SIGCONT)
+	f.notify(fr, syscall.SIGCONT, err)
+	if err != nil && !errors.Is(err, syscall.ESRCH) {
+		return err
+	}
+	delete(f.frozen, pid)
+	return nil
+}
+
+func (f *Freezer) notify(fr Frozen, sig syscall.Signal, err error) {
+	if f.OnSignal != nil {
+		f.OnSignal(fr, sig, err)
+	}
+}
+
+func sortFrozen(list []Frozen) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].FrozenAt.Equal(list[j].FrozenAt) {
+			return list[i].PID < list[j].PID
+		}
+		return list[i].FrozenAt.Before(list[j].FrozenAt)
+	})
+}
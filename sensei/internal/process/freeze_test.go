@@ -0,0 +1,138 @@
+package process
+
+import (
+	"syscall"
+	"testing"
+	"time"
+)
+
+type sentSignal struct {
+	pid int
+	sig syscall.Signal
+}
+
+func newTestFreezer(sent *[]sentSignal) *Freezer {
+	f := NewFreezer()
+	f.signal = func(pid int, sig syscall.Signal) error {
+		*sent = append(*sent, sentSignal{pid, sig})
+		return nil
+	}
+	return f
+}
+
+func TestFreezeSendsSigstop(t *testing.T) {
+	var sent []sentSignal
+	f := newTestFreezer(&sent)
+	now := time.Unix(1707860342, 0)
+
+	if err := f.Freeze(Process{PID: 42, Name: "node"}, time.Minute, now); err != nil {
+		t.Fatalf("Freeze failed: %v", err)
+	}
+
+	if len(sent) != 1 || sent[0] != (sentSignal{42, syscall.SIGSTOP}) {
+		t.Fatalf("Expected SIGSTOP to PID 42, got %v", sent)
+	}
+	if !f.IsFrozen(42) {
+		t.Error("Expected PID 42 to be frozen")
+	}
+
+	list := f.List()
+	if len(list) != 1 || list[0].Name != "node" || !list[0].ThawAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("Unexpected frozen list: %+v", list)
+	}
+}
+
+func TestThawExpired(t *testing.T) {
+	var sent []sentSignal
+	f := newTestFreezer(&sent)
+	now := time.Unix(1707860342, 0)
+
+	f.Freeze(Process{PID: 1, Name: "short"}, 30*time.Second, now)
+	f.Freeze(Process{PID: 2, Name: "long"}, 5*time.Minute, now)
+	f.Freeze(Process{PID: 3, Name: "forever"}, 0, now)
+	sent = nil
+
+	thawed, err := f.ThawExpired(now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("ThawExpired failed: %v", err)
+	}
+	if len(thawed) != 1 || thawed[0].PID != 1 {
+		t.Fatalf("Expected only PID 1 to thaw, got %+v", thawed)
+	}
+	if len(sent) != 1 || sent[0] != (sentSignal{1, syscall.SIGCONT}) {
+		t.Errorf("Expected SIGCONT to PID 1, got %v", sent)
+	}
+	if f.IsFrozen(1) || !f.IsFrozen(2) || !f.IsFrozen(3) {
+		t.Error("Wrong set of processes still frozen")
+	}
+}
+
+func TestThawAllResumesEverything(t *testing.T) {
+	var sent []sentSignal
+	f := newTestFreezer(&sent)
+	now := time.Unix(1707860342, 0)
+
+	f.Freeze(Process{PID: 1}, time.Hour, now)
+	f.Freeze(Process{PID: 2}, 0, now)
+	sent = nil
+
+	thawed, err := f.ThawAll()
+	if err != nil {
+		t.Fatalf("ThawAll failed: %v", err)
+	}
+	if len(thawed) != 2 || len(sent) != 2 {
+		t.Errorf("Expected 2 SIGCONTs, got %v", sent)
+	}
+	if len(f.List()) != 0 {
+		t.Errorf("Expected nothing frozen, got %+v", f.List())
+	}
+}
+
+func TestThawExitedProcess(t *testing.T) {
+	f := NewFreezer()
+	f.signal = func(pid int, sig syscall.Signal) error {
+		if sig == syscall.SIGCONT {
+			return syscall.ESRCH
+		}
+		return nil
+	}
+	f.Freeze(Process{PID: 7}, 0, time.Now())
+
+	if err := f.Thaw(7); err != nil {
+		t.Errorf("Thawing an exited process should not fail, got %v", err)
+	}
+	if f.IsFrozen(7) {
+		t.Error("Exited process should be forgotten")
+	}
+}
+
+func TestFreezeFailureNotRecorded(t *testing.T) {
+	f := NewFreezer()
+	f.signal = func(pid int, sig syscall.Signal) error {
+		return syscall.EPERM
+	}
+
+	if err := f.Freeze(Process{PID: 1}, time.Minute, time.Now()); err == nil {
+		t.Fatal("Expected Freeze to fail")
+	}
+	if f.IsFrozen(1) {
+		t.Error("Failed freeze should not be recorded")
+	}
+}
+
+func TestOnSignalSeesEverySignal(t *testing.T) {
+	var sent []sentSignal
+	f := newTestFreezer(&sent)
+
+	var notified []sentSignal
+	f.OnSignal = func(fr Frozen, sig syscall.Signal, err error) {
+		notified = append(notified, sentSignal{fr.PID, sig})
+	}
+
+	f.Freeze(Process{PID: 5, Name: "cc1"}, 0, time.Now())
+	f.Thaw(5)
+
+	if len(notified) != 2 || notified[0].sig != syscall.SIGSTOP || notified[1].sig != syscall.SIGCONT {
+		t.Errorf("Expected SIGSTOP then SIGCONT notifications, got %v", notified)
+	}
+}
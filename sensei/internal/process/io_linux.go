@@ -0,0 +1,47 @@
+package process
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ReadIO reads storage I/O counters for the given PIDs from
+// /proc/[pid]/io. Processes that have exited or belong to another user
+// (their io file needs ptrace access) are left out.
+func ReadIO(pids []int) (map[int]IOCounters, error) {
+	counters := make(map[int]IOCounters, len(pids))
+	for _, pid := range pids {
+		data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/io")
+		if err != nil {
+			continue
+		}
+		if c, err := parseProcIO(string(data)); err == nil {
+			counters[pid] = c
+		}
+	}
+	return counters, nil
+}
+
+// SocketCounts counts the open sockets of the given PIDs by reading the
+// links in /proc/[pid]/fd. Processes whose descriptors can't be read
+// are left out.
+func SocketCounts(pids []int) (map[int]int, error) {
+	counts := make(map[int]int, len(pids))
+	for _, pid := range pids {
+		dir := "/proc/" + strconv.Itoa(pid) + "/fd"
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		n := 0
+		for _, e := range entries {
+			target, err := os.Readlink(dir + "/" + e.Name())
+			if err == nil && strings.HasPrefix(target, "socket:") {
+				n++
+			}
+		}
+		counts[pid] = n
+	}
+	return counts, nil
+}
@@ -0,0 +1,56 @@
+package process
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseProcIO(t *testing.T) {
+	data := `rchar: 323934931
+wchar: 323929600
+syscr: 632687
+syscw: 632675
+read_bytes: 4096
+write_bytes: 323932160
+cancelled_write_bytes: 0
+`
+	c, err := parseProcIO(data)
+	if err != nil {
+		t.Fatalf("parseProcIO failed: %v", err)
+	}
+	if c.ReadBytes != 4096 || c.WriteBytes != 323932160 {
+		t.Errorf("Unexpected counters: %+v", c)
+	}
+
+	if _, err := parseProcIO("rchar: 1\nwchar: 2\n"); err == nil {
+		t.Error("Expected an error when read_bytes and write_bytes are missing")
+	}
+}
+
+func TestIORates(t *testing.T) {
+	prev := map[int]IOCounters{
+		10: {ReadBytes: 1000, WriteBytes: 2000},
+		20: {ReadBytes: 5000, WriteBytes: 5000},
+	}
+	cur := map[int]IOCounters{
+		10: {ReadBytes: 3000, WriteBytes: 2000},
+		20: {ReadBytes: 10, WriteBytes: 10}, // PID reused
+		30: {ReadBytes: 100},                // new process
+	}
+
+	rates := IORates(prev, cur, 2*time.Second)
+	if len(rates) != 1 {
+		t.Fatalf("Expected a rate for PID 10 only, got %+v", rates)
+	}
+	if r := rates[10]; r.ReadBytesPerSec != 1000 || r.WriteBytesPerSec != 0 {
+		t.Errorf("Unexpected rate for PID 10: %+v", r)
+	}
+}
+
+func TestParseLsofSockets(t *testing.T) {
+	output := "p123\nf4\nf7\np456\nf12\np789\n"
+	counts := parseLsofSockets(output)
+	if counts[123] != 2 || counts[456] != 1 || counts[789] != 0 {
+		t.Errorf("Unexpected socket counts: %v", counts)
+	}
+}
@@ -10,11 +10,10 @@ import (
 
 // ListTop returns the top n processes sorted by CPU usage
 func ListTop(n int) ([]Process, error) {
-	out, err := exec.Command("ps", "-Aceo", "pid,pcpu,pmem,comm").Output()
+	procs, err := ListAll()
 	if err != nil {
-		return nil, fmt.Errorf("ps command failed: %w", err)
+		return nil, err
 	}
-	procs := parsePsOutput(string(out))
 	SortByCPU(procs)
 	if len(procs) > n {
 		procs = procs[:n]
@@ -22,6 +21,24 @@ func ListTop(n int) ([]Process, error) {
 	return procs, nil
 }
 
+// ListAll returns every running process in ps order
+func ListAll() ([]Process, error) {
+	out, err := exec.Command("ps", psListFlag, "pid,uid,user,pcpu,pmem,comm").Output()
+	if err != nil {
+		return nil, fmt.Errorf("ps command failed: %w", err)
+	}
+	return parsePsOutput(string(out)), nil
+}
+
+// Command returns the full command line of the process with the given PID
+func Command(pid int) (string, error) {
+	out, err := exec.Command("ps", "-o", "command=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return "", fmt.Errorf("ps command failed: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 // Kill sends SIGTERM to the process with the given PID
 func Kill(pid int) error {
 	return syscall.Kill(pid, syscall.SIGTERM)
@@ -30,7 +47,7 @@ func Kill(pid int) error {
 // GetCPUPercent returns total CPU usage by summing all process CPU percentages
 // and dividing by the number of logical cores (ps reports per-core percentages)
 func GetCPUPercent() (float64, error) {
-	out, err := exec.Command("ps", "-Aceo", "pcpu").Output()
+	out, err := exec.Command("ps", psListFlag, "pcpu").Output()
 	if err != nil {
 		return 0, fmt.Errorf("ps command failed: %w", err)
 	}
@@ -44,7 +61,7 @@ func GetCPUPercent() (float64, error) {
 	return total, nil
 }
 
-// parsePsOutput parses the output of ps -Aceo pid,pcpu,pmem,comm
+// parsePsOutput parses the output of ps -Aceo pid,uid,user,pcpu,pmem,comm
 func parsePsOutput(output string) []Process {
 	lines := strings.Split(strings.TrimSpace(output), "\n")
 	if len(lines) < 2 {
@@ -64,7 +81,7 @@ func parsePsOutput(output string) []Process {
 // parseLine parses a single line of ps output
 func parseLine(line string) (Process, bool) {
 	fields := strings.Fields(line)
-	if len(fields) < 4 {
+	if len(fields) < 6 {
 		return Process{}, false
 	}
 
@@ -72,16 +89,20 @@ func parseLine(line string) (Process, bool) {
 	if err != nil {
 		return Process{}, false
 	}
-	cpu, err := strconv.ParseFloat(fields[1], 64)
+	uid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return Process{}, false
+	}
+	cpu, err := strconv.ParseFloat(fields[3], 64)
 	if err != nil {
 		return Process{}, false
 	}
-	mem, err := strconv.ParseFloat(fields[2], 64)
+	mem, err := strconv.ParseFloat(fields[4], 64)
 	if err != nil {
 		return Process{}, false
 	}
 	// comm can contain spaces, so join remaining fields
-	name := strings.Join(fields[3:], " ")
+	name := strings.Join(fields[5:], " ")
 
-	return Process{PID: pid, Name: name, CPU: cpu, Memory: mem}, true
+	return Process{PID: pid, UID: uid, User: fields[2], Name: name, CPU: cpu, Memory: mem}, true
 }
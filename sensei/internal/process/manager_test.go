@@ -0,0 +1,23 @@
+package process
+
+import "testing"
+
+func TestParsePsOutput(t *testing.T) {
+	output := `  PID   UID USER             %CPU %MEM COMM
+    1     0 root              0.0  0.1 launchd
+  412   501 owen             12.5  1.3 Google Chrome Helper
+  abc   501 owen              1.0  0.1 broken
+`
+	procs := parsePsOutput(output)
+	if len(procs) != 2 {
+		t.Fatalf("Expected 2 processes, got %d: %+v", len(procs), procs)
+	}
+
+	want := Process{PID: 412, UID: 501, User: "owen", Name: "Google Chrome Helper", CPU: 12.5, Memory: 1.3}
+	if procs[1] != want {
+		t.Errorf("Expected %+v, got %+v", want, procs[1])
+	}
+	if procs[0].UID != 0 || procs[0].User != "root" {
+		t.Errorf("Expected launchd to be owned by root, got %+v", procs[0])
+	}
+}
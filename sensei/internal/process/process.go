@@ -4,10 +4,12 @@ import "sort"
 
 // Process represents a running system process
 type Process struct {
-	PID    int
-	Name   string
-	CPU    float64
-	Memory float64
+	PID    int     `json:"pid"`
+	UID    int     `json:"uid"`
+	User   string  `json:"user"`
+	Name   string  `json:"name"`
+	CPU    float64 `json:"cpu_percent"`
+	Memory float64 `json:"memory_percent"`
 }
 
 // SortByCPU sorts processes by CPU usage descending
@@ -16,3 +18,10 @@ func SortByCPU(procs []Process) {
 		return procs[i].CPU > procs[j].CPU
 	})
 }
+
+// SortByMemory sorts processes by memory usage descending
+func SortByMemory(procs []Process) {
+	sort.Slice(procs, func(i, j int) bool {
+		return procs[i].Memory > procs[j].Memory
+	})
+}
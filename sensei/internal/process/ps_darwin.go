@@ -0,0 +1,4 @@
+package process
+
+// psListFlag selects every process; -c makes comm the bare executable name
+const psListFlag = "-Aceo"
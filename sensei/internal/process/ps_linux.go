@@ -0,0 +1,5 @@
+package process
+
+// psListFlag selects every process; procps already reports comm as the
+// bare executable name and rejects -c alongside -o
+const psListFlag = "-Aeo"
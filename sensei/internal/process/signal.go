@@ -0,0 +1,43 @@
+package process
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+)
+
+// signals lists the signals that can be named in configuration
+var signals = map[string]syscall.Signal{
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGINT":  syscall.SIGINT,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGKILL": syscall.SIGKILL,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+	"SIGTERM": syscall.SIGTERM,
+	"SIGCONT": syscall.SIGCONT,
+	"SIGSTOP": syscall.SIGSTOP,
+}
+
+// ParseSignal converts a name like "SIGTERM", "term" or "KILL" to a signal
+func ParseSignal(name string) (syscall.Signal, error) {
+	key := strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(key, "SIG") {
+		key = "SIG" + key
+	}
+	sig, ok := signals[key]
+	if !ok {
+		return 0, fmt.Errorf("unknown signal %q", name)
+	}
+	return sig, nil
+}
+
+// SignalName returns the conventional name of sig, e.g. "SIGTERM"
+func SignalName(sig syscall.Signal) string {
+	for name, s := range signals {
+		if s == sig {
+			return name
+		}
+	}
+	return fmt.Sprintf("signal %d", int(sig))
+}
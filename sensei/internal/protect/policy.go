@@ -0,0 +1,102 @@
+package protect
+
+import (
+	"fmt"
+	"os"
+
+	"system-shinobi/sensei/internal/config"
+	"system-shinobi/sensei/internal/process"
+)
+
+// Verdict says whether a process may be signalled
+type Verdict int
+
+const (
+	Allow   Verdict = iota // not protected
+	Confirm                // protected: needs a typed confirmation
+	Refuse                 // protected: never signal
+)
+
+// builtinNames are system-critical processes on macOS and Linux that are
+// always protected, along with PID 1 and the calling process. Names are
+// as ps reports them, which Linux cuts to 15 characters.
+var builtinNames = []string{
+	"launchd", "kernel_task", "WindowServer", "loginwindow", "Finder", "Dock",
+	"init", "systemd", "systemd-journal", "dbus-daemon", "Xorg", "Xwayland",
+	"gnome-shell", "kwin_x11", "kwin_wayland", "sway",
+	"sshd", "sensei", "dojo",
+}
+
+// Decision is the result of checking a process against a Policy
+type Decision struct {
+	Verdict Verdict
+	Reason  string // why the process is protected, empty for Allow
+}
+
+// Policy decides which processes are protected
+type Policy struct {
+	mode  Verdict
+	self  int
+	pids  map[int]bool
+	names map[string]bool
+	users map[string]bool
+	root  bool
+}
+
+// New builds a Policy from the built-in list plus the configured entries
+func New(cfg config.Protect) (*Policy, error) {
+	p := &Policy{
+		self:  os.Getpid(),
+		pids:  make(map[int]bool),
+		names: make(map[string]bool),
+		users: make(map[string]bool),
+		root:  cfg.Root,
+	}
+
+	switch cfg.Mode {
+	case "", "confirm":
+		p.mode = Confirm
+	case "refuse":
+		p.mode = Refuse
+	default:
+		return nil, fmt.Errorf("protect: unknown mode %q (want \"confirm\" or \"refuse\")", cfg.Mode)
+	}
+
+	for _, name := range builtinNames {
+		p.names[name] = true
+	}
+	for _, name := range cfg.Names {
+		p.names[name] = true
+	}
+	for _, pid := range cfg.PIDs {
+		p.pids[pid] = true
+	}
+	for _, user := range cfg.Users {
+		p.users[user] = true
+	}
+	return p, nil
+}
+
+// Check decides whether proc may be signalled. PID 1 and the calling
+// process are always refused; other protected processes get the
+// configured mode. A process whose name couldn't be resolved, empty or
+// "?", needs confirmation since it could be any of them.
+func (p *Policy) Check(proc process.Process) Decision {
+	switch {
+	case proc.PID <= 1:
+		return Decision{Refuse, fmt.Sprintf("PID %d is the system's init process", proc.PID)}
+	case proc.PID == p.self:
+		return Decision{Refuse, "it is this process"}
+	case p.pids[proc.PID]:
+		return Decision{p.mode, fmt.Sprintf("PID %d is on the protect list", proc.PID)}
+	case p.names[proc.Name]:
+		return Decision{p.mode, fmt.Sprintf("%s is on the protect list", proc.Name)}
+	case p.users[proc.User]:
+		return Decision{p.mode, fmt.Sprintf("it is owned by protected user %s", proc.User)}
+	case p.root && proc.UID == 0:
+		return Decision{p.mode, "it is owned by root"}
+	case proc.Name == "" || proc.Name == "?":
+		return Decision{Confirm, fmt.Sprintf("PID %d's name could not be resolved", proc.PID)}
+	}
+	return Decision{Verdict: Allow}
+}
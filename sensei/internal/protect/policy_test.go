@@ -0,0 +1,83 @@
+package protect
+
+import (
+	"testing"
+
+	"system-shinobi/sensei/internal/config"
+	"system-shinobi/sensei/internal/process"
+)
+
+func newTestPolicy(t *testing.T, cfg config.Protect) *Policy {
+	t.Helper()
+	p, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	p.self = 4242
+	return p
+}
+
+func TestBuiltinProtection(t *testing.T) {
+	p := newTestPolicy(t, config.Protect{})
+
+	tests := []struct {
+		proc     process.Process
+		expected Verdict
+	}{
+		{process.Process{PID: 1, Name: "launchd"}, Refuse},
+		{process.Process{PID: 0, Name: "kernel_task"}, Refuse},
+		{process.Process{PID: 4242, Name: "dojo"}, Refuse},
+		{process.Process{PID: 300, Name: "WindowServer"}, Confirm},
+		{process.Process{PID: 301, Name: "sshd"}, Confirm},
+		{process.Process{PID: 302, Name: "sensei"}, Confirm},
+		{process.Process{PID: 305, Name: "systemd-journal"}, Confirm}, // comm is cut to 15 characters
+		{process.Process{PID: 306, Name: "?"}, Confirm},
+		{process.Process{PID: 307}, Confirm},
+		{process.Process{PID: 303, Name: "node", UID: 0, User: "root"}, Allow},
+		{process.Process{PID: 304, Name: "node", UID: 501, User: "owen"}, Allow},
+	}
+
+	for _, tt := range tests {
+		d := p.Check(tt.proc)
+		if d.Verdict != tt.expected {
+			t.Errorf("Check(%d %s) = %v (%s), expected %v", tt.proc.PID, tt.proc.Name, d.Verdict, d.Reason, tt.expected)
+		}
+		if d.Verdict != Allow && d.Reason == "" {
+			t.Errorf("Check(%d %s) gave no reason", tt.proc.PID, tt.proc.Name)
+		}
+	}
+}
+
+func TestConfiguredProtection(t *testing.T) {
+	p := newTestPolicy(t, config.Protect{
+		Mode:  "refuse",
+		PIDs:  []int{777},
+		Names: []string{"postgres"},
+		Users: []string{"_mysql"},
+		Root:  true,
+	})
+
+	tests := []struct {
+		proc     process.Process
+		expected Verdict
+	}{
+		{process.Process{PID: 777, Name: "anything", UID: 501}, Refuse},
+		{process.Process{PID: 500, Name: "postgres", UID: 501}, Refuse},
+		{process.Process{PID: 501, Name: "mysqld", UID: 74, User: "_mysql"}, Refuse},
+		{process.Process{PID: 502, Name: "cron", UID: 0, User: "root"}, Refuse},
+		{process.Process{PID: 503, Name: "sshd", UID: 501}, Refuse},
+		{process.Process{PID: 504, Name: "node", UID: 501, User: "owen"}, Allow},
+	}
+
+	for _, tt := range tests {
+		if d := p.Check(tt.proc); d.Verdict != tt.expected {
+			t.Errorf("Check(%d %s) = %v, expected %v", tt.proc.PID, tt.proc.Name, d.Verdict, tt.expected)
+		}
+	}
+}
+
+func TestRejectsUnknownMode(t *testing.T) {
+	if _, err := New(config.Protect{Mode: "maybe"}); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
@@ -0,0 +1,197 @@
+// Package record saves the probe's readings and process snapshots to a
+// file and plays them back, for demos, bug reports and deterministic
+// tests
+package record
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"system-shinobi/sensei/internal/pipe"
+	"system-shinobi/sensei/internal/process"
+)
+
+// Frame is one recorded moment: a probe reading and the top processes
+// when it arrived
+type Frame struct {
+	At        time.Time         `json:"at"`
+	Reading   *pipe.CpuReading  `json:"reading,omitempty"`   // nil while the probe was disconnected
+	Processes []process.Process `json:"processes,omitempty"` // by CPU, highest first
+}
+
+// Writer appends frames to a recording as JSON lines
+type Writer struct {
+	enc *json.Encoder
+}
+
+// NewWriter returns a Writer that writes to w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{enc: json.NewEncoder(w)}
+}
+
+// Write appends one frame
+func (w *Writer) Write(f Frame) error {
+	return w.enc.Encode(f)
+}
+
+// Read parses a recording. Frames must be in the order they were taken.
+func Read(r io.Reader) ([]Frame, error) {
+	var frames []Frame
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var f Frame
+		if err := json.Unmarshal(scanner.Bytes(), &f); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if n := len(frames); n > 0 && f.At.Before(frames[n-1].At) {
+			return nil, fmt.Errorf("line %d: frame at %s is older than the one before it", line, f.At.Format(time.RFC3339))
+		}
+		frames = append(frames, f)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return frames, nil
+}
+
+// ReadFile parses the recording at path
+func ReadFile(path string) ([]Frame, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	frames, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("%s: no frames recorded", path)
+	}
+	return frames, nil
+}
+
+// Player replays frames against the wall clock, speed times faster than
+// they were recorded
+type Player struct {
+	frames []Frame
+	speed  float64
+	start  time.Time
+}
+
+// NewPlayer starts playing frames at start
+func NewPlayer(frames []Frame, speed float64, start time.Time) (*Player, error) {
+	if len(frames) == 0 {
+		return nil, errors.New("no frames to replay")
+	}
+	if speed <= 0 {
+		return nil, fmt.Errorf("speed must be positive, got %v", speed)
+	}
+	return &Player{frames: frames, speed: speed, start: start}, nil
+}
+
+// Speed returns the playback speed
+func (p *Player) Speed() float64 {
+	return p.speed
+}
+
+// Length returns the recorded time from the first frame to the last
+func (p *Player) Length() time.Duration {
+	return p.frames[len(p.frames)-1].At.Sub(p.frames[0].At)
+}
+
+// Position returns how far into the recording playback is at now. It
+// stops at the end.
+func (p *Player) Position(now time.Time) time.Duration {
+	pos := time.Duration(float64(now.Sub(p.start)) * p.speed)
+	return min(max(pos, 0), p.Length())
+}
+
+// At returns the frame showing at now: the last one taken at or before
+// the playback position
+func (p *Player) At(now time.Time) Frame {
+	at := p.frames[0].At.Add(p.Position(now))
+	i := 0
+	for i+1 < len(p.frames) && !p.frames[i+1].At.After(at) {
+		i++
+	}
+	return p.frames[i]
+}
+
+// Segments splits a recording at each probe disconnect, a frame without a
+// reading, so that every segment can be streamed as one connection. The
+// disconnect frame ends one segment and starts the next, keeping the time
+// the probe was gone on both sides. An empty recording has no segments.
+func Segments(frames []Frame) [][]Frame {
+	var segments [][]Frame
+	start, read := 0, false
+	for i, f := range frames {
+		if f.Reading != nil {
+			read = true
+			continue
+		}
+		if read {
+			segments = append(segments, frames[start:i+1])
+			start, read = i, false
+		}
+	}
+	if read || (len(segments) == 0 && len(frames) > 0) {
+		segments = append(segments, frames[start:])
+	}
+	return segments
+}
+
+// Stream writes the frames' readings in the probe's JSON line protocol,
+// for a pipe.PipeReader to read as if the probe were live. Readings are
+// spaced as recorded, divided by speed; a speed of zero or less sends
+// them all at once. Frames without a reading only keep time, so a
+// segment from Segments ends at its disconnect. Closing the stream stops it.
+func Stream(frames []Frame, speed float64) io.ReadCloser {
+	r, w := io.Pipe()
+	s := &stream{PipeReader: r, done: make(chan struct{})}
+	go s.play(frames, speed, pipe.NewPipeWriterFromWriter(w), w)
+	return s
+}
+
+// stream is the reading end of Stream
+type stream struct {
+	*io.PipeReader
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func (s *stream) Close() error {
+	s.doneOnce.Do(func() { close(s.done) })
+	return s.PipeReader.Close()
+}
+
+func (s *stream) play(frames []Frame, speed float64, out *pipe.PipeWriter, w *io.PipeWriter) {
+	defer w.Close()
+	for i, f := range frames {
+		if i > 0 && speed > 0 {
+			gap := time.Duration(float64(f.At.Sub(frames[i-1].At)) / speed)
+			select {
+			case <-time.After(gap):
+			case <-s.done:
+				return
+			}
+		}
+		if f.Reading == nil {
+			continue
+		}
+		if err := out.Write(*f.Reading); err != nil {
+			return
+		}
+	}
+}
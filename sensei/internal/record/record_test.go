@@ -0,0 +1,159 @@
+package record
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"system-shinobi/sensei/internal/metrics"
+	"system-shinobi/sensei/internal/pipe"
+	"system-shinobi/sensei/internal/process"
+)
+
+var start = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// frames returns one frame per reading, a second apart
+func frames(percents ...float64) []Frame {
+	out := make([]Frame, len(percents))
+	for i, p := range percents {
+		out[i] = Frame{
+			At:      start.Add(time.Duration(i) * time.Second),
+			Reading: &pipe.CpuReading{CpuPercent: p, Timestamp: start.Unix() + int64(i)},
+		}
+	}
+	return out
+}
+
+func TestWriteRead(t *testing.T) {
+	want := frames(10, 95)
+	want[1].Reading.Memory = &metrics.Memory{Total: 1024, Used: 900}
+	want[1].Processes = []process.Process{{PID: 42, User: "owen", Name: "cc1plus", CPU: 93.5, Memory: 4.2}}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, f := range want {
+		if err := w.Write(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 frames, got %d", len(got))
+	}
+	if !got[1].At.Equal(want[1].At) || got[1].Reading.CpuPercent != 95 || got[1].Reading.Memory.Used != 900 {
+		t.Errorf("Unexpected frame %+v", got[1])
+	}
+	if len(got[1].Processes) != 1 || got[1].Processes[0] != want[1].Processes[0] {
+		t.Errorf("Expected processes %+v, got %+v", want[1].Processes, got[1].Processes)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name, data, want string
+	}{
+		{"malformed", `{"at":"2026-03-01T12:00:00Z"}` + "\nnot json\n", "line 2"},
+		{"out of order", `{"at":"2026-03-01T12:00:05Z"}` + "\n" + `{"at":"2026-03-01T12:00:00Z"}` + "\n", "older"},
+	}
+	for _, tt := range tests {
+		_, err := Read(strings.NewReader(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error mentioning %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestPlayerAt(t *testing.T) {
+	p, err := NewPlayer(frames(10, 20, 30), 2, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		after time.Duration
+		want  float64
+	}{
+		{0, 10},
+		{400 * time.Millisecond, 10},
+		{500 * time.Millisecond, 20},
+		{time.Second, 30},
+		{time.Hour, 30}, // stays on the last frame
+	}
+	for _, tt := range tests {
+		if got := p.At(start.Add(tt.after)).Reading.CpuPercent; got != tt.want {
+			t.Errorf("After %s: expected %v, got %v", tt.after, tt.want, got)
+		}
+	}
+	if p.Position(start.Add(time.Hour)) != 2*time.Second {
+		t.Errorf("Expected the position to stop at the end, got %s", p.Position(start.Add(time.Hour)))
+	}
+
+	if _, err := NewPlayer(frames(10), 0, start); err == nil {
+		t.Error("Expected an error for a zero speed")
+	}
+	if _, err := NewPlayer(nil, 1, start); err == nil {
+		t.Error("Expected an error for no frames")
+	}
+}
+
+func TestStreamThroughPipeReader(t *testing.T) {
+	recorded := frames(10, 50, 95)
+	recorded = append(recorded[:1], append([]Frame{{At: start.Add(500 * time.Millisecond)}}, recorded[1:]...)...)
+
+	reader := pipe.NewPipeReaderFromReader(Stream(recorded, 0))
+	reader.Start()
+	var got []float64
+	for r := range reader.Readings() {
+		got = append(got, r.CpuPercent)
+	}
+	if len(got) != 3 || got[0] != 10 || got[1] != 50 || got[2] != 95 {
+		t.Errorf("Expected readings 10, 50, 95 in order, got %v", got)
+	}
+}
+
+func TestStreamClose(t *testing.T) {
+	s := Stream(frames(10, 20), 0.001) // the second reading is 1000s away
+	buf := make([]byte, 256)
+	if _, err := s.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		s.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close blocked")
+	}
+	if _, err := s.Read(buf); err == nil {
+		t.Error("Expected reads to fail after Close")
+	}
+}
+
+func TestSegments(t *testing.T) {
+	recorded := frames(10, 0, 0, 50, 95, 0)
+	for _, i := range []int{1, 2, 5} {
+		recorded[i].Reading = nil // the probe was disconnected
+	}
+
+	segments := Segments(recorded)
+	if len(segments) != 2 {
+		t.Fatalf("Expected 2 segments, got %d", len(segments))
+	}
+	if len(segments[0]) != 2 || segments[0][1].Reading != nil {
+		t.Errorf("The first segment should end at the disconnect, got %v", segments[0])
+	}
+	if len(segments[1]) != 5 || !segments[1][0].At.Equal(recorded[1].At) || segments[1][4].Reading != nil {
+		t.Errorf("The second segment should run from the disconnect to the end, got %v", segments[1])
+	}
+	if Segments(nil) != nil {
+		t.Error("An empty recording should have no segments")
+	}
+}
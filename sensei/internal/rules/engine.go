@@ -0,0 +1,309 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"sync"
+	"syscall"
+	"time"
+
+	"system-shinobi/sensei/internal/audit"
+	"system-shinobi/sensei/internal/config"
+	"system-shinobi/sensei/internal/logging"
+	"system-shinobi/sensei/internal/process"
+	"system-shinobi/sensei/internal/protect"
+	"system-shinobi/sensei/internal/selfstat"
+)
+
+const defaultGrace = 10 * time.Second
+
+// Rule is a compiled config.RuleSpec
+type Rule struct {
+	Name     string
+	Match    *regexp.Regexp
+	CPUAbove float64
+	For      time.Duration
+	Signals  []syscall.Signal
+	Grace    time.Duration
+}
+
+// Compile validates a rule spec and fills in defaults: SIGTERM then
+// SIGKILL, with a 10s grace period between them
+func Compile(spec config.RuleSpec) (Rule, error) {
+	if spec.Name == "" {
+		return Rule{}, errors.New("rule has no name")
+	}
+	re, err := regexp.Compile(spec.Match)
+	if err != nil {
+		return Rule{}, fmt.Errorf("rule %s: bad match pattern: %w", spec.Name, err)
+	}
+	if spec.For.Duration < 0 || spec.Grace.Duration < 0 {
+		return Rule{}, fmt.Errorf("rule %s: durations must not be negative", spec.Name)
+	}
+
+	r := Rule{
+		Name:     spec.Name,
+		Match:    re,
+		CPUAbove: spec.CPUAbove,
+		For:      spec.For.Duration,
+		Grace:    spec.Grace.Duration,
+		Signals:  []syscall.Signal{syscall.SIGTERM, syscall.SIGKILL},
+	}
+	if r.Grace == 0 {
+		r.Grace = defaultGrace
+	}
+	if len(spec.Signals) > 0 {
+		r.Signals = r.Signals[:0]
+		for _, name := range spec.Signals {
+			sig, err := process.ParseSignal(name)
+			if err != nil {
+				return Rule{}, fmt.Errorf("rule %s: %w", spec.Name, err)
+			}
+			r.Signals = append(r.Signals, sig)
+		}
+	}
+	return r, nil
+}
+
+// Recorder receives an audit entry for every action the engine takes
+type Recorder interface {
+	Record(audit.Entry) error
+}
+
+// key identifies one process as seen by one rule. The name guards
+// against acting on a recycled PID.
+type key struct {
+	rule string
+	pid  int
+	name string
+}
+
+// action tracks a signal escalation in progress
+type action struct {
+	step     int // index of the next signal to send
+	nextAt   time.Time
+	finished bool
+}
+
+// Engine periodically samples processes and applies rules to them
+type Engine struct {
+	rules     []Rule
+	dryRun    bool
+	interval  time.Duration
+	allowlist map[string]bool
+	policy    *protect.Policy
+	recorder  Recorder
+
+	list    func() ([]process.Process, error)
+	cpuTime func(pids []int) (map[int]time.Duration, error)
+	signal  func(pid int, sig syscall.Signal) error
+	command func(pid int) (string, error)
+	self    int
+
+	prevCPU map[int]time.Duration // CPU time per PID at the previous tick
+	prevAt  time.Time
+
+	hot      map[key]time.Time // when a process first crossed the threshold
+	actions  map[key]*action
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewEngine compiles the configured rules. Processes protected by policy
+// (if non-nil) are never touched, whatever their verdict. Every action,
+// including dry-run ones, is written to rec.
+func NewEngine(cfg config.Rules, policy *protect.Policy, rec Recorder) (*Engine, error) {
+	e := &Engine{
+		dryRun:    cfg.DryRun,
+		interval:  cfg.Interval.Duration,
+		allowlist: make(map[string]bool),
+		policy:    policy,
+		recorder:  rec,
+		list:      process.ListAll,
+		cpuTime:   process.ReadCPUTime,
+		signal:    syscall.Kill,
+		command:   process.Command,
+		self:      os.Getpid(),
+		hot:       make(map[key]time.Time),
+		actions:   make(map[key]*action),
+		done:      make(chan struct{}),
+	}
+	if e.interval <= 0 {
+		e.interval = config.Default().Rules.Interval.Duration
+	}
+	for _, name := range cfg.Allowlist {
+		e.allowlist[name] = true
+	}
+	for _, spec := range cfg.Rules {
+		r, err := Compile(spec)
+		if err != nil {
+			return nil, err
+		}
+		e.rules = append(e.rules, r)
+	}
+	return e, nil
+}
+
+// Start begins sampling processes in a goroutine
+func (e *Engine) Start() {
+	go e.loop()
+}
+
+// Stop ends the sampling goroutine. It is safe to call more than once.
+func (e *Engine) Stop() {
+	e.stopOnce.Do(func() { close(e.done) })
+}
+
+func (e *Engine) loop() {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.done:
+			return
+		case now := <-ticker.C:
+			done := selfstat.Time("rules")
+			procs, err := e.list()
+			if err == nil {
+				procs = e.currentCPU(now, procs)
+			}
+			done()
+			if err != nil {
+				slog.Warn("Rules: failed to list processes", "err", err)
+				continue
+			}
+			e.Evaluate(now, procs)
+		}
+	}
+}
+
+// currentCPU replaces each process's CPU figure from ps, which on Linux
+// is an average over the process's lifetime, with its usage since the
+// previous tick. A process seen for the first time counts as idle until
+// the next one. Where CPU times can't be read, ps already reports recent
+// usage and procs is returned as is.
+func (e *Engine) currentCPU(now time.Time, procs []process.Process) []process.Process {
+	pids := make([]int, len(procs))
+	for i, p := range procs {
+		pids[i] = p.PID
+	}
+	times, err := e.cpuTime(pids)
+	if err != nil {
+		return procs
+	}
+	rates := process.CPURates(e.prevCPU, times, now.Sub(e.prevAt))
+	e.prevCPU, e.prevAt = times, now
+	for i := range procs {
+		procs[i].CPU = rates[procs[i].PID]
+	}
+	return procs
+}
+
+// Evaluate applies every rule to a process snapshot taken at now and
+// returns the actions taken
+func (e *Engine) Evaluate(now time.Time, procs []process.Process) []audit.Entry {
+	var taken []audit.Entry
+	seen := make(map[key]bool)
+
+	for _, r := range e.rules {
+		for _, p := range procs {
+			if !r.Match.MatchString(p.Name) || e.protected(p) {
+				continue
+			}
+			k := key{rule: r.Name, pid: p.PID, name: p.Name}
+			seen[k] = true
+			hot := p.CPU > r.CPUAbove
+
+			if act, ok := e.actions[k]; ok {
+				if act.finished {
+					// Allow the rule to fire again once the process calms down
+					if !hot {
+						delete(e.actions, k)
+					}
+				} else if !now.Before(act.nextAt) {
+					taken = append(taken, e.fire(r, p, act, now))
+				}
+				continue
+			}
+
+			if !hot {
+				delete(e.hot, k)
+				continue
+			}
+			since, ok := e.hot[k]
+			if !ok {
+				since = now
+				e.hot[k] = now
+			}
+			if now.Sub(since) >= r.For {
+				delete(e.hot, k)
+				act := &action{}
+				e.actions[k] = act
+				taken = append(taken, e.fire(r, p, act, now))
+			}
+		}
+	}
+
+	// Forget processes that have exited
+	for k := range e.hot {
+		if !seen[k] {
+			delete(e.hot, k)
+		}
+	}
+	for k := range e.actions {
+		if !seen[k] {
+			delete(e.actions, k)
+		}
+	}
+
+	return taken
+}
+
+// fire sends the next signal of an escalation and records it
+func (e *Engine) fire(r Rule, p process.Process, act *action, now time.Time) audit.Entry {
+	sig := r.Signals[act.step]
+	cmdline, _ := e.command(p.PID)
+	entry := audit.Entry{
+		Time:      now,
+		PID:       p.PID,
+		Name:      p.Name,
+		Command:   cmdline,
+		Signal:    process.SignalName(sig),
+		Outcome:   audit.OutcomeDryRun,
+		Initiator: audit.RuleInitiator(r.Name),
+	}
+
+	act.step++
+	act.nextAt = now.Add(r.Grace)
+	act.finished = act.step >= len(r.Signals)
+
+	if !e.dryRun {
+		err := e.signal(p.PID, sig)
+		entry.Outcome = audit.Outcome(err)
+		if errors.Is(err, syscall.ESRCH) {
+			act.finished = true
+		}
+	}
+
+	slog.Info("Rules: signal sent", "rule", entry.Initiator, "signal", entry.Signal, "pid", p.PID, "name", p.Name, "outcome", entry.Outcome)
+	if e.recorder != nil {
+		if err := e.recorder.Record(entry); err != nil {
+			slog.ErrorContext(logging.Event(context.Background()), "Rules: failed to write audit entry", "err", err)
+		}
+	}
+	return entry
+}
+
+// protected reports whether rules must never touch p. Rules run
+// unattended, so anything that would need a confirmation is skipped.
+func (e *Engine) protected(p process.Process) bool {
+	if p.PID <= 1 || p.PID == e.self || e.allowlist[p.Name] {
+		return true
+	}
+	return e.policy != nil && e.policy.Check(p).Verdict != protect.Allow
+}
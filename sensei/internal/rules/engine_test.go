@@ -0,0 +1,238 @@
+package rules
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"system-shinobi/sensei/internal/audit"
+	"system-shinobi/sensei/internal/config"
+	"system-shinobi/sensei/internal/process"
+	"system-shinobi/sensei/internal/protect"
+)
+
+type memRecorder struct {
+	entries []audit.Entry
+}
+
+func (m *memRecorder) Record(e audit.Entry) error {
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+type sentSignal struct {
+	pid int
+	sig syscall.Signal
+}
+
+func newTestEngine(t *testing.T, cfg config.Rules, sent *[]sentSignal) (*Engine, *memRecorder) {
+	t.Helper()
+	rec := &memRecorder{}
+	e, err := NewEngine(cfg, nil, rec)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	e.self = 99999
+	e.signal = func(pid int, sig syscall.Signal) error {
+		*sent = append(*sent, sentSignal{pid, sig})
+		return nil
+	}
+	e.command = func(pid int) (string, error) {
+		return "/usr/bin/worker --pid", nil
+	}
+	return e, rec
+}
+
+func workerRule() config.RuleSpec {
+	return config.RuleSpec{
+		Name:     "leaked-worker",
+		Match:    "^jest-worker$",
+		CPUAbove: 90,
+		For:      config.Duration{Duration: 5 * time.Minute},
+		Grace:    config.Duration{Duration: 10 * time.Second},
+	}
+}
+
+func TestRuleFiresAfterSustainedCPU(t *testing.T) {
+	var sent []sentSignal
+	e, rec := newTestEngine(t, config.Rules{Rules: []config.RuleSpec{workerRule()}}, &sent)
+
+	start := time.Unix(1707860000, 0)
+	hot := []process.Process{{PID: 100, Name: "jest-worker", CPU: 95}}
+
+	// Hot for less than five minutes: nothing happens
+	for i := 0; i < 5; i++ {
+		e.Evaluate(start.Add(time.Duration(i)*time.Minute-time.Second), hot)
+	}
+	if len(sent) != 0 {
+		t.Fatalf("Rule fired too early: %v", sent)
+	}
+
+	e.Evaluate(start.Add(5*time.Minute), hot)
+	if len(sent) != 1 || sent[0] != (sentSignal{100, syscall.SIGTERM}) {
+		t.Fatalf("Expected SIGTERM to PID 100, got %v", sent)
+	}
+
+	// Still alive after the grace period: escalate to SIGKILL
+	e.Evaluate(start.Add(5*time.Minute+5*time.Second), hot)
+	if len(sent) != 1 {
+		t.Fatalf("Escalated before grace period: %v", sent)
+	}
+	e.Evaluate(start.Add(5*time.Minute+10*time.Second), hot)
+	if len(sent) != 2 || sent[1] != (sentSignal{100, syscall.SIGKILL}) {
+		t.Fatalf("Expected SIGKILL to PID 100, got %v", sent)
+	}
+
+	if len(rec.entries) != 2 {
+		t.Fatalf("Expected 2 audit entries, got %d", len(rec.entries))
+	}
+	if rec.entries[0].Initiator != "rule:leaked-worker" || rec.entries[0].Outcome != audit.OutcomeOK ||
+		rec.entries[0].Command != "/usr/bin/worker --pid" {
+		t.Errorf("Unexpected audit entry: %+v", rec.entries[0])
+	}
+}
+
+func TestRuleResetsWhenCPUDrops(t *testing.T) {
+	var sent []sentSignal
+	e, _ := newTestEngine(t, config.Rules{Rules: []config.RuleSpec{workerRule()}}, &sent)
+
+	start := time.Unix(1707860000, 0)
+	hot := []process.Process{{PID: 100, Name: "jest-worker", CPU: 95}}
+	calm := []process.Process{{PID: 100, Name: "jest-worker", CPU: 20}}
+
+	e.Evaluate(start, hot)
+	e.Evaluate(start.Add(4*time.Minute), calm)
+	e.Evaluate(start.Add(6*time.Minute), hot)
+	if len(sent) != 0 {
+		t.Errorf("Rule should restart its timer after CPU drops, sent %v", sent)
+	}
+}
+
+func TestDryRunSendsNothing(t *testing.T) {
+	var sent []sentSignal
+	cfg := config.Rules{DryRun: true, Rules: []config.RuleSpec{workerRule()}}
+	e, rec := newTestEngine(t, cfg, &sent)
+
+	start := time.Unix(1707860000, 0)
+	hot := []process.Process{{PID: 100, Name: "jest-worker", CPU: 95}}
+	e.Evaluate(start, hot)
+	e.Evaluate(start.Add(5*time.Minute), hot)
+
+	if len(sent) != 0 {
+		t.Errorf("Dry run sent signals: %v", sent)
+	}
+	if len(rec.entries) != 1 || rec.entries[0].Outcome != audit.OutcomeDryRun {
+		t.Errorf("Expected one dry-run audit entry, got %+v", rec.entries)
+	}
+}
+
+func TestAllowlistAndSelfAreProtected(t *testing.T) {
+	var sent []sentSignal
+	spec := workerRule()
+	spec.Match = ".*"
+	spec.For = config.Duration{}
+	cfg := config.Rules{Allowlist: []string{"sshd"}, Rules: []config.RuleSpec{spec}}
+	e, _ := newTestEngine(t, cfg, &sent)
+
+	procs := []process.Process{
+		{PID: 1, Name: "launchd", CPU: 99},
+		{PID: 200, Name: "sshd", CPU: 99},
+		{PID: 99999, Name: "sensei", CPU: 99},
+		{PID: 300, Name: "runaway", CPU: 99},
+	}
+	e.Evaluate(time.Unix(1707860000, 0), procs)
+
+	if len(sent) != 1 || sent[0].pid != 300 {
+		t.Errorf("Expected only PID 300 to be signalled, got %v", sent)
+	}
+}
+
+func TestProtectPolicyIsHonored(t *testing.T) {
+	var sent []sentSignal
+	spec := workerRule()
+	spec.Match = ".*"
+	spec.For = config.Duration{}
+	e, _ := newTestEngine(t, config.Rules{Rules: []config.RuleSpec{spec}}, &sent)
+
+	policy, err := protect.New(config.Protect{Names: []string{"postgres"}, Root: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.policy = policy
+
+	procs := []process.Process{
+		{PID: 200, Name: "WindowServer", UID: 88, CPU: 99},
+		{PID: 201, Name: "postgres", UID: 501, CPU: 99},
+		{PID: 202, Name: "cron", UID: 0, CPU: 99},
+		{PID: 300, Name: "runaway", UID: 501, CPU: 99},
+	}
+	e.Evaluate(time.Unix(1707860000, 0), procs)
+
+	if len(sent) != 1 || sent[0].pid != 300 {
+		t.Errorf("Expected only PID 300 to be signalled, got %v", sent)
+	}
+}
+
+func TestRecycledPIDStartsOver(t *testing.T) {
+	var sent []sentSignal
+	e, _ := newTestEngine(t, config.Rules{Rules: []config.RuleSpec{workerRule()}}, &sent)
+
+	start := time.Unix(1707860000, 0)
+	e.Evaluate(start, []process.Process{{PID: 100, Name: "jest-worker", CPU: 95}})
+	e.Evaluate(start.Add(3*time.Minute), []process.Process{{PID: 100, Name: "cc1", CPU: 95}})
+	e.Evaluate(start.Add(5*time.Minute), []process.Process{{PID: 100, Name: "jest-worker", CPU: 95}})
+
+	if len(sent) != 0 {
+		t.Errorf("A recycled PID should not inherit the old timer, sent %v", sent)
+	}
+}
+
+func TestCompileRejectsBadRules(t *testing.T) {
+	bad := []config.RuleSpec{
+		{Match: "x"},
+		{Name: "regex", Match: "("},
+		{Name: "signal", Match: "x", Signals: []string{"SIGBOGUS"}},
+	}
+	for _, spec := range bad {
+		if _, err := Compile(spec); err == nil {
+			t.Errorf("Compile(%+v) should fail", spec)
+		}
+	}
+}
+
+func TestCurrentCPUUsesRecentUsage(t *testing.T) {
+	var sent []sentSignal
+	e, _ := newTestEngine(t, config.Rules{Rules: []config.RuleSpec{workerRule()}}, &sent)
+	times := map[int]time.Duration{100: time.Hour, 200: time.Hour}
+	e.cpuTime = func(pids []int) (map[int]time.Duration, error) { return times, nil }
+
+	// ps averages over each process's lifetime: 100 only just started
+	// spinning, 200 has since gone idle
+	procs := func() []process.Process {
+		return []process.Process{{PID: 100, Name: "jest-worker", CPU: 5}, {PID: 200, Name: "jest-worker", CPU: 95}}
+	}
+	start := time.Now()
+	if got := e.currentCPU(start, procs()); got[0].CPU != 0 || got[1].CPU != 0 {
+		t.Errorf("Expected both idle on the first tick, got %+v", got)
+	}
+
+	times = map[int]time.Duration{100: time.Hour + 2*time.Second, 200: time.Hour}
+	got := e.currentCPU(start.Add(2*time.Second), procs())
+	if got[0].CPU != 100 || got[1].CPU != 0 {
+		t.Errorf("Expected 100%% for PID 100 and 0%% for PID 200, got %+v", got)
+	}
+
+	e.cpuTime = func(pids []int) (map[int]time.Duration, error) { return nil, errors.New("unsupported") }
+	if got := e.currentCPU(start.Add(4*time.Second), procs()); got[0].CPU != 5 || got[1].CPU != 95 {
+		t.Errorf("Expected ps figures when CPU times can't be read, got %+v", got)
+	}
+}
+
+func TestStopTwice(t *testing.T) {
+	var sent []sentSignal
+	e, _ := newTestEngine(t, config.Rules{}, &sent)
+	e.Start()
+	e.Stop()
+	e.Stop()
+}
@@ -0,0 +1,144 @@
+// Package selfstat measures what shinobi's own processes cost: CPU time,
+// resident memory, goroutines and how long each collection takes
+package selfstat
+
+import (
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Collection is the timing of one kind of collection, such as reading
+// memory or listing processes
+type Collection struct {
+	Name  string        `json:"name"`
+	Count uint64        `json:"count"`
+	Total time.Duration `json:"total_ns"`
+	Last  time.Duration `json:"last_ns"`
+	Max   time.Duration `json:"max_ns"`
+}
+
+// Mean returns the average duration of the collection
+func (c Collection) Mean() time.Duration {
+	if c.Count == 0 {
+		return 0
+	}
+	return c.Total / time.Duration(c.Count)
+}
+
+// Report is a snapshot of one process's overhead
+type Report struct {
+	Process     string        `json:"process"` // "sensei", "probe" or "dojo"
+	PID         int           `json:"pid"`
+	At          time.Time     `json:"at"`      // when the snapshot was taken
+	Started     time.Time     `json:"started"` // when the process started
+	CPUTime     time.Duration `json:"cpu_time_ns"`
+	RSS         uint64        `json:"rss_bytes"`
+	Goroutines  int           `json:"goroutines"`
+	Collections []Collection  `json:"collections,omitempty"` // sorted by name
+}
+
+// CPUPercent returns the share of one core the process used between prev
+// and r, or over its lifetime when prev is from another process
+func (r Report) CPUPercent(prev *Report) float64 {
+	from, cpu := r.Started, r.CPUTime
+	if prev != nil && prev.PID == r.PID && prev.At.Before(r.At) {
+		from, cpu = prev.At, r.CPUTime-prev.CPUTime
+	}
+	wall := r.At.Sub(from)
+	if wall <= 0 {
+		return 0
+	}
+	return 100 * float64(cpu) / float64(wall)
+}
+
+// Registry accumulates collection timings
+type Registry struct {
+	mu          sync.Mutex
+	collections map[string]*Collection
+}
+
+// Observe records that the named collection took d
+func (r *Registry) Observe(name string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.collections == nil {
+		r.collections = make(map[string]*Collection)
+	}
+	c, ok := r.collections[name]
+	if !ok {
+		c = &Collection{Name: name}
+		r.collections[name] = c
+	}
+	c.Count++
+	c.Total += d
+	c.Last = d
+	c.Max = max(c.Max, d)
+}
+
+// Collections returns every collection seen so far, sorted by name
+func (r *Registry) Collections() []Collection {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Collection, 0, len(r.collections))
+	for _, c := range r.collections {
+		out = append(out, *c)
+	}
+	slices.SortFunc(out, func(a, b Collection) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+// std is the process-wide registry behind Observe, Time and Snapshot
+var std Registry
+
+// started approximates the process start time
+var started = time.Now()
+
+// Observe records that the named collection took d
+func Observe(name string, d time.Duration) {
+	std.Observe(name, d)
+}
+
+// Time starts timing the named collection; call the returned function
+// when it is done, e.g. defer selfstat.Time("memory")()
+func Time(name string) func() {
+	start := time.Now()
+	return func() { std.Observe(name, time.Since(start)) }
+}
+
+// Snapshot reports the calling process's overhead under the given name
+func Snapshot(process string) Report {
+	r := Report{
+		Process:     process,
+		PID:         os.Getpid(),
+		At:          time.Now(),
+		Started:     started,
+		Goroutines:  runtime.NumGoroutine(),
+		Collections: std.Collections(),
+	}
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err == nil {
+		r.CPUTime = time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+	}
+	r.RSS = residentBytes(&usage)
+	return r
+}
+
+// parseStatm returns the resident size from /proc/self/statm, whose
+// second field counts resident pages
+func parseStatm(data string, pageSize uint64) uint64 {
+	fields := strings.Fields(data)
+	if len(fields) < 2 {
+		return 0
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return pages * pageSize
+}
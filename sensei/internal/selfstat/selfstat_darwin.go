@@ -0,0 +1,9 @@
+package selfstat
+
+import "syscall"
+
+// residentBytes returns the peak resident set size; the current size
+// needs task_info, which isn't reachable without cgo
+func residentBytes(usage *syscall.Rusage) uint64 {
+	return uint64(usage.Maxrss) // bytes on macOS
+}
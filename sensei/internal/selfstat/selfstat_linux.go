@@ -0,0 +1,15 @@
+package selfstat
+
+import (
+	"os"
+	"syscall"
+)
+
+// residentBytes reads the current resident set size from /proc
+func residentBytes(usage *syscall.Rusage) uint64 {
+	data, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return uint64(usage.Maxrss) * 1024 // peak, in KiB on Linux
+	}
+	return parseStatm(string(data), uint64(os.Getpagesize()))
+}
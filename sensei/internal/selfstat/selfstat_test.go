@@ -0,0 +1,82 @@
+package selfstat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegistryObserve(t *testing.T) {
+	var r Registry
+	r.Observe("memory", 2*time.Millisecond)
+	r.Observe("cpu", time.Millisecond)
+	r.Observe("memory", 6*time.Millisecond)
+	r.Observe("memory", 4*time.Millisecond)
+
+	got := r.Collections()
+	if len(got) != 2 || got[0].Name != "cpu" || got[1].Name != "memory" {
+		t.Fatalf("Expected cpu then memory, got %+v", got)
+	}
+	mem := got[1]
+	if mem.Count != 3 || mem.Last != 4*time.Millisecond || mem.Max != 6*time.Millisecond {
+		t.Errorf("Unexpected memory collection %+v", mem)
+	}
+	if mem.Mean() != 4*time.Millisecond {
+		t.Errorf("Expected a 4ms mean, got %s", mem.Mean())
+	}
+	if (Collection{}).Mean() != 0 {
+		t.Error("Expected a zero mean for an empty collection")
+	}
+}
+
+func TestCPUPercent(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	prev := Report{PID: 7, Started: start, At: start.Add(10 * time.Second), CPUTime: time.Second}
+	cur := Report{PID: 7, Started: start, At: start.Add(20 * time.Second), CPUTime: 6 * time.Second}
+
+	tests := []struct {
+		name string
+		prev *Report
+		want float64
+	}{
+		{"since previous", &prev, 50},
+		{"lifetime without previous", nil, 30},
+		{"lifetime after restart", &Report{PID: 8, At: prev.At}, 30},
+	}
+	for _, tt := range tests {
+		if got := cur.CPUPercent(tt.prev); got != tt.want {
+			t.Errorf("%s: expected %v%%, got %v%%", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	Observe("snapshot-test", time.Millisecond)
+	r := Snapshot("test")
+	if r.Process != "test" || r.PID == 0 || r.Goroutines == 0 {
+		t.Errorf("Unexpected report %+v", r)
+	}
+	found := false
+	for _, c := range r.Collections {
+		found = found || c.Name == "snapshot-test"
+	}
+	if !found {
+		t.Errorf("Expected the observed collection in %+v", r.Collections)
+	}
+}
+
+func TestParseStatm(t *testing.T) {
+	tests := []struct {
+		data string
+		want uint64
+	}{
+		{"1000 250 80 10 0 300 0\n", 250 * 4096},
+		{"1000", 0},
+		{"1000 x 80", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := parseStatm(tt.data, 4096); got != tt.want {
+			t.Errorf("parseStatm(%q) = %d, want %d", tt.data, got, tt.want)
+		}
+	}
+}
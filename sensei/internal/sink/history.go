@@ -0,0 +1,92 @@
+package sink
+
+import (
+	"time"
+
+	"system-shinobi/sensei/internal/metrics"
+)
+
+// historySpan is how far back History looks
+const historySpan = 15 * time.Minute
+
+// Windows are the spans CPU averages are reported over
+var Windows = []struct {
+	Label  string
+	Window time.Duration
+}{{"1m", time.Minute}, {"5m", 5 * time.Minute}, {"15m", 15 * time.Minute}}
+
+type cpuSample struct {
+	at  time.Time
+	cpu float64
+}
+
+// History keeps the last 15 minutes of readings, for the tray's History
+// submenu and the exporter's averages. It is not safe for concurrent use.
+type History struct {
+	samples     []cpuSample // oldest first
+	connectedAt time.Time
+	mem         *metrics.Memory
+}
+
+// Add records a reading taken at now, starting the connection clock on
+// the first reading after a Reset
+func (h *History) Add(now time.Time, cpu float64, mem *metrics.Memory) {
+	if h.connectedAt.IsZero() {
+		h.connectedAt = now
+	}
+	h.samples = append(h.samples, cpuSample{now, cpu})
+	drop := 0
+	for drop < len(h.samples) && now.Sub(h.samples[drop].at) > historySpan {
+		drop++
+	}
+	h.samples = h.samples[drop:]
+	if mem != nil {
+		h.mem = mem
+	}
+}
+
+// Reset forgets everything, e.g. when the probe disconnects
+func (h *History) Reset() {
+	*h = History{}
+}
+
+// Average returns the mean CPU over the readings within window of now
+func (h *History) Average(now time.Time, window time.Duration) (float64, bool) {
+	var sum float64
+	n := 0
+	for _, s := range h.samples {
+		if now.Sub(s.at) < window {
+			sum += s.cpu
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
+
+// Peak returns the highest CPU reading in the history and when it was taken
+func (h *History) Peak() (float64, time.Time, bool) {
+	if len(h.samples) == 0 {
+		return 0, time.Time{}, false
+	}
+	peak := h.samples[0]
+	for _, s := range h.samples[1:] {
+		if s.cpu >= peak.cpu {
+			peak = s
+		}
+	}
+	return peak.cpu, peak.at, true
+}
+
+// ConnectedAt returns when the first reading since the last Reset
+// arrived, or the zero time if none has
+func (h *History) ConnectedAt() time.Time {
+	return h.connectedAt
+}
+
+// Memory returns the most recent memory reading, or nil
+func (h *History) Memory() *metrics.Memory {
+	return h.mem
+}
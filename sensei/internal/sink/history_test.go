@@ -0,0 +1,42 @@
+package sink
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryAverages(t *testing.T) {
+	var h History
+	start := time.Date(2024, 2, 13, 14, 0, 0, 0, time.Local)
+
+	// 10 minutes at 20%, then a minute at 80%
+	for i := 0; i < 600; i++ {
+		h.Add(start.Add(time.Duration(i)*time.Second), 20, nil)
+	}
+	for i := 600; i < 660; i++ {
+		h.Add(start.Add(time.Duration(i)*time.Second), 80, nil)
+	}
+	now := start.Add(659 * time.Second)
+
+	if avg, _ := h.Average(now, time.Minute); avg != 80 {
+		t.Errorf("1m average = %v, expected 80", avg)
+	}
+	if avg, _ := h.Average(now, 5*time.Minute); avg < 31 || avg > 33 {
+		t.Errorf("5m average = %v, expected about 32", avg)
+	}
+	if avg, _ := h.Average(now, 15*time.Minute); avg < 25 || avg > 26 {
+		t.Errorf("15m average = %v, expected about 25.5", avg)
+	}
+}
+
+func TestHistoryDropsOldSamples(t *testing.T) {
+	var h History
+	start := time.Date(2024, 2, 13, 14, 0, 0, 0, time.Local)
+	h.Add(start, 99, nil)
+	h.Add(start.Add(20*time.Minute), 10, nil)
+
+	peak, at, ok := h.Peak()
+	if !ok || peak != 10 || !at.Equal(start.Add(20*time.Minute)) {
+		t.Errorf("Expected the 99%% peak to have aged out, got %v at %v", peak, at)
+	}
+}
@@ -0,0 +1,86 @@
+// Package sink fans classified probe readings out to the places sensei
+// shows them: the tray, alerts, the status socket and the exporter
+package sink
+
+import (
+	"time"
+
+	"system-shinobi/sensei/internal/icon"
+	"system-shinobi/sensei/internal/pipe"
+	"system-shinobi/sensei/internal/selfstat"
+)
+
+// Sample is one reading after classification, as handed to every sink
+type Sample struct {
+	Time    time.Time
+	Reading pipe.CpuReading
+	Class   icon.Class
+
+	// History holds the readings since the probe connected, this one
+	// included. Sinks may read it only during Update.
+	History *History
+}
+
+// Sink receives the pipeline's output. Calls come from a single goroutine.
+type Sink interface {
+	// Update handles a new reading
+	Update(s Sample)
+	// Disconnected is called when the probe goes away; idle is the class
+	// to show until it comes back
+	Disconnected(now time.Time, idle icon.Class)
+}
+
+// Pipeline smooths and classifies readings, keeps their history and
+// passes them to its sinks. It is not safe for concurrent use.
+type Pipeline struct {
+	smoother *icon.Smoother
+	percent  func(pipe.CpuReading) float64 // the percentage to classify
+	idle     icon.Class
+	history  History
+	sinks    []Sink
+}
+
+// NewPipeline returns a pipeline that classifies the percentage picked by
+// percent through smoother
+func NewPipeline(smoother *icon.Smoother, classifier *icon.Classifier, percent func(pipe.CpuReading) float64, sinks ...Sink) *Pipeline {
+	return &Pipeline{
+		smoother: smoother,
+		percent:  percent,
+		idle:     classifier.Classify(0),
+		sinks:    sinks,
+	}
+}
+
+// Add appends a sink. It must be called before readings flow.
+func (p *Pipeline) Add(s Sink) {
+	p.sinks = append(p.sinks, s)
+}
+
+// Update classifies a reading taken at now and hands it to every sink
+func (p *Pipeline) Update(now time.Time, reading pipe.CpuReading) {
+	defer selfstat.Time("pipeline")()
+
+	class := p.smoother.Update(now, p.percent(reading))
+	p.history.Add(now, reading.CpuPercent, reading.Memory)
+	s := Sample{Time: now, Reading: reading, Class: class, History: &p.history}
+	for _, sink := range p.sinks {
+		sink.Update(s)
+	}
+}
+
+// Disconnected forgets the smoothing and history and tells every sink
+// the probe went away
+func (p *Pipeline) Disconnected(now time.Time) {
+	p.smoother.Reset()
+	p.history.Reset()
+	for _, sink := range p.sinks {
+		sink.Disconnected(now, p.idle)
+	}
+}
+
+// Read feeds readings into the pipeline until the channel closes
+func (p *Pipeline) Read(readings <-chan pipe.CpuReading) {
+	for reading := range readings {
+		p.Update(time.Now(), reading)
+	}
+}
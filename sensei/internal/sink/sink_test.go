@@ -0,0 +1,77 @@
+package sink
+
+import (
+	"testing"
+	"time"
+
+	"system-shinobi/sensei/internal/config"
+	"system-shinobi/sensei/internal/icon"
+	"system-shinobi/sensei/internal/pipe"
+)
+
+// recorder is a sink that keeps what it was given
+type recorder struct {
+	samples      []Sample
+	averages     []float64
+	disconnected []icon.Class
+}
+
+func (r *recorder) Update(s Sample) {
+	r.samples = append(r.samples, s)
+	avg, _ := s.History.Average(s.Time, time.Minute)
+	r.averages = append(r.averages, avg)
+}
+
+func (r *recorder) Disconnected(now time.Time, idle icon.Class) {
+	r.disconnected = append(r.disconnected, idle)
+}
+
+func newTestPipeline(t *testing.T, sinks ...Sink) *Pipeline {
+	t.Helper()
+	classifier := icon.DefaultClassifier()
+	smoother, err := icon.NewSmoother(classifier, config.Smoothing{Method: "none"})
+	if err != nil {
+		t.Fatalf("NewSmoother failed: %v", err)
+	}
+	percent := func(r pipe.CpuReading) float64 { return r.CpuPercent }
+	return NewPipeline(smoother, classifier, percent, sinks...)
+}
+
+func TestPipelineFansOut(t *testing.T) {
+	first, second := &recorder{}, &recorder{}
+	p := newTestPipeline(t, first)
+	p.Add(second)
+
+	start := time.Date(2024, 2, 13, 14, 0, 0, 0, time.UTC)
+	p.Update(start, pipe.CpuReading{CpuPercent: 10})
+	p.Update(start.Add(time.Second), pipe.CpuReading{CpuPercent: 90})
+
+	for _, r := range []*recorder{first, second} {
+		if len(r.samples) != 2 {
+			t.Fatalf("Sink got %d samples, expected 2", len(r.samples))
+		}
+		if r.samples[1].Class.State != icon.StateHigh {
+			t.Errorf("90%% classified as %v, expected High", r.samples[1].Class.State)
+		}
+		if r.averages[1] != 50 {
+			t.Errorf("History average = %v, expected 50", r.averages[1])
+		}
+	}
+}
+
+func TestPipelineDisconnectResetsHistory(t *testing.T) {
+	r := &recorder{}
+	p := newTestPipeline(t, r)
+
+	start := time.Date(2024, 2, 13, 14, 0, 0, 0, time.UTC)
+	p.Update(start, pipe.CpuReading{CpuPercent: 80})
+	p.Disconnected(start.Add(time.Second))
+	p.Update(start.Add(2*time.Second), pipe.CpuReading{CpuPercent: 20})
+
+	if len(r.disconnected) != 1 || r.disconnected[0].State != icon.StateIdle {
+		t.Errorf("Expected one disconnect with the idle class, got %+v", r.disconnected)
+	}
+	if r.averages[1] != 20 {
+		t.Errorf("Average after reconnect = %v, expected 20", r.averages[1])
+	}
+}
@@ -0,0 +1,320 @@
+// Package supervisor runs the probe as a child of sensei, restarting it
+// with backoff when it exits and logging what it prints to stderr
+package supervisor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"system-shinobi/sensei/internal/config"
+)
+
+const (
+	// minBackoff is the delay before the first restart
+	minBackoff = time.Second
+
+	// defaultMaxBackoff caps the delay when the config doesn't
+	defaultMaxBackoff = time.Minute
+
+	// stableRun is how long a run must last for the backoff to reset
+	stableRun = 30 * time.Second
+
+	// fifoPoll is how often a fresh probe is checked for its FIFO
+	fifoPoll = 100 * time.Millisecond
+
+	// stopGrace is how long the probe gets to exit after SIGTERM
+	stopGrace = 3 * time.Second
+)
+
+// Health describes the supervised probe for the tray and --status
+type Health struct {
+	Running   bool      `json:"running"`
+	PID       int       `json:"pid,omitempty"`
+	Started   time.Time `json:"started,omitempty"` // start of the current run
+	Restarts  int       `json:"restarts"`
+	LastError string    `json:"last_error,omitempty"` // why the last run ended
+}
+
+// process is the part of a started probe the supervisor uses
+type process interface {
+	Pid() int
+	Signal(sig os.Signal) error
+	Wait() error
+}
+
+// Supervisor keeps one probe running until Stop
+type Supervisor struct {
+	path       string
+	args       []string
+	fifo       string
+	maxBackoff time.Duration
+
+	start func(path string, args []string, stderr io.Writer) (process, error)
+
+	mu     sync.Mutex
+	health Health
+
+	ready    chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// New returns a supervisor for the probe described by cfg, which writes
+// its readings to the FIFO at fifo. path is the probe binary.
+func New(cfg config.Probe, path, fifo string) (*Supervisor, error) {
+	if path == "" {
+		return nil, errors.New("probe path is empty")
+	}
+	if cfg.MaxBackoff.Duration < 0 {
+		return nil, fmt.Errorf("max_backoff must not be negative, got %s", cfg.MaxBackoff)
+	}
+	maxBackoff := cfg.MaxBackoff.Duration
+	if maxBackoff == 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	return &Supervisor{
+		path:       path,
+		args:       cfg.Args,
+		fifo:       fifo,
+		maxBackoff: max(maxBackoff, minBackoff),
+		start:      startExec,
+		ready:      make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}, nil
+}
+
+// Ready receives each time a fresh probe has created its FIFO and can be
+// connected to
+func (s *Supervisor) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// Health returns the probe's current state
+func (s *Supervisor) Health() Health {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.health
+}
+
+// Run starts the probe and restarts it whenever it exits, until Stop
+func (s *Supervisor) Run() {
+	defer close(s.done)
+
+	var delay time.Duration
+	for {
+		// A crashed probe leaves its FIFO behind; opening it would wait
+		// for a writer that never comes
+		clearFIFO(s.fifo)
+
+		started := time.Now()
+		err := s.runOnce(started)
+		clearFIFO(s.fifo)
+		if s.stopping() {
+			return
+		}
+		if err == nil {
+			err = errors.New("exited")
+		}
+
+		delay = nextBackoff(delay, time.Since(started), s.maxBackoff)
+		s.exited(err)
+		slog.Warn("Probe stopped", "err", err, "restart_in", delay)
+
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(delay):
+		}
+		s.mu.Lock()
+		s.health.Restarts++
+		s.mu.Unlock()
+	}
+}
+
+// runOnce starts the probe and waits for it to exit or for Stop
+func (s *Supervisor) runOnce(started time.Time) error {
+	stderr := &lineLogger{}
+	proc, err := s.start(s.path, s.args, stderr)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.health.Running = true
+	s.health.PID = proc.Pid()
+	s.health.Started = started
+	s.mu.Unlock()
+	slog.Info("Probe started", "pid", proc.Pid(), "path", s.path)
+
+	waited := make(chan error, 1)
+	go func() { waited <- proc.Wait() }()
+
+	poll := time.NewTicker(fifoPoll)
+	defer poll.Stop()
+	for {
+		select {
+		case err := <-waited:
+			stderr.Flush()
+			return withStderr(err, stderr.Last())
+		case <-s.stop:
+			proc.Signal(syscall.SIGTERM)
+			select {
+			case <-waited:
+			case <-time.After(stopGrace):
+				proc.Signal(syscall.SIGKILL)
+				<-waited
+			}
+			stderr.Flush()
+			return nil
+		case <-poll.C:
+			if isFIFO(s.fifo) {
+				poll.Stop()
+				select {
+				case s.ready <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// exited records a finished run
+func (s *Supervisor) exited(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.health.Running = false
+	s.health.PID = 0
+	s.health.LastError = err.Error()
+}
+
+func (s *Supervisor) stopping() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stop terminates the probe and waits for Run to return. It is safe to
+// call on a nil Supervisor.
+func (s *Supervisor) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+// nextBackoff returns the delay before the next restart: minBackoff after
+// the first exit or a run of at least stableRun, otherwise double the
+// previous delay up to limit
+func nextBackoff(prev, ran, limit time.Duration) time.Duration {
+	if prev == 0 || ran >= stableRun {
+		return min(minBackoff, limit)
+	}
+	return min(prev*2, limit)
+}
+
+// withStderr adds the probe's last stderr line to an exit error, which
+// usually says why it gave up
+func withStderr(err error, last string) error {
+	if err == nil || last == "" {
+		return err
+	}
+	return fmt.Errorf("%w: %s", err, last)
+}
+
+// isFIFO reports whether a named pipe exists at path
+func isFIFO(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode()&os.ModeNamedPipe != 0
+}
+
+// clearFIFO removes a FIFO left at path, first opening it for writing so
+// that a reader blocked opening it sees end of file
+func clearFIFO(path string) {
+	if !isFIFO(path) {
+		return
+	}
+	if f, err := os.OpenFile(path, os.O_WRONLY|syscall.O_NONBLOCK, 0); err == nil {
+		f.Close()
+	}
+	os.Remove(path)
+}
+
+// execProcess adapts a started exec.Cmd to process
+type execProcess struct {
+	cmd *exec.Cmd
+}
+
+func (p execProcess) Pid() int                   { return p.cmd.Process.Pid }
+func (p execProcess) Signal(sig os.Signal) error { return p.cmd.Process.Signal(sig) }
+func (p execProcess) Wait() error                { return p.cmd.Wait() }
+
+func startExec(path string, args []string, stderr io.Writer) (process, error) {
+	cmd := exec.Command(path, args...)
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return execProcess{cmd}, nil
+}
+
+// lineLogger writes each line the probe prints to the sensei log and
+// remembers the last one
+type lineLogger struct {
+	mu      sync.Mutex
+	partial []byte
+	last    string
+}
+
+func (l *lineLogger) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.partial = append(l.partial, p...)
+	for {
+		i := bytes.IndexByte(l.partial, '\n')
+		if i < 0 {
+			break
+		}
+		l.log(l.partial[:i])
+		l.partial = l.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush logs a final line that didn't end in a newline
+func (l *lineLogger) Flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.partial) > 0 {
+		l.log(l.partial)
+		l.partial = nil
+	}
+}
+
+// Last returns the last non-empty line written
+func (l *lineLogger) Last() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.last
+}
+
+func (l *lineLogger) log(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+	l.last = string(line)
+	slog.Info("Probe stderr", "line", string(line))
+}
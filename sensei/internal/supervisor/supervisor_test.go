@@ -0,0 +1,165 @@
+package supervisor
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"system-shinobi/sensei/internal/config"
+)
+
+// fakeProcess runs until it is signalled
+type fakeProcess struct {
+	exit chan error
+}
+
+func (p *fakeProcess) Pid() int { return 4242 }
+
+func (p *fakeProcess) Signal(sig os.Signal) error {
+	select {
+	case p.exit <- errors.New("signal: " + sig.String()):
+	default:
+	}
+	return nil
+}
+
+func (p *fakeProcess) Wait() error { return <-p.exit }
+
+func TestNextBackoff(t *testing.T) {
+	limit := 10 * time.Second
+	tests := []struct {
+		prev, ran, expected time.Duration
+	}{
+		{0, time.Second, minBackoff},
+		{time.Second, time.Second, 2 * time.Second},
+		{4 * time.Second, time.Second, 8 * time.Second},
+		{8 * time.Second, time.Second, limit},
+		{limit, time.Second, limit},
+		{limit, stableRun, minBackoff},
+	}
+	for _, tt := range tests {
+		if got := nextBackoff(tt.prev, tt.ran, limit); got != tt.expected {
+			t.Errorf("nextBackoff(%s, %s) = %s, expected %s", tt.prev, tt.ran, got, tt.expected)
+		}
+	}
+}
+
+func TestNewValidates(t *testing.T) {
+	if _, err := New(config.Probe{}, "", "/tmp/x.pipe"); err == nil {
+		t.Error("Expected an error for an empty probe path")
+	}
+	cfg := config.Probe{MaxBackoff: config.Duration{Duration: -time.Second}}
+	if _, err := New(cfg, "probe", "/tmp/x.pipe"); err == nil {
+		t.Error("Expected an error for a negative max_backoff")
+	}
+	s, err := New(config.Probe{}, "probe", "/tmp/x.pipe")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if s.maxBackoff != defaultMaxBackoff {
+		t.Errorf("maxBackoff = %s, expected %s", s.maxBackoff, defaultMaxBackoff)
+	}
+}
+
+func TestLineLoggerKeepsLastLine(t *testing.T) {
+	var l lineLogger
+	io.WriteString(&l, "starting\nFailed to ")
+	if got := l.Last(); got != "starting" {
+		t.Errorf("Last() = %q, expected %q", got, "starting")
+	}
+	io.WriteString(&l, "open pipe\n\n")
+	if got := l.Last(); got != "Failed to open pipe" {
+		t.Errorf("Last() = %q, expected %q", got, "Failed to open pipe")
+	}
+	io.WriteString(&l, "no newline")
+	l.Flush()
+	if got := l.Last(); got != "no newline" {
+		t.Errorf("Last() after Flush = %q, expected %q", got, "no newline")
+	}
+}
+
+func TestWithStderr(t *testing.T) {
+	err := withStderr(errors.New("exit status 1"), "Failed to open pipe")
+	if err.Error() != "exit status 1: Failed to open pipe" {
+		t.Errorf("withStderr = %q", err)
+	}
+	if withStderr(nil, "ignored") != nil {
+		t.Error("Expected nil for a clean exit")
+	}
+}
+
+func TestRunSignalsReadyAndStops(t *testing.T) {
+	fifo := filepath.Join(t.TempDir(), "test.pipe")
+	s, err := New(config.Probe{}, "probe", fifo)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	proc := &fakeProcess{exit: make(chan error, 1)}
+	s.start = func(path string, args []string, stderr io.Writer) (process, error) {
+		// Like the real probe, create the FIFO once started
+		if err := syscall.Mkfifo(fifo, 0600); err != nil {
+			return nil, err
+		}
+		return proc, nil
+	}
+
+	go s.Run()
+	select {
+	case <-s.Ready():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Supervisor never reported the probe ready")
+	}
+	if h := s.Health(); !h.Running || h.PID != 4242 || h.Restarts != 0 {
+		t.Errorf("Health = %+v, expected running PID 4242 with no restarts", h)
+	}
+
+	s.Stop()
+	if h := s.Health(); h.Restarts != 0 {
+		t.Errorf("Stop counted as a restart: %+v", h)
+	}
+	if _, err := os.Stat(fifo); !os.IsNotExist(err) {
+		t.Errorf("Expected the FIFO to be removed after Stop, got %v", err)
+	}
+}
+
+func TestRunRecordsFailedStart(t *testing.T) {
+	s, err := New(config.Probe{}, "probe", filepath.Join(t.TempDir(), "test.pipe"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	started := make(chan struct{}, 1)
+	s.start = func(path string, args []string, stderr io.Writer) (process, error) {
+		started <- struct{}{}
+		return nil, errors.New("no such file or directory")
+	}
+
+	go s.Run()
+	<-started
+	deadline := time.Now().Add(2 * time.Second)
+	for s.Health().LastError == "" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	h := s.Health()
+	if h.Running || !strings.Contains(h.LastError, "no such file") {
+		t.Errorf("Health = %+v, expected a stopped probe with the start error", h)
+	}
+
+	// Stop must not wait out the backoff
+	done := make(chan struct{})
+	go func() { s.Stop(); close(done) }()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Stop waited for the restart backoff")
+	}
+}
+
+func TestStopNil(t *testing.T) {
+	var s *Supervisor
+	s.Stop()
+}
@@ -3,13 +3,10 @@ package sysinfo
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"runtime"
-	"strconv"
-	"strings"
 	"time"
 
-	"golang.org/x/sys/unix"
+	"system-shinobi/sensei/internal/metrics"
 )
 
 // Info holds system information for the !clone scroll
@@ -21,9 +18,11 @@ type Info struct {
 	Cores     int
 	MemTotal  uint64 // bytes
 	MemUsed   uint64 // bytes
+	Load      metrics.Load
+	Power     metrics.Power
 }
 
-// Collect gathers system info from macOS APIs
+// Collect gathers system info from the platform's APIs
 func Collect() Info {
 	info := Info{
 		Cores: runtime.NumCPU(),
@@ -33,7 +32,15 @@ func Collect() Info {
 	info.OSVersion = getOSVersion()
 	info.Uptime = getUptime()
 	info.CPUModel = getCPUModel()
-	info.MemTotal, info.MemUsed = getMemory()
+	if mem, err := metrics.ReadMemory(); err == nil {
+		info.MemTotal, info.MemUsed = mem.Total, mem.Used
+	}
+	if load, err := metrics.ReadLoad(); err == nil {
+		info.Load = load
+	}
+	if power, err := metrics.ReadPower(); err == nil {
+		info.Power = power
+	}
 
 	return info
 }
@@ -48,6 +55,18 @@ func FormatMemory(bytes uint64) string {
 	return fmt.Sprintf("%.0f MB", mb)
 }
 
+// FormatRate formats a transfer rate in bytes per second
+func FormatRate(bytesPerSec float64) string {
+	switch {
+	case bytesPerSec >= 1024*1024:
+		return fmt.Sprintf("%.1f MB/s", bytesPerSec/(1024*1024))
+	case bytesPerSec >= 1024:
+		return fmt.Sprintf("%.1f KB/s", bytesPerSec/1024)
+	default:
+		return fmt.Sprintf("%.0f B/s", bytesPerSec)
+	}
+}
+
 // FormatUptime formats a duration as a human-readable string
 func FormatUptime(d time.Duration) string {
 	days := int(d.Hours()) / 24
@@ -61,70 +80,3 @@ func FormatUptime(d time.Duration) string {
 	}
 	return fmt.Sprintf("%dm", mins)
 }
-
-func getOSVersion() string {
-	out, err := exec.Command("sw_vers", "-productVersion").Output()
-	if err != nil {
-		return "unknown"
-	}
-	return "macOS " + strings.TrimSpace(string(out))
-}
-
-func getUptime() time.Duration {
-	tv, err := unix.SysctlTimeval("kern.boottime")
-	if err != nil {
-		return 0
-	}
-	boot := time.Unix(tv.Sec, int64(tv.Usec)*1000)
-	return time.Since(boot)
-}
-
-func getCPUModel() string {
-	out, err := exec.Command("sysctl", "-n", "machdep.cpu.brand_string").Output()
-	if err != nil {
-		return "unknown"
-	}
-	return strings.TrimSpace(string(out))
-}
-
-func getMemory() (total, used uint64) {
-	totalMem, err := unix.SysctlUint64("hw.memsize")
-	if err != nil {
-		return 0, 0
-	}
-	total = totalMem
-
-	// Parse vm_stat output for memory usage
-	out, err := exec.Command("vm_stat").Output()
-	if err != nil {
-		return total, 0
-	}
-
-	pageSize := uint64(unix.Getpagesize())
-	var freePages, inactivePages uint64
-
-	for _, line := range strings.Split(string(out), "\n") {
-		if strings.HasPrefix(line, "Pages free:") {
-			freePages = parseVmStatValue(line)
-		} else if strings.HasPrefix(line, "Pages inactive:") {
-			inactivePages = parseVmStatValue(line)
-		}
-	}
-
-	free := (freePages + inactivePages) * pageSize
-	if free > total {
-		return total, 0
-	}
-	used = total - free
-	return
-}
-
-func parseVmStatValue(line string) uint64 {
-	parts := strings.SplitN(line, ":", 2)
-	if len(parts) < 2 {
-		return 0
-	}
-	s := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(parts[1]), "."))
-	val, _ := strconv.ParseUint(s, 10, 64)
-	return val
-}
@@ -0,0 +1,34 @@
+package sysinfo
+
+import (
+	"os/exec"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func getOSVersion() string {
+	out, err := exec.Command("sw_vers", "-productVersion").Output()
+	if err != nil {
+		return "unknown"
+	}
+	return "macOS " + strings.TrimSpace(string(out))
+}
+
+func getUptime() time.Duration {
+	tv, err := unix.SysctlTimeval("kern.boottime")
+	if err != nil {
+		return 0
+	}
+	boot := time.Unix(tv.Sec, int64(tv.Usec)*1000)
+	return time.Since(boot)
+}
+
+func getCPUModel() string {
+	out, err := exec.Command("sysctl", "-n", "machdep.cpu.brand_string").Output()
+	if err != nil {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(out))
+}
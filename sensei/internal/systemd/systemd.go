@@ -0,0 +1,129 @@
+// Package systemd lists and controls systemd units over D-Bus
+package systemd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	busName      = "org.freedesktop.systemd1"
+	busPath      = dbus.ObjectPath("/org/freedesktop/systemd1")
+	managerIface = "org.freedesktop.systemd1.Manager"
+)
+
+// Unit is one service known to the systemd manager
+type Unit struct {
+	Name        string
+	Description string
+	LoadState   string // loaded, not-found, masked...
+	ActiveState string // active, inactive, failed...
+	SubState    string // running, exited, dead...
+}
+
+// caller is the part of dbus.BusObject the Manager uses, so tests can
+// stand in for systemd
+type caller interface {
+	Call(method string, flags dbus.Flags, args ...interface{}) *dbus.Call
+}
+
+// Manager talks to a systemd instance, the system one or the user's
+type Manager struct {
+	conn *dbus.Conn
+	obj  caller
+}
+
+// Connect opens the system bus, or the session bus when user is true,
+// and returns a Manager for the systemd instance on it
+func Connect(user bool) (*Manager, error) {
+	var conn *dbus.Conn
+	var err error
+	if user {
+		conn, err = dbus.ConnectSessionBus()
+	} else {
+		conn, err = dbus.ConnectSystemBus()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connecting to D-Bus: %w", err)
+	}
+	return &Manager{conn: conn, obj: conn.Object(busName, busPath)}, nil
+}
+
+// unitStatus mirrors one a(ssssssouso) entry returned by ListUnits
+type unitStatus struct {
+	Name        string
+	Description string
+	LoadState   string
+	ActiveState string
+	SubState    string
+	Followed    string
+	Path        dbus.ObjectPath
+	JobID       uint32
+	JobType     string
+	JobPath     dbus.ObjectPath
+}
+
+// ListServices returns every loaded service unit, sorted by name
+func (m *Manager) ListServices() ([]Unit, error) {
+	var statuses []unitStatus
+	if err := m.obj.Call(managerIface+".ListUnits", 0).Store(&statuses); err != nil {
+		return nil, fmt.Errorf("ListUnits: %w", err)
+	}
+
+	var units []Unit
+	for _, s := range statuses {
+		if !strings.HasSuffix(s.Name, ".service") {
+			continue
+		}
+		units = append(units, Unit{
+			Name:        s.Name,
+			Description: s.Description,
+			LoadState:   s.LoadState,
+			ActiveState: s.ActiveState,
+			SubState:    s.SubState,
+		})
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].Name < units[j].Name })
+	return units, nil
+}
+
+// Stop asks systemd to stop the unit
+func (m *Manager) Stop(name string) error {
+	return m.control("StopUnit", name, "replace")
+}
+
+// Restart asks systemd to restart the unit, starting it if it is stopped
+func (m *Manager) Restart(name string) error {
+	return m.control("RestartUnit", name, "replace")
+}
+
+// Mask links the unit to /dev/null so nothing can start it, then
+// reloads systemd like `systemctl mask` does. It doesn't stop the unit.
+func (m *Manager) Mask(name string) error {
+	// MaskUnitFiles(files, runtime, force)
+	if err := m.control("MaskUnitFiles", []string{name}, false, true); err != nil {
+		return err
+	}
+	return m.control("Reload")
+}
+
+// Close closes the D-Bus connection
+func (m *Manager) Close() error {
+	if m == nil || m.conn == nil {
+		return nil
+	}
+	return m.conn.Close()
+}
+
+// control calls a manager method that changes state. polkit may ask
+// the user to authenticate.
+func (m *Manager) control(method string, args ...interface{}) error {
+	call := m.obj.Call(managerIface+"."+method, dbus.FlagAllowInteractiveAuthorization, args...)
+	if call.Err != nil {
+		return fmt.Errorf("%s: %w", method, call.Err)
+	}
+	return nil
+}
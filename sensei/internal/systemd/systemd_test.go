@@ -0,0 +1,97 @@
+package systemd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+type busCall struct {
+	method string
+	args   []interface{}
+}
+
+// fakeSystemd answers manager calls with canned replies and records them
+type fakeSystemd struct {
+	calls   []busCall
+	replies map[string][]interface{}
+	fail    map[string]error
+}
+
+func (f *fakeSystemd) Call(method string, flags dbus.Flags, args ...interface{}) *dbus.Call {
+	f.calls = append(f.calls, busCall{method, args})
+	return &dbus.Call{Method: method, Args: args, Body: f.replies[method], Err: f.fail[method]}
+}
+
+func newTestManager() (*Manager, *fakeSystemd) {
+	fake := &fakeSystemd{replies: map[string][]interface{}{}, fail: map[string]error{}}
+	return &Manager{obj: fake}, fake
+}
+
+func TestListServices(t *testing.T) {
+	m, fake := newTestManager()
+	fake.replies[managerIface+".ListUnits"] = []interface{}{[]unitStatus{
+		{Name: "sshd.service", Description: "OpenSSH", LoadState: "loaded", ActiveState: "active", SubState: "running"},
+		{Name: "-.mount", LoadState: "loaded", ActiveState: "active", SubState: "mounted"},
+		{Name: "cups.service", LoadState: "loaded", ActiveState: "failed", SubState: "failed"},
+	}}
+
+	units, err := m.ListServices()
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+	if len(units) != 2 || units[0].Name != "cups.service" || units[1].Name != "sshd.service" {
+		t.Fatalf("Expected the two services sorted by name, got %+v", units)
+	}
+	if units[1].Description != "OpenSSH" || units[1].SubState != "running" {
+		t.Errorf("Unexpected unit: %+v", units[1])
+	}
+}
+
+func TestStopAndRestart(t *testing.T) {
+	m, fake := newTestManager()
+
+	if err := m.Stop("nginx.service"); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if err := m.Restart("nginx.service"); err != nil {
+		t.Fatalf("Restart failed: %v", err)
+	}
+
+	want := []string{managerIface + ".StopUnit", managerIface + ".RestartUnit"}
+	for i, c := range fake.calls {
+		if c.method != want[i] || c.args[0] != "nginx.service" || c.args[1] != "replace" {
+			t.Errorf("Call %d: got %s%v", i, c.method, c.args)
+		}
+	}
+}
+
+func TestMaskReloads(t *testing.T) {
+	m, fake := newTestManager()
+
+	if err := m.Mask("cups.service"); err != nil {
+		t.Fatalf("Mask failed: %v", err)
+	}
+	if len(fake.calls) != 2 || fake.calls[0].method != managerIface+".MaskUnitFiles" || fake.calls[1].method != managerIface+".Reload" {
+		t.Fatalf("Expected MaskUnitFiles then Reload, got %+v", fake.calls)
+	}
+	files, ok := fake.calls[0].args[0].([]string)
+	if !ok || len(files) != 1 || files[0] != "cups.service" {
+		t.Errorf("Unexpected MaskUnitFiles args: %v", fake.calls[0].args)
+	}
+}
+
+func TestControlErrors(t *testing.T) {
+	m, fake := newTestManager()
+	denied := errors.New("org.freedesktop.PolicyKit1.Error.NotAuthorized")
+	fake.fail[managerIface+".MaskUnitFiles"] = denied
+
+	err := m.Mask("cups.service")
+	if !errors.Is(err, denied) {
+		t.Errorf("Expected the D-Bus error to be wrapped, got %v", err)
+	}
+	if len(fake.calls) != 1 {
+		t.Error("Reload should not run after a failed mask")
+	}
+}
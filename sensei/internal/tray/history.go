@@ -0,0 +1,64 @@
+package tray
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/systray"
+	"system-shinobi/sensei/internal/sink"
+	"system-shinobi/sensei/internal/sysinfo"
+)
+
+// HistoryLines formats the History submenu entries, in menu order
+func HistoryLines(h *sink.History, now time.Time) []string {
+	var lines []string
+	for _, w := range sink.Windows {
+		if avg, ok := h.Average(now, w.Window); ok {
+			lines = append(lines, fmt.Sprintf("CPU avg %s: %.1f%%", w.Label, avg))
+		} else {
+			lines = append(lines, fmt.Sprintf("CPU avg %s: --", w.Label))
+		}
+	}
+
+	if peak, at, ok := h.Peak(); ok {
+		lines = append(lines, fmt.Sprintf("Peak (15m): %.1f%% at %s", peak, at.Format("15:04:05")))
+	} else {
+		lines = append(lines, "Peak (15m): --")
+	}
+
+	if h.ConnectedAt().IsZero() {
+		lines = append(lines, "Connected: --")
+	} else {
+		lines = append(lines, "Connected: "+sysinfo.FormatUptime(now.Sub(h.ConnectedAt())))
+	}
+
+	if mem := h.Memory(); mem == nil {
+		lines = append(lines, "Memory used: --")
+	} else {
+		lines = append(lines, fmt.Sprintf("Memory used: %s / %s (%.0f%%)",
+			sysinfo.FormatMemory(mem.Used), sysinfo.FormatMemory(mem.Total), mem.UsedPercent()))
+	}
+	return lines
+}
+
+// HistoryMenu is the History submenu's read-only items
+type HistoryMenu struct {
+	items []*systray.MenuItem
+}
+
+func newHistoryMenu(root *systray.MenuItem) *HistoryMenu {
+	m := &HistoryMenu{}
+	for _, line := range HistoryLines(&sink.History{}, time.Now()) {
+		item := root.AddSubMenuItem(line, "")
+		item.Disable()
+		m.items = append(m.items, item)
+	}
+	return m
+}
+
+// UpdateHistory refreshes the History submenu from h
+func UpdateHistory(menu *HistoryMenu, h *sink.History, now time.Time) {
+	for i, line := range HistoryLines(h, now) {
+		menu.items[i].SetTitle(line)
+	}
+}
@@ -0,0 +1,37 @@
+package tray
+
+import (
+	"testing"
+	"time"
+
+	"system-shinobi/sensei/internal/metrics"
+	"system-shinobi/sensei/internal/sink"
+)
+
+func TestHistoryLines(t *testing.T) {
+	var h sink.History
+	start := time.Date(2024, 2, 13, 14, 2, 11, 0, time.Local)
+	const gb = 1024 * 1024 * 1024
+	h.Add(start, 97.3, &metrics.Memory{Total: 16 * gb, Used: 8 * gb})
+	h.Add(start.Add(90*time.Second), 12.7, nil)
+
+	expected := []string{
+		"CPU avg 1m: 12.7%",
+		"CPU avg 5m: 55.0%",
+		"CPU avg 15m: 55.0%",
+		"Peak (15m): 97.3% at 14:02:11",
+		"Connected: 1m",
+		"Memory used: 8.0 GB / 16.0 GB (50%)",
+	}
+	lines := HistoryLines(&h, start.Add(90*time.Second))
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Line %d = %q, expected %q", i, lines[i], expected[i])
+		}
+	}
+
+	h.Reset()
+	if got := HistoryLines(&h, start)[0]; got != "CPU avg 1m: --" {
+		t.Errorf("Expected no data after Reset, got %q", got)
+	}
+}
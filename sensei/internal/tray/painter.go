@@ -0,0 +1,141 @@
+package tray
+
+import (
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"fyne.io/systray"
+	"system-shinobi/sensei/internal/icon"
+)
+
+// animationInterval is how long each frame of an animated icon is shown
+const animationInterval = 200 * time.Millisecond
+
+// Painter renders the tray icon on demand, so it can carry a value
+// overlay and animate the High state. It is safe for concurrent use.
+type Painter struct {
+	opts    icon.Options
+	animate bool
+	tint    bool
+
+	mu        sync.Mutex
+	shown     bool
+	class     icon.Class
+	value     int // overlay value the current frames were rendered with
+	frames    [][]byte
+	templates [][]byte
+	frame     int
+	stop      chan struct{}
+}
+
+// NewPainter validates the icon options and returns a Painter for them.
+// With tint the icon takes each class's color, e.g. for custom levels or a
+// gradient; otherwise the theme's state colors are used.
+func NewPainter(opts icon.Options, animate, tint bool) (*Painter, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return &Painter{opts: opts, animate: animate, tint: tint}, nil
+}
+
+// Show displays the icon for a classified reading, re-rendering only when
+// the class (including its gradient color) or the overlay's rounded value changes
+func (p *Painter) Show(class icon.Class, percent float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	value := int(math.Round(percent))
+	if p.shown && class == p.class && (p.opts.Overlay == icon.OverlayNone || value == p.value) {
+		return
+	}
+
+	opts := p.opts
+	opts.Percent = percent
+	if p.tint {
+		opts.Tint = class.Color
+	}
+	frames, templates, err := render(class.State, opts, p.animate && icon.Animated(class.State))
+	if err != nil {
+		slog.Error("Failed to render icon", "state", class.State, "err", err)
+		return
+	}
+	p.shown, p.class, p.value = true, class, value
+	p.frames, p.templates = frames, templates
+	p.frame %= len(frames)
+
+	if len(frames) > 1 {
+		if p.stop == nil {
+			p.stop = make(chan struct{})
+			go p.run(p.stop)
+		}
+		return
+	}
+	p.stopAnimation()
+	systray.SetTemplateIcon(templates[0], frames[0])
+}
+
+// Stop ends any running animation
+func (p *Painter) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stopAnimation()
+}
+
+func (p *Painter) stopAnimation() {
+	if p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+}
+
+// run cycles through the current frames until stop is closed
+func (p *Painter) run(stop chan struct{}) {
+	ticker := time.NewTicker(animationInterval)
+	defer ticker.Stop()
+	for {
+		p.mu.Lock()
+		select {
+		case <-stop:
+			p.mu.Unlock()
+			return
+		default:
+		}
+		systray.SetTemplateIcon(p.templates[p.frame], p.frames[p.frame])
+		p.frame = (p.frame + 1) % len(p.frames)
+		p.mu.Unlock()
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// render produces the colored and template frames for a state; a single
+// frame unless animated
+func render(state icon.IconState, opts icon.Options, animated bool) (frames, templates [][]byte, err error) {
+	if !animated {
+		data, err := icon.Render(state, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts.Template = true
+		tmpl, err := icon.Render(state, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return [][]byte{data}, [][]byte{tmpl}, nil
+	}
+
+	if frames, err = icon.Frames(state, opts); err != nil {
+		return nil, nil, err
+	}
+	opts.Template = true
+	if templates, err = icon.Frames(state, opts); err != nil {
+		return nil, nil, err
+	}
+	return frames, templates, nil
+}
@@ -0,0 +1,69 @@
+package tray
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/systray"
+	"system-shinobi/sensei/internal/supervisor"
+	"system-shinobi/sensei/internal/sysinfo"
+)
+
+// ProbeMenu is the Probe submenu, shown only when sensei manages the probe
+type ProbeMenu struct {
+	root  *systray.MenuItem
+	items []*systray.MenuItem
+}
+
+func newProbeMenu(root *systray.MenuItem) *ProbeMenu {
+	m := &ProbeMenu{root: root}
+	for _, line := range ProbeLines(supervisor.Health{}, time.Now()) {
+		item := root.AddSubMenuItem(line, "")
+		item.Disable()
+		m.items = append(m.items, item)
+	}
+	root.Hide()
+	return m
+}
+
+// UpdateProbe shows the Probe submenu with the supervised probe's health
+func UpdateProbe(menu *ProbeMenu, h supervisor.Health, now time.Time) {
+	menu.root.SetTitle(FormatProbeTitle(h))
+	for i, line := range ProbeLines(h, now) {
+		menu.items[i].SetTitle(line)
+	}
+	menu.root.SetTooltip(h.LastError)
+	menu.root.Show()
+}
+
+// FormatProbeTitle formats the Probe submenu's title
+func FormatProbeTitle(h supervisor.Health) string {
+	if h.Running {
+		return "Probe: running"
+	}
+	return "Probe: restarting"
+}
+
+// ProbeLines formats the Probe submenu's items: uptime, restart count and
+// the last error
+func ProbeLines(h supervisor.Health, now time.Time) []string {
+	uptime := "Uptime: --"
+	if h.Running {
+		uptime = fmt.Sprintf("Uptime: %s (PID %d)", sysinfo.FormatUptime(now.Sub(h.Started)), h.PID)
+	}
+
+	lastErr := "Last error: none"
+	if h.LastError != "" {
+		msg := []rune(h.LastError)
+		if len(msg) > 48 {
+			msg = append(msg[:47], '…')
+		}
+		lastErr = "Last error: " + string(msg)
+	}
+
+	return []string{
+		uptime,
+		fmt.Sprintf("Restarts: %d", h.Restarts),
+		lastErr,
+	}
+}
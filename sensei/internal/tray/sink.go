@@ -0,0 +1,41 @@
+package tray
+
+import (
+	"time"
+
+	"system-shinobi/sensei/internal/icon"
+	"system-shinobi/sensei/internal/sink"
+)
+
+// Sink shows the pipeline's readings on the tray icon and menu
+type Sink struct {
+	menu    *Menu
+	painter *Painter
+}
+
+// NewSink returns a sink that updates menu and paints the icon with painter
+func NewSink(menu *Menu, painter *Painter) *Sink {
+	return &Sink{menu: menu, painter: painter}
+}
+
+// Update shows a new reading
+func (t *Sink) Update(s sink.Sample) {
+	t.painter.Show(s.Class, s.Reading.CpuPercent)
+	UpdateLabel(t.menu.CPULabel, s.Reading.CpuPercent, s.Class)
+	UpdateHistory(t.menu.History, s.History, s.Time)
+	if s.Reading.Memory != nil {
+		UpdateMemory(t.menu.MemLabel, s.Reading.Memory)
+	}
+	if s.Reading.Load != nil {
+		UpdateLoad(t.menu.LoadLabel, s.Reading.Load)
+	}
+}
+
+// Disconnected clears the menu and shows the idle icon
+func (t *Sink) Disconnected(now time.Time, idle icon.Class) {
+	UpdateHistory(t.menu.History, &sink.History{}, now)
+	UpdateLabel(t.menu.CPULabel, -1, idle)
+	UpdateMemory(t.menu.MemLabel, nil)
+	UpdateLoad(t.menu.LoadLabel, nil)
+	t.painter.Show(idle, 0)
+}
@@ -0,0 +1,92 @@
+package tray
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"syscall"
+	"time"
+
+	"system-shinobi/sensei/internal/audit"
+	"system-shinobi/sensei/internal/logging"
+	"system-shinobi/sensei/internal/process"
+	"system-shinobi/sensei/internal/protect"
+)
+
+// confirmWindow is how long a first Terminate click stays armed
+const confirmWindow = 10 * time.Second
+
+// Terminator sends SIGTERM from the tray menu. A menu can't prompt, so the
+// first Terminate click only arms the process and asks for confirmation
+// through a notification; a second click within confirmWindow sends the
+// signal. It is safe for concurrent use.
+type Terminator struct {
+	policy   *protect.Policy
+	auditLog *audit.Log
+	notify   func(title, message string) error
+	kill     func(pid int) error
+
+	mu      sync.Mutex
+	armed   process.Process
+	armedAt time.Time
+}
+
+// NewTerminator creates a Terminator that checks policy, records to
+// auditLog and confirms through notify
+func NewTerminator(policy *protect.Policy, auditLog *audit.Log, notify func(title, message string) error) *Terminator {
+	return &Terminator{
+		policy:   policy,
+		auditLog: auditLog,
+		notify:   notify,
+		kill:     process.Kill,
+	}
+}
+
+// Request handles a Terminate click for p and reports whether SIGTERM was sent
+func (t *Terminator) Request(p process.Process, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Only the dojo can take the typed confirmation protected processes need
+	if d := t.policy.Check(p); d.Verdict != protect.Allow {
+		t.send("Not terminated", fmt.Sprintf("%s (PID %d) is protected: %s. Use the dojo instead.", p.Name, p.PID, d.Reason))
+		return false
+	}
+
+	if t.armed.PID != p.PID || t.armed.Name != p.Name || now.Sub(t.armedAt) > confirmWindow {
+		t.armed, t.armedAt = p, now
+		t.send("Confirm terminate", fmt.Sprintf("Click Terminate on %s (PID %d) again within %s to send SIGTERM.",
+			p.Name, p.PID, confirmWindow))
+		return false
+	}
+
+	t.armed = process.Process{}
+	cmdline, _ := process.Command(p.PID)
+	err := t.kill(p.PID)
+	entry := audit.Entry{
+		Time:      now,
+		PID:       p.PID,
+		Name:      p.Name,
+		Command:   cmdline,
+		Signal:    process.SignalName(syscall.SIGTERM),
+		Outcome:   audit.Outcome(err),
+		Initiator: audit.InitiatorTray,
+	}
+	if err := t.auditLog.Record(entry); err != nil {
+		slog.ErrorContext(logging.Event(context.Background()), "Failed to write audit entry", "err", err)
+	}
+
+	if err != nil {
+		t.send("Terminate failed", fmt.Sprintf("%s (PID %d): %v", p.Name, p.PID, err))
+		return false
+	}
+	t.send("Terminated", fmt.Sprintf("Sent SIGTERM to %s (PID %d).", p.Name, p.PID))
+	return true
+}
+
+func (t *Terminator) send(title, message string) {
+	if err := t.notify(title, message); err != nil {
+		slog.Warn("Failed to notify", "title", title, "err", err)
+	}
+}
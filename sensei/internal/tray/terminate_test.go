@@ -0,0 +1,108 @@
+package tray
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"system-shinobi/sensei/internal/audit"
+	"system-shinobi/sensei/internal/config"
+	"system-shinobi/sensei/internal/process"
+	"system-shinobi/sensei/internal/protect"
+)
+
+type fakeDesktop struct {
+	titles []string
+	killed []int
+}
+
+func newTestTerminator(t *testing.T, cfg config.Protect) (*Terminator, *fakeDesktop, string) {
+	t.Helper()
+	policy, err := protect.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := audit.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { auditLog.Close() })
+
+	d := &fakeDesktop{}
+	term := NewTerminator(policy, auditLog, func(title, message string) error {
+		d.titles = append(d.titles, title)
+		return nil
+	})
+	term.kill = func(pid int) error {
+		d.killed = append(d.killed, pid)
+		return nil
+	}
+	return term, d, path
+}
+
+func TestTerminateNeedsTwoClicks(t *testing.T) {
+	term, d, path := newTestTerminator(t, config.Protect{})
+	p := process.Process{PID: 4242, Name: "node"}
+	now := time.Unix(1707860342, 0)
+
+	if term.Request(p, now) {
+		t.Fatal("First click must only arm the process")
+	}
+	if len(d.killed) != 0 || d.titles[0] != "Confirm terminate" {
+		t.Fatalf("Expected a confirmation notification, got %v (killed %v)", d.titles, d.killed)
+	}
+	if !term.Request(p, now.Add(3*time.Second)) {
+		t.Fatal("Second click within the window should terminate")
+	}
+	if len(d.killed) != 1 || d.killed[0] != 4242 {
+		t.Errorf("Expected PID 4242 to be killed, got %v", d.killed)
+	}
+
+	entries, err := audit.ReadTail(path, 10)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expected one audit entry, got %v (%v)", entries, err)
+	}
+	if e := entries[0]; e.Initiator != audit.InitiatorTray || e.Signal != "SIGTERM" || e.Outcome != audit.OutcomeOK {
+		t.Errorf("Unexpected audit entry: %+v", e)
+	}
+}
+
+func TestTerminateConfirmationExpires(t *testing.T) {
+	term, d, _ := newTestTerminator(t, config.Protect{})
+	p := process.Process{PID: 4242, Name: "node"}
+	now := time.Unix(1707860342, 0)
+
+	term.Request(p, now)
+	if term.Request(p, now.Add(confirmWindow+time.Second)) {
+		t.Error("A click after the window should re-arm, not terminate")
+	}
+	if term.Request(process.Process{PID: 4242, Name: "reused"}, now.Add(confirmWindow+2*time.Second)) {
+		t.Error("A different process on the same PID must not be terminated")
+	}
+	if len(d.killed) != 0 {
+		t.Errorf("Expected no kills, got %v", d.killed)
+	}
+}
+
+func TestTerminateRespectsProtectPolicy(t *testing.T) {
+	term, d, _ := newTestTerminator(t, config.Protect{Names: []string{"postgres"}})
+	p := process.Process{PID: 500, Name: "postgres"}
+	now := time.Unix(1707860342, 0)
+
+	term.Request(p, now)
+	if term.Request(p, now.Add(time.Second)) || len(d.killed) != 0 {
+		t.Fatal("Protected processes must not be terminated from the tray")
+	}
+	if !strings.HasPrefix(d.titles[0], "Not terminated") {
+		t.Errorf("Expected a refusal notification, got %v", d.titles)
+	}
+}
+
+func TestFormatTopItem(t *testing.T) {
+	got := FormatTopItem(process.Process{PID: 812, Name: "Google Chrome", CPU: 43.25})
+	if got != " 43.2%  Google Chrome (812)" {
+		t.Errorf("FormatTopItem = %q", got)
+	}
+}
@@ -0,0 +1,92 @@
+package tray
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"fyne.io/systray"
+	"system-shinobi/sensei/internal/process"
+	"system-shinobi/sensei/internal/selfstat"
+)
+
+// topCount is how many processes the Top processes submenu lists
+const topCount = 5
+
+// TopMenu is the live "Top processes" submenu: one item per process, each
+// with a Terminate action
+type TopMenu struct {
+	items      [topCount]*systray.MenuItem
+	terminate  [topCount]*systray.MenuItem
+	terminator *Terminator
+
+	mu    sync.Mutex
+	procs []process.Process
+}
+
+// newTopMenu adds the process slots under root and starts listening for
+// Terminate clicks
+func newTopMenu(root *systray.MenuItem, terminator *Terminator) *TopMenu {
+	m := &TopMenu{terminator: terminator}
+	for i := range m.items {
+		m.items[i] = root.AddSubMenuItem("--", "")
+		m.terminate[i] = m.items[i].AddSubMenuItem("Terminate", "Send SIGTERM after confirming")
+		m.items[i].Hide()
+		go m.listen(i)
+	}
+	return m
+}
+
+// Run refreshes the list every interval, forever
+func (m *TopMenu) Run(interval time.Duration) {
+	for {
+		m.Refresh()
+		time.Sleep(interval)
+	}
+}
+
+// Refresh lists the current top processes by CPU
+func (m *TopMenu) Refresh() {
+	done := selfstat.Time("top")
+	procs, err := process.ListTop(topCount)
+	done()
+	if err != nil {
+		slog.Warn("Failed to list top processes", "err", err)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.procs = procs
+	for i, item := range m.items {
+		if i < len(procs) {
+			item.SetTitle(FormatTopItem(procs[i]))
+			item.SetTooltip(fmt.Sprintf("PID %d, %s, %.1f%% memory", procs[i].PID, procs[i].User, procs[i].Memory))
+			item.Show()
+		} else {
+			item.Hide()
+		}
+	}
+}
+
+func (m *TopMenu) listen(i int) {
+	for range m.terminate[i].ClickedCh {
+		m.mu.Lock()
+		var p process.Process
+		ok := i < len(m.procs)
+		if ok {
+			p = m.procs[i]
+		}
+		m.mu.Unlock()
+
+		if ok && m.terminator.Request(p, time.Now()) {
+			m.Refresh()
+		}
+	}
+}
+
+// FormatTopItem formats a process for the Top processes submenu
+func FormatTopItem(p process.Process) string {
+	return fmt.Sprintf("%5.1f%%  %s (%d)", p.CPU, p.Name, p.PID)
+}
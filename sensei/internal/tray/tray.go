@@ -5,59 +5,144 @@ import (
 
 	"fyne.io/systray"
 	"system-shinobi/sensei/internal/icon"
+	"system-shinobi/sensei/internal/metrics"
+	"system-shinobi/sensei/internal/sysinfo"
 )
 
-var currentIcons map[icon.IconState][]byte
-var templateIcons map[icon.IconState][]byte
+// Menu holds the tray menu items sensei updates or listens to
+type Menu struct {
+	CPULabel  *systray.MenuItem
+	MemLabel  *systray.MenuItem
+	LoadLabel *systray.MenuItem
+	Top       *TopMenu
+	History   *HistoryMenu
+	Probe     *ProbeMenu
+	Dojo      *systray.MenuItem
+	Quit      *systray.MenuItem
+}
 
 // Setup initializes the system tray with menu items and returns references to them
-func Setup(icons map[icon.IconState][]byte, templates map[icon.IconState][]byte) (*systray.MenuItem, *systray.MenuItem, *systray.MenuItem) {
-	currentIcons = icons
-	templateIcons = templates
-
-	// Set initial icon to idle state
-	UpdateIcon(icon.StateIdle)
+func Setup(painter *Painter, classifier *icon.Classifier, terminator *Terminator) *Menu {
+	// Set initial icon to the lowest level
+	idle := classifier.Classify(0)
+	painter.Show(idle, 0)
 
 	// Set tooltip
 	systray.SetTooltip("System Shinobi - CPU Monitor")
 
 	// Create menu items
-	cpuLabel := systray.AddMenuItem("🥷 CPU: --% [Idle]", "Current CPU usage and ninja state")
+	cpuLabel := systray.AddMenuItem(fmt.Sprintf("🥷 CPU: --%% [%s]", idle.Name), "Current CPU usage and ninja state")
 	cpuLabel.Disable() // Make it read-only
 
+	memLabel := systray.AddMenuItem(FormatMemLabel(nil), "Memory and swap usage")
+	memLabel.Disable()
+
+	loadLabel := systray.AddMenuItem(FormatLoadLabel(nil), "Load averages and pressure stall")
+	loadLabel.Disable()
+
+	historyItem := systray.AddMenuItem("History", "Recent averages, peak and uptime")
+	history := newHistoryMenu(historyItem)
+
+	topItem := systray.AddMenuItem("Top processes", "Busiest processes by CPU")
+	top := newTopMenu(topItem, terminator)
+
+	probeItem := systray.AddMenuItem("Probe", "Health of the probe sensei manages")
+	probe := newProbeMenu(probeItem)
+
 	systray.AddSeparator()
 
-	dojoItem := systray.AddMenuItem("Open Dojo (Terminal UI)", "Launch the Dojo process manager")
+	dojoItem := systray.AddMenuItem(dojoTitle, dojoTooltip)
 
 	systray.AddSeparator()
 
 	quit := systray.AddMenuItem("Quit Shinobi", "Exit System Shinobi")
 
-	return cpuLabel, dojoItem, quit
+	return &Menu{
+		CPULabel:  cpuLabel,
+		MemLabel:  memLabel,
+		LoadLabel: loadLabel,
+		Top:       top,
+		History:   history,
+		Probe:     probe,
+		Dojo:      dojoItem,
+		Quit:      quit,
+	}
+}
+
+// Open Dojo item text when the last launch didn't fail
+const (
+	dojoTitle   = "Open Dojo (Terminal UI)"
+	dojoTooltip = "Launch the Dojo process manager"
+)
+
+// UpdateDojo shows the outcome of the last Open Dojo click. A failure
+// stays on the item, with the full reason in its tooltip, until a launch
+// succeeds.
+func UpdateDojo(dojoItem *systray.MenuItem, err error) {
+	if err == nil {
+		dojoItem.SetTitle(dojoTitle)
+		dojoItem.SetTooltip(dojoTooltip)
+		return
+	}
+	dojoItem.SetTitle(FormatDojoError(err))
+	dojoItem.SetTooltip(err.Error())
 }
 
-// UpdateIcon swaps the systray icon based on the current state
-func UpdateIcon(state icon.IconState) {
-	if templateIcons != nil && currentIcons != nil {
-		systray.SetTemplateIcon(templateIcons[state], currentIcons[state])
-	} else if currentIcons != nil {
-		systray.SetIcon(currentIcons[state])
+// FormatDojoError formats a launch failure for the Open Dojo item
+func FormatDojoError(err error) string {
+	msg := []rune(err.Error())
+	if len(msg) > 48 {
+		msg = append(msg[:47], '…')
 	}
+	return fmt.Sprintf("Open Dojo (failed: %s)", string(msg))
 }
 
-// UpdateLabel updates the CPU percentage and state display in the menu
-func UpdateLabel(cpuLabel *systray.MenuItem, percent float64, state icon.IconState) {
-	cpuLabel.SetTitle(FormatCpuLabel(percent, state))
+// UpdateLabel updates the CPU percentage and level display in the menu
+func UpdateLabel(cpuLabel *systray.MenuItem, percent float64, class icon.Class) {
+	cpuLabel.SetTitle(FormatCpuLabel(percent, class.Name))
 }
 
-// FormatCpuLabel formats the CPU percentage and state for display
-func FormatCpuLabel(percent float64, state icon.IconState) string {
-	stateNames := map[icon.IconState]string{
-		icon.StateIdle:   "Idle",
-		icon.StateLow:    "Low",
-		icon.StateMedium: "Medium",
-		icon.StateHigh:   "High",
+// UpdateMemory updates the memory display in the menu. A nil mem shows
+// that no memory metrics are available.
+func UpdateMemory(memLabel *systray.MenuItem, mem *metrics.Memory) {
+	memLabel.SetTitle(FormatMemLabel(mem))
+}
+
+// FormatMemLabel formats memory, swap and (on Linux) memory pressure for display
+func FormatMemLabel(mem *metrics.Memory) string {
+	if mem == nil {
+		return "Memory: --"
+	}
+	label := fmt.Sprintf("Memory: %s / %s  Swap: %s",
+		sysinfo.FormatMemory(mem.Used), sysinfo.FormatMemory(mem.Total), sysinfo.FormatMemory(mem.SwapUsed))
+	if mem.Pressure != nil {
+		label += fmt.Sprintf("  PSI: %.1f%%", mem.Pressure.SomeAvg10)
+	}
+	return label
+}
+
+// UpdateLoad updates the load average display in the menu
+func UpdateLoad(loadLabel *systray.MenuItem, load *metrics.Load) {
+	loadLabel.SetTitle(FormatLoadLabel(load))
+}
+
+// FormatLoadLabel formats the 1/5/15 minute load averages, plus CPU and
+// I/O pressure when the probe reports them
+func FormatLoadLabel(load *metrics.Load) string {
+	if load == nil {
+		return "Load: --"
+	}
+	label := fmt.Sprintf("Load: %.2f %.2f %.2f", load.Load1, load.Load5, load.Load15)
+	if load.CPUPressure != nil {
+		label += fmt.Sprintf("  PSI cpu: %.1f%%", load.CPUPressure.SomeAvg10)
 	}
-	name := stateNames[state]
-	return fmt.Sprintf("🥷 CPU: %.1f%% [%s]", percent, name)
+	if load.IOPressure != nil {
+		label += fmt.Sprintf(" io: %.1f%%", load.IOPressure.SomeAvg10)
+	}
+	return label
+}
+
+// FormatCpuLabel formats the CPU percentage and level name for display
+func FormatCpuLabel(percent float64, level string) string {
+	return fmt.Sprintf("🥷 CPU: %.1f%% [%s]", percent, level)
 }
@@ -1,28 +1,96 @@
 package tray
 
 import (
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"system-shinobi/sensei/internal/icon"
+	"system-shinobi/sensei/internal/metrics"
+	"system-shinobi/sensei/internal/supervisor"
 )
 
 func TestFormatCpuLabel(t *testing.T) {
 	tests := []struct {
 		percent  float64
-		state    icon.IconState
 		expected string
 	}{
-		{0.0, icon.StateIdle, "🥷 CPU: 0.0% [Idle]"},
-		{45.3, icon.StateMedium, "🥷 CPU: 45.3% [Medium]"},
-		{100.0, icon.StateHigh, "🥷 CPU: 100.0% [High]"},
-		{12.5, icon.StateIdle, "🥷 CPU: 12.5% [Idle]"},
-		{25.0, icon.StateLow, "🥷 CPU: 25.0% [Low]"},
+		{0.0, "🥷 CPU: 0.0% [Idle]"},
+		{45.3, "🥷 CPU: 45.3% [Medium]"},
+		{100.0, "🥷 CPU: 100.0% [High]"},
+		{12.5, "🥷 CPU: 12.5% [Idle]"},
+		{25.0, "🥷 CPU: 25.0% [Low]"},
 	}
 
+	classifier := icon.DefaultClassifier()
 	for _, tt := range tests {
-		result := FormatCpuLabel(tt.percent, tt.state)
+		result := FormatCpuLabel(tt.percent, classifier.Classify(tt.percent).Name)
 		if result != tt.expected {
-			t.Errorf("FormatCpuLabel(%f, %v) = %q, expected %q", tt.percent, tt.state, result, tt.expected)
+			t.Errorf("FormatCpuLabel(%f) = %q, expected %q", tt.percent, result, tt.expected)
 		}
 	}
 }
+
+func TestFormatMemLabel(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+	tests := []struct {
+		mem      *metrics.Memory
+		expected string
+	}{
+		{nil, "Memory: --"},
+		{&metrics.Memory{Total: 16 * gb, Used: 9 * gb, SwapUsed: 512 * 1024 * 1024},
+			"Memory: 9.0 GB / 16.0 GB  Swap: 512 MB"},
+		{&metrics.Memory{Total: 8 * gb, Used: 6 * gb, Pressure: &metrics.Pressure{SomeAvg10: 12.34}},
+			"Memory: 6.0 GB / 8.0 GB  Swap: 0 MB  PSI: 12.3%"},
+	}
+
+	for _, tt := range tests {
+		result := FormatMemLabel(tt.mem)
+		if result != tt.expected {
+			t.Errorf("FormatMemLabel(%+v) = %q, expected %q", tt.mem, result, tt.expected)
+		}
+	}
+}
+
+func TestFormatLoadLabel(t *testing.T) {
+	tests := []struct {
+		load     *metrics.Load
+		expected string
+	}{
+		{nil, "Load: --"},
+		{&metrics.Load{Load1: 1.5, Load5: 0.75, Load15: 0.333}, "Load: 1.50 0.75 0.33"},
+		{&metrics.Load{Load1: 40, Load5: 20, Load15: 10,
+			CPUPressure: &metrics.Pressure{SomeAvg10: 55.55}, IOPressure: &metrics.Pressure{SomeAvg10: 3}},
+			"Load: 40.00 20.00 10.00  PSI cpu: 55.5% io: 3.0%"},
+	}
+
+	for _, tt := range tests {
+		result := FormatLoadLabel(tt.load)
+		if result != tt.expected {
+			t.Errorf("FormatLoadLabel(%+v) = %q, expected %q", tt.load, result, tt.expected)
+		}
+	}
+}
+
+func TestProbeLines(t *testing.T) {
+	now := time.Date(2024, 2, 13, 14, 0, 0, 0, time.UTC)
+
+	running := supervisor.Health{Running: true, PID: 99, Started: now.Add(-90 * time.Minute), Restarts: 2}
+	expected := []string{"Uptime: 1h 30m (PID 99)", "Restarts: 2", "Last error: none"}
+	if got := ProbeLines(running, now); !slices.Equal(got, expected) {
+		t.Errorf("ProbeLines(running) = %q, expected %q", got, expected)
+	}
+
+	down := supervisor.Health{Restarts: 5, LastError: "exit status 1: " + strings.Repeat("x", 60)}
+	got := ProbeLines(down, now)
+	if got[0] != "Uptime: --" {
+		t.Errorf("Uptime line = %q, expected %q", got[0], "Uptime: --")
+	}
+	if !strings.HasPrefix(got[2], "Last error: exit status 1: ") || !strings.HasSuffix(got[2], "…") {
+		t.Errorf("Expected a truncated last error, got %q", got[2])
+	}
+	if title := FormatProbeTitle(down); title != "Probe: restarting" {
+		t.Errorf("FormatProbeTitle = %q, expected %q", title, "Probe: restarting")
+	}
+}